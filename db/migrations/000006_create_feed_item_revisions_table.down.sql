@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_feed_item_revisions_feed_item_id;
+DROP TABLE IF EXISTS feed_item_revisions;
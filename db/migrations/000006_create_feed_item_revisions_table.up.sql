@@ -0,0 +1,10 @@
+CREATE TABLE feed_item_revisions (
+    id INTEGER PRIMARY KEY,
+    feed_item_id INTEGER NOT NULL REFERENCES feed_items(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT,
+    date TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_feed_item_revisions_feed_item_id ON feed_item_revisions(feed_item_id);
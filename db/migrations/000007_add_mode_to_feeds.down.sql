@@ -0,0 +1,2 @@
+ALTER TABLE feeds DROP COLUMN last_snapshot;
+ALTER TABLE feeds DROP COLUMN mode;
@@ -0,0 +1,2 @@
+ALTER TABLE feeds ADD COLUMN mode TEXT NOT NULL DEFAULT 'list';
+ALTER TABLE feeds ADD COLUMN last_snapshot TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_feed_filter_rules_feed_id;
+DROP TABLE IF EXISTS feed_filter_rules;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_feed_items_feed_id_created_at;
+
+ALTER TABLE feeds DROP COLUMN retention_max_age_days;
+ALTER TABLE feeds DROP COLUMN retention_max_items;
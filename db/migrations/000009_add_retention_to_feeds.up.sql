@@ -0,0 +1,4 @@
+ALTER TABLE feeds ADD COLUMN retention_max_items INTEGER;
+ALTER TABLE feeds ADD COLUMN retention_max_age_days INTEGER;
+
+CREATE INDEX idx_feed_items_feed_id_created_at ON feed_items(feed_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE feeds DROP COLUMN description_format;
@@ -0,0 +1 @@
+ALTER TABLE feeds ADD COLUMN description_format TEXT NOT NULL DEFAULT 'html';
@@ -0,0 +1 @@
+ALTER TABLE feeds ADD COLUMN remove_selectors TEXT;
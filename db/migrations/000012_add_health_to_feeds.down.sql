@@ -0,0 +1,3 @@
+ALTER TABLE feeds DROP COLUMN broken_since;
+ALTER TABLE feeds DROP COLUMN broken_reason;
+ALTER TABLE feeds DROP COLUMN last_item_count;
@@ -0,0 +1,3 @@
+ALTER TABLE feeds ADD COLUMN last_item_count INTEGER;
+ALTER TABLE feeds ADD COLUMN broken_reason TEXT;
+ALTER TABLE feeds ADD COLUMN broken_since DATETIME;
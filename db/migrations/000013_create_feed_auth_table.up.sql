@@ -0,0 +1,12 @@
+CREATE TABLE feed_auth (
+    feed_id INTEGER PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    password TEXT NOT NULL DEFAULT '',
+    token TEXT NOT NULL DEFAULT '',
+    login_url TEXT NOT NULL DEFAULT '',
+    login_fields TEXT NOT NULL DEFAULT '',
+    login_check TEXT NOT NULL DEFAULT '',
+    cookies TEXT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
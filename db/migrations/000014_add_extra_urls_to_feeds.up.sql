@@ -0,0 +1 @@
+ALTER TABLE feeds ADD COLUMN extra_urls TEXT;
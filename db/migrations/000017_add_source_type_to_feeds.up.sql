@@ -0,0 +1 @@
+ALTER TABLE feeds ADD COLUMN source_type TEXT NOT NULL DEFAULT 'css';
@@ -0,0 +1,2 @@
+ALTER TABLE feeds DROP COLUMN map_expression;
+ALTER TABLE feeds DROP COLUMN filter_expression;
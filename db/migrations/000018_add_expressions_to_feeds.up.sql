@@ -0,0 +1,2 @@
+ALTER TABLE feeds ADD COLUMN filter_expression TEXT;
+ALTER TABLE feeds ADD COLUMN map_expression TEXT;
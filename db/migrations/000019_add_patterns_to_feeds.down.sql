@@ -0,0 +1,6 @@
+ALTER TABLE feeds DROP COLUMN date_template;
+ALTER TABLE feeds DROP COLUMN description_template;
+ALTER TABLE feeds DROP COLUMN link_template;
+ALTER TABLE feeds DROP COLUMN title_template;
+ALTER TABLE feeds DROP COLUMN item_pattern;
+ALTER TABLE feeds DROP COLUMN global_pattern;
@@ -0,0 +1,6 @@
+ALTER TABLE feeds ADD COLUMN global_pattern TEXT;
+ALTER TABLE feeds ADD COLUMN item_pattern TEXT;
+ALTER TABLE feeds ADD COLUMN title_template TEXT;
+ALTER TABLE feeds ADD COLUMN link_template TEXT;
+ALTER TABLE feeds ADD COLUMN description_template TEXT;
+ALTER TABLE feeds ADD COLUMN date_template TEXT;
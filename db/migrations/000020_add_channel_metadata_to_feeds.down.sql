@@ -0,0 +1,2 @@
+ALTER TABLE feeds DROP COLUMN description;
+ALTER TABLE feeds DROP COLUMN language;
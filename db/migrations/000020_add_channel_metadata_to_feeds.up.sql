@@ -0,0 +1,2 @@
+ALTER TABLE feeds ADD COLUMN language TEXT;
+ALTER TABLE feeds ADD COLUMN description TEXT;
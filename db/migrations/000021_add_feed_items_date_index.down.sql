@@ -0,0 +1 @@
+DROP INDEX idx_feed_items_feed_id_date;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS feed_view_filter_rules;
+DROP TABLE IF EXISTS feed_views;
@@ -0,0 +1,23 @@
+CREATE TABLE feed_views (
+    id INTEGER PRIMARY KEY,
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    title TEXT,
+    max_items INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_feed_views_feed_id ON feed_views(feed_id);
+
+CREATE TABLE feed_view_filter_rules (
+    id INTEGER PRIMARY KEY,
+    view_id INTEGER NOT NULL REFERENCES feed_views(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    operator TEXT NOT NULL,
+    value TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_feed_view_filter_rules_view_id ON feed_view_filter_rules(view_id);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS skip_pruned_feed_items;
+DROP TABLE IF EXISTS feed_item_tombstones;
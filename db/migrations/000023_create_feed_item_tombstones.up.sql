@@ -0,0 +1,18 @@
+-- Links of the items removed by retention, so that items still listed on
+-- the source page are not stored again as new ones
+CREATE TABLE feed_item_tombstones (
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    link TEXT NOT NULL,
+    pruned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (feed_id, link)
+);
+
+CREATE TRIGGER skip_pruned_feed_items
+BEFORE INSERT ON feed_items
+WHEN EXISTS (
+    SELECT 1 FROM feed_item_tombstones t
+    WHERE t.feed_id = NEW.feed_id AND t.link = NEW.link
+)
+BEGIN
+    SELECT RAISE(IGNORE);
+END;
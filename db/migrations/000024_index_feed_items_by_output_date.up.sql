@@ -0,0 +1,2 @@
+DROP INDEX idx_feed_items_feed_id_date;
+CREATE INDEX idx_feed_items_feed_id_output_date ON feed_items(feed_id, COALESCE(date, created_at));
@@ -0,0 +1,48 @@
+-- name: GetCollection :one
+SELECT * FROM collections
+WHERE id = ? LIMIT 1;
+
+-- name: ListCollectionsWithFeedsCount :many
+SELECT c.*, COUNT(cf.feed_id) AS feeds_count
+FROM collections c
+LEFT JOIN collection_feeds cf ON c.id = cf.collection_id
+GROUP BY c.id
+ORDER BY c.name, c.id;
+
+-- name: CreateCollection :one
+INSERT INTO collections (name)
+VALUES (?)
+RETURNING *;
+
+-- name: UpdateCollection :exec
+UPDATE collections
+SET name = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteCollection :exec
+DELETE FROM collections
+WHERE id = ?;
+
+-- name: ListCollectionFeeds :many
+SELECT f.* FROM feeds f
+JOIN collection_feeds cf ON f.id = cf.feed_id
+WHERE cf.collection_id = ?
+ORDER BY f.name, f.id;
+
+-- name: AddCollectionFeed :exec
+INSERT INTO collection_feeds (collection_id, feed_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteCollectionFeeds :exec
+DELETE FROM collection_feeds
+WHERE collection_id = ?;
+
+-- name: ListCollectionItems :many
+SELECT sqlc.embed(i), f.name AS feed_name
+FROM feed_items i
+JOIN feeds f ON f.id = i.feed_id
+JOIN collection_feeds cf ON cf.feed_id = i.feed_id
+WHERE cf.collection_id = sqlc.arg(collection_id)
+ORDER BY COALESCE(i.date, i.created_at) DESC, i.id DESC
+LIMIT sqlc.arg(max_items);
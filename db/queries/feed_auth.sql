@@ -0,0 +1,27 @@
+-- name: GetFeedAuth :one
+SELECT * FROM feed_auth
+WHERE feed_id = ? LIMIT 1;
+
+-- name: UpsertFeedAuth :exec
+-- Changing the credentials drops the session, forcing a new login
+INSERT INTO feed_auth (feed_id, type, username, password, token, login_url, login_fields, login_check, cookies, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULL, CURRENT_TIMESTAMP)
+ON CONFLICT(feed_id) DO UPDATE SET
+    type = excluded.type,
+    username = excluded.username,
+    password = excluded.password,
+    token = excluded.token,
+    login_url = excluded.login_url,
+    login_fields = excluded.login_fields,
+    login_check = excluded.login_check,
+    cookies = NULL,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: UpdateFeedAuthCookies :exec
+UPDATE feed_auth
+SET cookies = ?, updated_at = CURRENT_TIMESTAMP
+WHERE feed_id = ?;
+
+-- name: DeleteFeedAuth :exec
+DELETE FROM feed_auth
+WHERE feed_id = ?;
@@ -0,0 +1,12 @@
+-- name: ListFeedFilterRules :many
+SELECT * FROM feed_filter_rules
+WHERE feed_id = ?
+ORDER BY position, id;
+
+-- name: CreateFeedFilterRule :exec
+INSERT INTO feed_filter_rules (feed_id, field, operator, value, position)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: DeleteFeedFilterRules :exec
+DELETE FROM feed_filter_rules
+WHERE feed_id = ?;
@@ -0,0 +1,9 @@
+-- name: CreateFeedItemRevision :one
+INSERT INTO feed_item_revisions (feed_item_id, title, description, date)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListFeedItemRevisions :many
+SELECT * FROM feed_item_revisions
+WHERE feed_item_id = ?
+ORDER BY id;
@@ -13,6 +13,76 @@ VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 ON CONFLICT(feed_id, link) DO NOTHING
 RETURNING id;
 
+-- name: TombstoneFeedItemsByAge :exec
+INSERT OR IGNORE INTO feed_item_tombstones (feed_id, link)
+SELECT i.feed_id, i.link FROM feed_items i
+WHERE i.feed_id = sqlc.arg(feed_id) AND i.created_at < sqlc.arg(created_at);
+
+-- name: PruneFeedItemsByAge :execrows
+DELETE FROM feed_items
+WHERE feed_id = ? AND created_at < ?;
+
+-- name: TombstoneFeedItemsByCount :exec
+INSERT OR IGNORE INTO feed_item_tombstones (feed_id, link)
+SELECT feed_items.feed_id, feed_items.link FROM feed_items
+WHERE feed_items.feed_id = sqlc.arg(feed_id)
+  AND feed_items.id NOT IN (
+    SELECT kept.id FROM feed_items AS kept
+    WHERE kept.feed_id = sqlc.arg(feed_id)
+    ORDER BY COALESCE(kept.date, kept.created_at) DESC, kept.id DESC
+    LIMIT sqlc.arg(max_items)
+  );
+
+-- name: PruneFeedItemsByCount :execrows
+DELETE FROM feed_items
+WHERE feed_items.feed_id = sqlc.arg(feed_id)
+  AND feed_items.id NOT IN (
+    SELECT kept.id FROM feed_items AS kept
+    WHERE kept.feed_id = sqlc.arg(feed_id)
+    ORDER BY COALESCE(kept.date, kept.created_at) DESC, kept.id DESC
+    LIMIT sqlc.arg(max_items)
+  );
+
 -- name: DeleteItemsByFeedID :exec
 DELETE FROM feed_items
 WHERE feed_id = ?;
+
+-- name: DeleteFeedItemTombstones :exec
+DELETE FROM feed_item_tombstones
+WHERE feed_id = ?;
+
+-- name: TrimFeedItemTombstones :exec
+DELETE FROM feed_item_tombstones
+WHERE feed_item_tombstones.feed_id = sqlc.arg(feed_id)
+  AND feed_item_tombstones.link NOT IN (
+    SELECT kept.link FROM feed_item_tombstones AS kept
+    WHERE kept.feed_id = sqlc.arg(feed_id)
+    ORDER BY kept.pruned_at DESC, kept.rowid DESC
+    LIMIT sqlc.arg(max_tombstones)
+  );
+
+-- name: GetFeedItemByLink :one
+SELECT * FROM feed_items
+WHERE feed_id = ? AND link = ? LIMIT 1;
+
+-- name: UpdateFeedItemContent :exec
+UPDATE feed_items
+SET title = ?, description = ?, date = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: GetFeedItemsVersion :one
+SELECT COUNT(*) AS items_count,
+    CAST(COALESCE(strftime('%s', MAX(updated_at)), 0) AS INTEGER) AS last_updated_unix
+FROM feed_items
+WHERE feed_id = ?;
+
+-- name: ListFeedItemsPage :many
+SELECT * FROM feed_items
+WHERE feed_id = sqlc.arg(feed_id)
+  AND (CAST(sqlc.arg(since) AS TEXT) = ''
+       OR datetime(COALESCE(date, created_at)) >= datetime(sqlc.arg(since)))
+  AND (CAST(sqlc.arg(query) AS TEXT) = ''
+       OR instr(lower(title), lower(sqlc.arg(query))) > 0
+       OR instr(lower(description), lower(sqlc.arg(query))) > 0)
+ORDER BY COALESCE(date, created_at) DESC, id DESC
+LIMIT sqlc.arg(max_items);
@@ -0,0 +1,35 @@
+-- name: ListFeedViews :many
+SELECT * FROM feed_views
+WHERE feed_id = ?
+ORDER BY name, id;
+
+-- name: GetFeedView :one
+SELECT * FROM feed_views
+WHERE id = ? AND feed_id = ? LIMIT 1;
+
+-- name: CreateFeedView :one
+INSERT INTO feed_views (feed_id, name, title, max_items)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: UpdateFeedView :exec
+UPDATE feed_views
+SET name = ?, title = ?, max_items = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND feed_id = ?;
+
+-- name: DeleteFeedView :exec
+DELETE FROM feed_views
+WHERE id = ? AND feed_id = ?;
+
+-- name: ListFeedViewFilterRules :many
+SELECT * FROM feed_view_filter_rules
+WHERE view_id = ?
+ORDER BY position, id;
+
+-- name: CreateFeedViewFilterRule :exec
+INSERT INTO feed_view_filter_rules (view_id, field, operator, value, position)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: DeleteFeedViewFilterRules :exec
+DELETE FROM feed_view_filter_rules
+WHERE view_id = ?;
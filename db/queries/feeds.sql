@@ -14,13 +14,39 @@ GROUP BY f.id
 ORDER BY f.id;
 
 -- name: CreateFeed :one
-INSERT INTO feeds (name, url, item_selector, title_selector, link_selector, description_selector, date_selector)
-VALUES (?, ?, ?, ?, ?, ?, ?)
+INSERT INTO feeds (name, url, extra_urls, source_type, item_selector, title_selector, link_selector, description_selector, date_selector, mode, description_format, remove_selectors, filter_expression, map_expression,
+    global_pattern, item_pattern, title_template, link_template, description_template, date_template, language, description)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: UpdateFeed :exec
 UPDATE feeds
-SET name = ?, url = ?, item_selector = ?, title_selector = ?, link_selector = ?, description_selector = ?, date_selector = ?, updated_at = CURRENT_TIMESTAMP
+SET name = sqlc.arg(name), url = sqlc.arg(url), extra_urls = sqlc.arg(extra_urls), source_type = sqlc.arg(source_type),
+    item_selector = sqlc.arg(item_selector), title_selector = sqlc.arg(title_selector), link_selector = sqlc.arg(link_selector),
+    description_selector = sqlc.arg(description_selector), date_selector = sqlc.arg(date_selector), mode = sqlc.arg(mode),
+    description_format = sqlc.arg(description_format), remove_selectors = sqlc.arg(remove_selectors),
+    filter_expression = sqlc.arg(filter_expression), map_expression = sqlc.arg(map_expression),
+    global_pattern = sqlc.arg(global_pattern), item_pattern = sqlc.arg(item_pattern), title_template = sqlc.arg(title_template),
+    link_template = sqlc.arg(link_template), description_template = sqlc.arg(description_template), date_template = sqlc.arg(date_template),
+    language = sqlc.arg(language), description = sqlc.arg(description),
+    -- A different monitored region starts from a new snapshot
+    last_snapshot = CASE WHEN (url, mode, item_selector) IS (sqlc.arg(url), sqlc.arg(mode), sqlc.arg(item_selector)) THEN last_snapshot END,
+    -- New extraction settings start a new baseline for breakage detection
+    (last_item_count, broken_reason, broken_since) = (
+        SELECT last_item_count, broken_reason, broken_since
+        WHERE (url, extra_urls, source_type, item_selector, title_selector, link_selector, description_selector, date_selector, mode,
+               global_pattern, item_pattern, title_template, link_template, description_template, date_template)
+           IS (sqlc.arg(url), sqlc.arg(extra_urls), sqlc.arg(source_type), sqlc.arg(item_selector), sqlc.arg(title_selector),
+               sqlc.arg(link_selector), sqlc.arg(description_selector), sqlc.arg(date_selector), sqlc.arg(mode),
+               sqlc.arg(global_pattern), sqlc.arg(item_pattern), sqlc.arg(title_template), sqlc.arg(link_template),
+               sqlc.arg(description_template), sqlc.arg(date_template))
+    ),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateFeedRetention :exec
+UPDATE feeds
+SET retention_max_items = ?, retention_max_age_days = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
 -- name: UpdateFeedLastRefreshedAt :exec
@@ -28,6 +54,16 @@ UPDATE feeds
 SET last_refreshed_at = ?, updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
+-- name: UpdateFeedHealth :exec
+UPDATE feeds
+SET last_item_count = ?, broken_reason = ?, broken_since = ?
+WHERE id = ?;
+
+-- name: UpdateFeedSnapshot :exec
+UPDATE feeds
+SET last_snapshot = ?
+WHERE id = ?;
+
 -- name: DeleteFeed :exec
 DELETE FROM feeds
 WHERE id = ?;
@@ -0,0 +1,57 @@
+-- name: GetTagByName :one
+SELECT * FROM tags
+WHERE name = ? LIMIT 1;
+
+-- name: ListTagsWithFeedsCount :many
+SELECT t.*, COUNT(ft.feed_id) AS feeds_count
+FROM tags t
+JOIN feed_tags ft ON t.id = ft.tag_id
+GROUP BY t.id
+ORDER BY t.name;
+
+-- name: UpsertTag :one
+INSERT INTO tags (name)
+VALUES (?)
+ON CONFLICT(name) DO UPDATE SET name = tags.name
+RETURNING *;
+
+-- name: AddFeedTag :exec
+INSERT INTO feed_tags (feed_id, tag_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFeedTags :exec
+DELETE FROM feed_tags
+WHERE feed_id = ?;
+
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags
+WHERE id NOT IN (SELECT tag_id FROM feed_tags);
+
+-- name: ListFeedTagNames :many
+SELECT ft.feed_id, t.name
+FROM feed_tags ft
+JOIN tags t ON t.id = ft.tag_id
+ORDER BY t.name;
+
+-- name: ListTagNamesByFeed :many
+SELECT t.name
+FROM tags t
+JOIN feed_tags ft ON t.id = ft.tag_id
+WHERE ft.feed_id = ?
+ORDER BY t.name;
+
+-- name: ListTagFeeds :many
+SELECT f.* FROM feeds f
+JOIN feed_tags ft ON f.id = ft.feed_id
+WHERE ft.tag_id = ?
+ORDER BY f.name, f.id;
+
+-- name: ListTagItems :many
+SELECT sqlc.embed(i), f.name AS feed_name
+FROM feed_items i
+JOIN feeds f ON f.id = i.feed_id
+JOIN feed_tags ft ON ft.feed_id = i.feed_id
+WHERE ft.tag_id = sqlc.arg(tag_id)
+ORDER BY COALESCE(i.date, i.created_at) DESC, i.id DESC
+LIMIT sqlc.arg(max_items);
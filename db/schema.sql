@@ -1,23 +1,114 @@
 CREATE TABLE schema_migrations (version uint64,dirty bool);
 CREATE UNIQUE INDEX version_unique ON schema_migrations (version);
-CREATE TABLE feeds (
-    id INTEGER PRIMARY KEY,
-    name TEXT NOT NULL,
-    url TEXT NOT NULL,
-    item_selector TEXT,
-    title_selector TEXT,
-    link_selector TEXT,
-    description_selector TEXT,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT NULL
-, last_refreshed_at TIMESTAMP, date_selector TEXT);
-CREATE TABLE feed_items (
-    id INTEGER PRIMARY KEY,
-    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
-    title TEXT NOT NULL,
-    description TEXT,
-    link TEXT NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, date TIMESTAMP,
-    UNIQUE(feed_id, link)
+CREATE TABLE feeds (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    item_selector TEXT,
+    title_selector TEXT,
+    link_selector TEXT,
+    description_selector TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT NULL
+, last_refreshed_at TIMESTAMP, date_selector TEXT, mode TEXT NOT NULL DEFAULT 'list', last_snapshot TEXT, retention_max_items INTEGER, retention_max_age_days INTEGER, description_format TEXT NOT NULL DEFAULT 'html', remove_selectors TEXT, last_item_count INTEGER, broken_reason TEXT, broken_since DATETIME, extra_urls TEXT, source_type TEXT NOT NULL DEFAULT 'css', filter_expression TEXT, map_expression TEXT, global_pattern TEXT, item_pattern TEXT, title_template TEXT, link_template TEXT, description_template TEXT, date_template TEXT, language TEXT, description TEXT);
+CREATE TABLE feed_items (
+    id INTEGER PRIMARY KEY,
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT,
+    link TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, date TIMESTAMP,
+    UNIQUE(feed_id, link)
 );
+CREATE TABLE feed_item_revisions (
+    id INTEGER PRIMARY KEY,
+    feed_item_id INTEGER NOT NULL REFERENCES feed_items(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT,
+    date TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_feed_item_revisions_feed_item_id ON feed_item_revisions(feed_item_id);
+CREATE TABLE feed_filter_rules (
+    id INTEGER PRIMARY KEY,
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    operator TEXT NOT NULL,
+    value TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_feed_filter_rules_feed_id ON feed_filter_rules(feed_id);
+CREATE INDEX idx_feed_items_feed_id_created_at ON feed_items(feed_id, created_at);
+CREATE TABLE feed_auth (
+    feed_id INTEGER PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    password TEXT NOT NULL DEFAULT '',
+    token TEXT NOT NULL DEFAULT '',
+    login_url TEXT NOT NULL DEFAULT '',
+    login_fields TEXT NOT NULL DEFAULT '',
+    login_check TEXT NOT NULL DEFAULT '',
+    cookies TEXT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE collections (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE collection_feeds (
+    collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    PRIMARY KEY (collection_id, feed_id)
+);
+CREATE INDEX idx_collection_feeds_feed_id ON collection_feeds(feed_id);
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE feed_tags (
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (feed_id, tag_id)
+);
+CREATE INDEX idx_feed_tags_tag_id ON feed_tags(tag_id);
+CREATE TABLE feed_views (
+    id INTEGER PRIMARY KEY,
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    title TEXT,
+    max_items INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_feed_views_feed_id ON feed_views(feed_id);
+CREATE TABLE feed_view_filter_rules (
+    id INTEGER PRIMARY KEY,
+    view_id INTEGER NOT NULL REFERENCES feed_views(id) ON DELETE CASCADE,
+    field TEXT NOT NULL,
+    operator TEXT NOT NULL,
+    value TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_feed_view_filter_rules_view_id ON feed_view_filter_rules(view_id);
+CREATE TABLE feed_item_tombstones (
+    feed_id INTEGER NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    link TEXT NOT NULL,
+    pruned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (feed_id, link)
+);
+CREATE TRIGGER skip_pruned_feed_items
+BEFORE INSERT ON feed_items
+WHEN EXISTS (
+    SELECT 1 FROM feed_item_tombstones t
+    WHERE t.feed_id = NEW.feed_id AND t.link = NEW.link
+)
+BEGIN
+    SELECT RAISE(IGNORE);
+END;
+CREATE INDEX idx_feed_items_feed_id_output_date ON feed_items(feed_id, COALESCE(date, created_at));
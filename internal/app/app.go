@@ -37,6 +37,7 @@ type Queries interface {
 	ListFeedItems(ctx context.Context, feedID int64) ([]db.FeedItem, error)
 	UpsertFeedItem(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error)
 	DeleteItemsByFeedID(ctx context.Context, feedID int64) error
+	DeleteFeedItemTombstones(ctx context.Context, feedID int64) error
 }
 
 // App represents the main application
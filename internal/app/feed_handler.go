@@ -44,13 +44,27 @@ func (a *App) handleDuplicateFeed(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := struct {
-		ID            int64
-		Name          string
-		Url           string
-		ItemSelector  string
-		TitleSelector string
-		LinkSelector  string
-		DateSelector  string
+		ID                int64
+		Name              string
+		Url               string
+		ExtraURLs         string
+		ItemSelector      string
+		TitleSelector     string
+		LinkSelector      string
+		DateSelector      string
+		Mode              string
+		DescriptionFormat string
+		SourceType        string
+		SourceTypes       []string
+		RemoveSelectors   string
+		Auth              authSettings
+		Tags              string
+		Filters           string
+		FilterExpression  string
+		MapExpression     string
+		Patterns          patternSettings
+		Language          string
+		Description       string
 	}{
 		ID:            feed.ID,
 		Name:          feed.Name + " (copy)",
@@ -59,6 +73,7 @@ func (a *App) handleDuplicateFeed(w http.ResponseWriter, r *http.Request) {
 		TitleSelector: nullStringToString(feed.TitleSelector),
 		LinkSelector:  nullStringToString(feed.LinkSelector),
 		DateSelector:  nullStringToString(feed.DateSelector),
+		Mode:          feed.Mode,
 	}
 
 	a.renderNewFeed(w, data)
@@ -197,14 +212,42 @@ func (a *App) handlePreviewFeed(w http.ResponseWriter, r *http.Request) {
 	type PageData struct {
 		ExistingSelectors []Selector
 		// any other fields, e.g. for preview
-		ItemSelector  string
-		TitleSelector string
-		LinkSelector  string
-		DateSelector  string
-		FirstHTML     string
-		FirstTitle    string
-		FirstLink     string
-		FirstDate     string
+		Mode              string
+		DescriptionFormat string
+		SourceType        string
+		SourceTypes       []string
+		RemoveSelectors   string
+		ItemSelector      string
+		TitleSelector     string
+		LinkSelector      string
+		DateSelector      string
+		FirstHTML         string
+		FirstTitle        string
+		FirstLink         string
+		FirstDate         string
+		ExtractError      string
+		Snapshot          string
+		Filters           string
+		FilterError       string
+		FilterResults     []struct {
+			Title  string
+			Link   string
+			Kept   bool
+			Reason string
+		}
+		FilterExpression  string
+		MapExpression     string
+		ExpressionError   string
+		ExpressionResults []struct {
+			Title       string
+			Link        string
+			Kept        bool
+			MappedTitle string
+			MappedLink  string
+			Error       string
+		}
+		Patterns     patternSettings
+		ResolvedURLs []string
 	}
 
 	ExistingSelectors, err := a.queries.ListFeeds(r.Context())
@@ -316,11 +359,27 @@ func (a *App) handleEditFeed(w http.ResponseWriter, r *http.Request) {
 		ID                  int64
 		Name                string
 		Url                 string
+		ExtraURLs           string
 		ItemSelector        string
 		TitleSelector       string
 		LinkSelector        string
 		DescriptionSelector string
 		DateSelector        string
+		Mode                string
+		DescriptionFormat   string
+		SourceType          string
+		SourceTypes         []string
+		RemoveSelectors     string
+		Auth                authSettings
+		Tags                string
+		Filters             string
+		FilterExpression    string
+		MapExpression       string
+		RetentionMaxItems   string
+		RetentionMaxAgeDays string
+		Patterns            patternSettings
+		Language            string
+		Description         string
 	}{
 		ID:                  feed.ID,
 		Name:                feed.Name,
@@ -330,6 +389,7 @@ func (a *App) handleEditFeed(w http.ResponseWriter, r *http.Request) {
 		LinkSelector:        nullStringToString(feed.LinkSelector),
 		DescriptionSelector: nullStringToString(feed.DescriptionSelector),
 		DateSelector:        nullStringToString(feed.DateSelector),
+		Mode:                feed.Mode,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -440,6 +500,13 @@ func (a *App) handleResetFeedItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Forget the items removed by retention too, so that the next refresh
+	// starts over
+	if err := a.queries.DeleteFeedItemTombstones(r.Context(), feedID); err != nil {
+		http.Error(w, "Failed to reset feed items", http.StatusInternalServerError)
+		return
+	}
+
 	// Redirect back to the homepage after successful reset
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
@@ -481,3 +548,24 @@ func nullStringToString(ns sql.NullString) string {
 	}
 	return ""
 }
+
+// authSettings mirrors the authentication fields rendered by the feed forms
+type authSettings struct {
+	Type        string
+	Username    string
+	Password    string
+	Token       string
+	LoginURL    string
+	LoginFields string
+	LoginCheck  string
+}
+
+// patternSettings mirrors the search pattern fields rendered by the feed forms
+type patternSettings struct {
+	GlobalPattern       string
+	ItemPattern         string
+	TitleTemplate       string
+	LinkTemplate        string
+	DescriptionTemplate string
+	DateTemplate        string
+}
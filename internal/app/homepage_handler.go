@@ -17,7 +17,22 @@ type HomePageData struct {
 	GoVersion  string
 	BuildTime  string
 	Uptime     string
-	Feeds      []db.ListFeedsWithItemsCountRow
+	Feeds      []feedRow
+	// Feeds whose selectors stopped matching, shown above the list
+	BrokenFeeds []db.ListFeedsWithItemsCountRow
+	Collections []db.ListCollectionsWithFeedsCountRow
+	Tags        []db.ListTagsWithFeedsCountRow
+	Tag         string
+	Groups      []struct {
+		Tag   string
+		Feeds []feedRow
+	}
+}
+
+// feedRow is a feed of the home page list along with its tags
+type feedRow struct {
+	db.ListFeedsWithItemsCountRow
+	Tags []string
 }
 
 // handleHomepage renders the homepage using HTML templates
@@ -35,6 +50,11 @@ func (a *App) handleHomepage(w http.ResponseWriter, r *http.Request) {
 	}
 	fmt.Printf("Feeds: %+v\n", feeds) // For debugging; remove in production
 
+	rows := make([]feedRow, 0, len(feeds))
+	for _, f := range feeds {
+		rows = append(rows, feedRow{ListFeedsWithItemsCountRow: f})
+	}
+
 	data := HomePageData{
 		Title:      "Home",
 		Version:    "0.1.0",
@@ -42,7 +62,7 @@ func (a *App) handleHomepage(w http.ResponseWriter, r *http.Request) {
 		GoVersion:  runtime.Version(),
 		BuildTime:  "2025-09-11", // You can make this dynamic with build flags
 		Uptime:     time.Since(a.startTime).Round(time.Second).String(),
-		Feeds:      feeds,
+		Feeds:      rows,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -20,6 +20,7 @@ type mockQueries struct {
 	ListFeedItemsFn             func(ctx context.Context, feedID int64) ([]db.FeedItem, error)
 	UpsertFeedItemFn            func(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error)
 	DeleteItemsByFeedIDFn       func(ctx context.Context, feedID int64) error
+	DeleteFeedItemTombstonesFn  func(ctx context.Context, feedID int64) error
 }
 
 func (m *mockQueries) GetFeed(ctx context.Context, id int64) (db.Feed, error) {
@@ -82,3 +83,9 @@ func (m *mockQueries) DeleteItemsByFeedID(ctx context.Context, feedID int64) err
 	}
 	return nil
 }
+func (m *mockQueries) DeleteFeedItemTombstones(ctx context.Context, feedID int64) error {
+	if m.DeleteFeedItemTombstonesFn != nil {
+		return m.DeleteFeedItemTombstonesFn(ctx, feedID)
+	}
+	return nil
+}
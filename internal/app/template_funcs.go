@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"html/template"
 	"log"
+	"net/url"
 	"time"
 )
 
@@ -26,5 +27,8 @@ func NewTemplateFuncs(cfg *Config) template.FuncMap {
 			// Format: YYYY-MM-DD HH:MM:SS MST
 			return localTime.Format("2006-01-02 15:04:05 MST")
 		},
+		// Escape values used as a URL path segment, such as tag names,
+		// which may contain slashes and question marks
+		"pathEscape": url.PathEscape,
 	}
 }
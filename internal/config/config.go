@@ -1,7 +1,11 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds the application configuration
@@ -11,6 +15,31 @@ type Config struct {
 	DataDir     string
 	Timezone    string
 	TemplateDir string
+	// Absolute URL the app is served at, e.g. behind a reverse proxy, used
+	// in links to its feeds (empty = the host of each request)
+	BaseURL string
+
+	// Default retention for feeds without their own settings (0 = unlimited)
+	RetentionMaxItems   int
+	RetentionMaxAgeDays int
+	// How often old items are pruned and the database compacted (0 = never)
+	MaintenanceInterval time.Duration
+
+	// HTML elements kept in item descriptions (empty = built-in allowlist)
+	SanitizeAllowedElements []string
+
+	// Number of items generated feeds list unless asked for another limit
+	FeedItemsLimit int
+
+	// Number of raw responses kept per feed in the data directory (0 = none)
+	ResponseHistory int
+
+	// Webhooks notified when a feed breaks or recovers
+	NotifyWebhookURLs []string
+
+	// Limits of a single run of a WebAssembly extractor plugin
+	PluginMemoryMB int
+	PluginTimeout  time.Duration
 }
 
 var (
@@ -26,7 +55,33 @@ func LoadConfig() *Config {
 		DataDir:     getEnv("DATA_DIR", "./data"),
 		Timezone:    getEnv("APP_TIMEZONE", "UTC"),
 		TemplateDir: getEnv("TEMPLATE_DIR", "templates"),
+		BaseURL:     strings.TrimSuffix(getEnv("BASE_URL", ""), "/"),
+
+		RetentionMaxItems:   getEnvInt("RETENTION_MAX_ITEMS", 0),
+		RetentionMaxAgeDays: getEnvInt("RETENTION_MAX_AGE_DAYS", 0),
+		MaintenanceInterval: getEnvDuration("MAINTENANCE_INTERVAL", 24*time.Hour),
+
+		SanitizeAllowedElements: getEnvList("SANITIZE_ALLOWED_ELEMENTS"),
+
+		FeedItemsLimit: getEnvInt("FEED_ITEMS_LIMIT", 100),
+
+		ResponseHistory: getEnvInt("RESPONSE_HISTORY", 5),
+
+		NotifyWebhookURLs: getEnvList("NOTIFY_WEBHOOK_URLS"),
+
+		PluginMemoryMB: getEnvInt("PLUGIN_MEMORY_MB", 64),
+		PluginTimeout:  getEnvDuration("PLUGIN_TIMEOUT", 10*time.Second),
+	}
+}
+
+// Location returns the configured time zone, falling back to UTC
+func (c *Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		log.Printf("Invalid timezone '%s', falling back to UTC: %v", c.Timezone, err)
+		return time.UTC
 	}
+	return loc
 }
 
 func getEnv(key, fallback string) string {
@@ -35,3 +90,39 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s '%s', using %d: %v", key, value, fallback, err)
+		return fallback
+	}
+	return n
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s '%s', using %s: %v", key, value, fallback, err)
+		return fallback
+	}
+	return d
+}
+
+func getEnvList(key string) []string {
+	var list []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,259 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: collections.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const addCollectionFeed = `-- name: AddCollectionFeed :exec
+INSERT INTO collection_feeds (collection_id, feed_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING
+`
+
+type AddCollectionFeedParams struct {
+	CollectionID int64 `json:"collection_id"`
+	FeedID       int64 `json:"feed_id"`
+}
+
+func (q *Queries) AddCollectionFeed(ctx context.Context, arg AddCollectionFeedParams) error {
+	_, err := q.db.ExecContext(ctx, addCollectionFeed, arg.CollectionID, arg.FeedID)
+	return err
+}
+
+const createCollection = `-- name: CreateCollection :one
+INSERT INTO collections (name)
+VALUES (?)
+RETURNING id, name, created_at, updated_at
+`
+
+func (q *Queries) CreateCollection(ctx context.Context, name string) (Collection, error) {
+	row := q.db.QueryRowContext(ctx, createCollection, name)
+	var i Collection
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteCollection = `-- name: DeleteCollection :exec
+DELETE FROM collections
+WHERE id = ?
+`
+
+func (q *Queries) DeleteCollection(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteCollection, id)
+	return err
+}
+
+const deleteCollectionFeeds = `-- name: DeleteCollectionFeeds :exec
+DELETE FROM collection_feeds
+WHERE collection_id = ?
+`
+
+func (q *Queries) DeleteCollectionFeeds(ctx context.Context, collectionID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteCollectionFeeds, collectionID)
+	return err
+}
+
+const getCollection = `-- name: GetCollection :one
+SELECT id, name, created_at, updated_at FROM collections
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetCollection(ctx context.Context, id int64) (Collection, error) {
+	row := q.db.QueryRowContext(ctx, getCollection, id)
+	var i Collection
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listCollectionFeeds = `-- name: ListCollectionFeeds :many
+SELECT f.id, f.name, f.url, f.item_selector, f.title_selector, f.link_selector, f.description_selector, f.created_at, f.updated_at, f.last_refreshed_at, f.date_selector, f.mode, f.last_snapshot, f.retention_max_items, f.retention_max_age_days, f.description_format, f.remove_selectors, f.last_item_count, f.broken_reason, f.broken_since, f.extra_urls, f.source_type, f.filter_expression, f.map_expression, f.global_pattern, f.item_pattern, f.title_template, f.link_template, f.description_template, f.date_template, f.language, f.description FROM feeds f
+JOIN collection_feeds cf ON f.id = cf.feed_id
+WHERE cf.collection_id = ?
+ORDER BY f.name, f.id
+`
+
+func (q *Queries) ListCollectionFeeds(ctx context.Context, collectionID int64) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listCollectionFeeds, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.ItemSelector,
+			&i.TitleSelector,
+			&i.LinkSelector,
+			&i.DescriptionSelector,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastRefreshedAt,
+			&i.DateSelector,
+			&i.Mode,
+			&i.LastSnapshot,
+			&i.RetentionMaxItems,
+			&i.RetentionMaxAgeDays,
+			&i.DescriptionFormat,
+			&i.RemoveSelectors,
+			&i.LastItemCount,
+			&i.BrokenReason,
+			&i.BrokenSince,
+			&i.ExtraUrls,
+			&i.SourceType,
+			&i.FilterExpression,
+			&i.MapExpression,
+			&i.GlobalPattern,
+			&i.ItemPattern,
+			&i.TitleTemplate,
+			&i.LinkTemplate,
+			&i.DescriptionTemplate,
+			&i.DateTemplate,
+			&i.Language,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCollectionItems = `-- name: ListCollectionItems :many
+SELECT i.id, i.feed_id, i.title, i.description, i.link, i.created_at, i.updated_at, i.date, f.name AS feed_name
+FROM feed_items i
+JOIN feeds f ON f.id = i.feed_id
+JOIN collection_feeds cf ON cf.feed_id = i.feed_id
+WHERE cf.collection_id = ?1
+ORDER BY COALESCE(i.date, i.created_at) DESC, i.id DESC
+LIMIT ?2
+`
+
+type ListCollectionItemsParams struct {
+	CollectionID int64 `json:"collection_id"`
+	MaxItems     int64 `json:"max_items"`
+}
+
+type ListCollectionItemsRow struct {
+	FeedItem FeedItem `json:"feed_item"`
+	FeedName string   `json:"feed_name"`
+}
+
+func (q *Queries) ListCollectionItems(ctx context.Context, arg ListCollectionItemsParams) ([]ListCollectionItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCollectionItems, arg.CollectionID, arg.MaxItems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCollectionItemsRow
+	for rows.Next() {
+		var i ListCollectionItemsRow
+		if err := rows.Scan(
+			&i.FeedItem.ID,
+			&i.FeedItem.FeedID,
+			&i.FeedItem.Title,
+			&i.FeedItem.Description,
+			&i.FeedItem.Link,
+			&i.FeedItem.CreatedAt,
+			&i.FeedItem.UpdatedAt,
+			&i.FeedItem.Date,
+			&i.FeedName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCollectionsWithFeedsCount = `-- name: ListCollectionsWithFeedsCount :many
+SELECT c.id, c.name, c.created_at, c.updated_at, COUNT(cf.feed_id) AS feeds_count
+FROM collections c
+LEFT JOIN collection_feeds cf ON c.id = cf.collection_id
+GROUP BY c.id
+ORDER BY c.name, c.id
+`
+
+type ListCollectionsWithFeedsCountRow struct {
+	ID         int64        `json:"id"`
+	Name       string       `json:"name"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+	UpdatedAt  sql.NullTime `json:"updated_at"`
+	FeedsCount int64        `json:"feeds_count"`
+}
+
+func (q *Queries) ListCollectionsWithFeedsCount(ctx context.Context) ([]ListCollectionsWithFeedsCountRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCollectionsWithFeedsCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCollectionsWithFeedsCountRow
+	for rows.Next() {
+		var i ListCollectionsWithFeedsCountRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FeedsCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCollection = `-- name: UpdateCollection :exec
+UPDATE collections
+SET name = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateCollectionParams struct {
+	Name string `json:"name"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) UpdateCollection(ctx context.Context, arg UpdateCollectionParams) error {
+	_, err := q.db.ExecContext(ctx, updateCollection, arg.Name, arg.ID)
+	return err
+}
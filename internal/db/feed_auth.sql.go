@@ -0,0 +1,101 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: feed_auth.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const deleteFeedAuth = `-- name: DeleteFeedAuth :exec
+DELETE FROM feed_auth
+WHERE feed_id = ?
+`
+
+func (q *Queries) DeleteFeedAuth(ctx context.Context, feedID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedAuth, feedID)
+	return err
+}
+
+const getFeedAuth = `-- name: GetFeedAuth :one
+SELECT feed_id, type, username, password, token, login_url, login_fields, login_check, cookies, updated_at FROM feed_auth
+WHERE feed_id = ? LIMIT 1
+`
+
+func (q *Queries) GetFeedAuth(ctx context.Context, feedID int64) (FeedAuth, error) {
+	row := q.db.QueryRowContext(ctx, getFeedAuth, feedID)
+	var i FeedAuth
+	err := row.Scan(
+		&i.FeedID,
+		&i.Type,
+		&i.Username,
+		&i.Password,
+		&i.Token,
+		&i.LoginUrl,
+		&i.LoginFields,
+		&i.LoginCheck,
+		&i.Cookies,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateFeedAuthCookies = `-- name: UpdateFeedAuthCookies :exec
+UPDATE feed_auth
+SET cookies = ?, updated_at = CURRENT_TIMESTAMP
+WHERE feed_id = ?
+`
+
+type UpdateFeedAuthCookiesParams struct {
+	Cookies sql.NullString `json:"cookies"`
+	FeedID  int64          `json:"feed_id"`
+}
+
+func (q *Queries) UpdateFeedAuthCookies(ctx context.Context, arg UpdateFeedAuthCookiesParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedAuthCookies, arg.Cookies, arg.FeedID)
+	return err
+}
+
+const upsertFeedAuth = `-- name: UpsertFeedAuth :exec
+INSERT INTO feed_auth (feed_id, type, username, password, token, login_url, login_fields, login_check, cookies, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULL, CURRENT_TIMESTAMP)
+ON CONFLICT(feed_id) DO UPDATE SET
+    type = excluded.type,
+    username = excluded.username,
+    password = excluded.password,
+    token = excluded.token,
+    login_url = excluded.login_url,
+    login_fields = excluded.login_fields,
+    login_check = excluded.login_check,
+    cookies = NULL,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertFeedAuthParams struct {
+	FeedID      int64  `json:"feed_id"`
+	Type        string `json:"type"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	Token       string `json:"token"`
+	LoginUrl    string `json:"login_url"`
+	LoginFields string `json:"login_fields"`
+	LoginCheck  string `json:"login_check"`
+}
+
+// Changing the credentials drops the session, forcing a new login
+func (q *Queries) UpsertFeedAuth(ctx context.Context, arg UpsertFeedAuthParams) error {
+	_, err := q.db.ExecContext(ctx, upsertFeedAuth,
+		arg.FeedID,
+		arg.Type,
+		arg.Username,
+		arg.Password,
+		arg.Token,
+		arg.LoginUrl,
+		arg.LoginFields,
+		arg.LoginCheck,
+	)
+	return err
+}
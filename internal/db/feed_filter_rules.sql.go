@@ -0,0 +1,81 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: feed_filter_rules.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createFeedFilterRule = `-- name: CreateFeedFilterRule :exec
+INSERT INTO feed_filter_rules (feed_id, field, operator, value, position)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateFeedFilterRuleParams struct {
+	FeedID   int64  `json:"feed_id"`
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+	Position int64  `json:"position"`
+}
+
+func (q *Queries) CreateFeedFilterRule(ctx context.Context, arg CreateFeedFilterRuleParams) error {
+	_, err := q.db.ExecContext(ctx, createFeedFilterRule,
+		arg.FeedID,
+		arg.Field,
+		arg.Operator,
+		arg.Value,
+		arg.Position,
+	)
+	return err
+}
+
+const deleteFeedFilterRules = `-- name: DeleteFeedFilterRules :exec
+DELETE FROM feed_filter_rules
+WHERE feed_id = ?
+`
+
+func (q *Queries) DeleteFeedFilterRules(ctx context.Context, feedID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedFilterRules, feedID)
+	return err
+}
+
+const listFeedFilterRules = `-- name: ListFeedFilterRules :many
+SELECT id, feed_id, field, operator, value, position, created_at FROM feed_filter_rules
+WHERE feed_id = ?
+ORDER BY position, id
+`
+
+func (q *Queries) ListFeedFilterRules(ctx context.Context, feedID int64) ([]FeedFilterRule, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedFilterRules, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedFilterRule
+	for rows.Next() {
+		var i FeedFilterRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.Field,
+			&i.Operator,
+			&i.Value,
+			&i.Position,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,79 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: feed_item_revisions.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createFeedItemRevision = `-- name: CreateFeedItemRevision :one
+INSERT INTO feed_item_revisions (feed_item_id, title, description, date)
+VALUES (?, ?, ?, ?)
+RETURNING id, feed_item_id, title, description, date, created_at
+`
+
+type CreateFeedItemRevisionParams struct {
+	FeedItemID  int64          `json:"feed_item_id"`
+	Title       string         `json:"title"`
+	Description sql.NullString `json:"description"`
+	Date        sql.NullTime   `json:"date"`
+}
+
+func (q *Queries) CreateFeedItemRevision(ctx context.Context, arg CreateFeedItemRevisionParams) (FeedItemRevision, error) {
+	row := q.db.QueryRowContext(ctx, createFeedItemRevision,
+		arg.FeedItemID,
+		arg.Title,
+		arg.Description,
+		arg.Date,
+	)
+	var i FeedItemRevision
+	err := row.Scan(
+		&i.ID,
+		&i.FeedItemID,
+		&i.Title,
+		&i.Description,
+		&i.Date,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listFeedItemRevisions = `-- name: ListFeedItemRevisions :many
+SELECT id, feed_item_id, title, description, date, created_at FROM feed_item_revisions
+WHERE feed_item_id = ?
+ORDER BY id
+`
+
+func (q *Queries) ListFeedItemRevisions(ctx context.Context, feedItemID int64) ([]FeedItemRevision, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedItemRevisions, feedItemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedItemRevision
+	for rows.Next() {
+		var i FeedItemRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedItemID,
+			&i.Title,
+			&i.Description,
+			&i.Date,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
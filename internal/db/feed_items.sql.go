@@ -10,6 +10,16 @@ import (
 	"database/sql"
 )
 
+const deleteFeedItemTombstones = `-- name: DeleteFeedItemTombstones :exec
+DELETE FROM feed_item_tombstones
+WHERE feed_id = ?
+`
+
+func (q *Queries) DeleteFeedItemTombstones(ctx context.Context, feedID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedItemTombstones, feedID)
+	return err
+}
+
 const deleteItemsByFeedID = `-- name: DeleteItemsByFeedID :exec
 DELETE FROM feed_items
 WHERE feed_id = ?
@@ -41,6 +51,51 @@ func (q *Queries) GetFeedItem(ctx context.Context, id int64) (FeedItem, error) {
 	return i, err
 }
 
+const getFeedItemByLink = `-- name: GetFeedItemByLink :one
+SELECT id, feed_id, title, description, link, created_at, updated_at, date FROM feed_items
+WHERE feed_id = ? AND link = ? LIMIT 1
+`
+
+type GetFeedItemByLinkParams struct {
+	FeedID int64  `json:"feed_id"`
+	Link   string `json:"link"`
+}
+
+func (q *Queries) GetFeedItemByLink(ctx context.Context, arg GetFeedItemByLinkParams) (FeedItem, error) {
+	row := q.db.QueryRowContext(ctx, getFeedItemByLink, arg.FeedID, arg.Link)
+	var i FeedItem
+	err := row.Scan(
+		&i.ID,
+		&i.FeedID,
+		&i.Title,
+		&i.Description,
+		&i.Link,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Date,
+	)
+	return i, err
+}
+
+const getFeedItemsVersion = `-- name: GetFeedItemsVersion :one
+SELECT COUNT(*) AS items_count,
+    CAST(COALESCE(strftime('%s', MAX(updated_at)), 0) AS INTEGER) AS last_updated_unix
+FROM feed_items
+WHERE feed_id = ?
+`
+
+type GetFeedItemsVersionRow struct {
+	ItemsCount      int64 `json:"items_count"`
+	LastUpdatedUnix int64 `json:"last_updated_unix"`
+}
+
+func (q *Queries) GetFeedItemsVersion(ctx context.Context, feedID int64) (GetFeedItemsVersionRow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedItemsVersion, feedID)
+	var i GetFeedItemsVersionRow
+	err := row.Scan(&i.ItemsCount, &i.LastUpdatedUnix)
+	return i, err
+}
+
 const listFeedItems = `-- name: ListFeedItems :many
 SELECT id, feed_id, title, description, link, created_at, updated_at, date FROM feed_items
 WHERE feed_id = ?
@@ -79,6 +134,186 @@ func (q *Queries) ListFeedItems(ctx context.Context, feedID int64) ([]FeedItem,
 	return items, nil
 }
 
+const listFeedItemsPage = `-- name: ListFeedItemsPage :many
+SELECT id, feed_id, title, description, link, created_at, updated_at, date FROM feed_items
+WHERE feed_id = ?1
+  AND (CAST(?2 AS TEXT) = ''
+       OR datetime(COALESCE(date, created_at)) >= datetime(?2))
+  AND (CAST(?3 AS TEXT) = ''
+       OR instr(lower(title), lower(?3)) > 0
+       OR instr(lower(description), lower(?3)) > 0)
+ORDER BY COALESCE(date, created_at) DESC, id DESC
+LIMIT ?4
+`
+
+type ListFeedItemsPageParams struct {
+	FeedID   int64  `json:"feed_id"`
+	Since    string `json:"since"`
+	Query    string `json:"query"`
+	MaxItems int64  `json:"max_items"`
+}
+
+func (q *Queries) ListFeedItemsPage(ctx context.Context, arg ListFeedItemsPageParams) ([]FeedItem, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedItemsPage,
+		arg.FeedID,
+		arg.Since,
+		arg.Query,
+		arg.MaxItems,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedItem
+	for rows.Next() {
+		var i FeedItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.Title,
+			&i.Description,
+			&i.Link,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Date,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pruneFeedItemsByAge = `-- name: PruneFeedItemsByAge :execrows
+DELETE FROM feed_items
+WHERE feed_id = ? AND created_at < ?
+`
+
+type PruneFeedItemsByAgeParams struct {
+	FeedID    int64        `json:"feed_id"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) PruneFeedItemsByAge(ctx context.Context, arg PruneFeedItemsByAgeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneFeedItemsByAge, arg.FeedID, arg.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneFeedItemsByCount = `-- name: PruneFeedItemsByCount :execrows
+DELETE FROM feed_items
+WHERE feed_items.feed_id = ?1
+  AND feed_items.id NOT IN (
+    SELECT kept.id FROM feed_items AS kept
+    WHERE kept.feed_id = ?1
+    ORDER BY COALESCE(kept.date, kept.created_at) DESC, kept.id DESC
+    LIMIT ?2
+  )
+`
+
+type PruneFeedItemsByCountParams struct {
+	FeedID   int64 `json:"feed_id"`
+	MaxItems int64 `json:"max_items"`
+}
+
+func (q *Queries) PruneFeedItemsByCount(ctx context.Context, arg PruneFeedItemsByCountParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneFeedItemsByCount, arg.FeedID, arg.MaxItems)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const tombstoneFeedItemsByAge = `-- name: TombstoneFeedItemsByAge :exec
+INSERT OR IGNORE INTO feed_item_tombstones (feed_id, link)
+SELECT i.feed_id, i.link FROM feed_items i
+WHERE i.feed_id = ?1 AND i.created_at < ?2
+`
+
+type TombstoneFeedItemsByAgeParams struct {
+	FeedID    int64        `json:"feed_id"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) TombstoneFeedItemsByAge(ctx context.Context, arg TombstoneFeedItemsByAgeParams) error {
+	_, err := q.db.ExecContext(ctx, tombstoneFeedItemsByAge, arg.FeedID, arg.CreatedAt)
+	return err
+}
+
+const tombstoneFeedItemsByCount = `-- name: TombstoneFeedItemsByCount :exec
+INSERT OR IGNORE INTO feed_item_tombstones (feed_id, link)
+SELECT feed_items.feed_id, feed_items.link FROM feed_items
+WHERE feed_items.feed_id = ?1
+  AND feed_items.id NOT IN (
+    SELECT kept.id FROM feed_items AS kept
+    WHERE kept.feed_id = ?1
+    ORDER BY COALESCE(kept.date, kept.created_at) DESC, kept.id DESC
+    LIMIT ?2
+  )
+`
+
+type TombstoneFeedItemsByCountParams struct {
+	FeedID   int64 `json:"feed_id"`
+	MaxItems int64 `json:"max_items"`
+}
+
+func (q *Queries) TombstoneFeedItemsByCount(ctx context.Context, arg TombstoneFeedItemsByCountParams) error {
+	_, err := q.db.ExecContext(ctx, tombstoneFeedItemsByCount, arg.FeedID, arg.MaxItems)
+	return err
+}
+
+const trimFeedItemTombstones = `-- name: TrimFeedItemTombstones :exec
+DELETE FROM feed_item_tombstones
+WHERE feed_item_tombstones.feed_id = ?1
+  AND feed_item_tombstones.link NOT IN (
+    SELECT kept.link FROM feed_item_tombstones AS kept
+    WHERE kept.feed_id = ?1
+    ORDER BY kept.pruned_at DESC, kept.rowid DESC
+    LIMIT ?2
+  )
+`
+
+type TrimFeedItemTombstonesParams struct {
+	FeedID        int64 `json:"feed_id"`
+	MaxTombstones int64 `json:"max_tombstones"`
+}
+
+func (q *Queries) TrimFeedItemTombstones(ctx context.Context, arg TrimFeedItemTombstonesParams) error {
+	_, err := q.db.ExecContext(ctx, trimFeedItemTombstones, arg.FeedID, arg.MaxTombstones)
+	return err
+}
+
+const updateFeedItemContent = `-- name: UpdateFeedItemContent :exec
+UPDATE feed_items
+SET title = ?, description = ?, date = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateFeedItemContentParams struct {
+	Title       string         `json:"title"`
+	Description sql.NullString `json:"description"`
+	Date        sql.NullTime   `json:"date"`
+	ID          int64          `json:"id"`
+}
+
+func (q *Queries) UpdateFeedItemContent(ctx context.Context, arg UpdateFeedItemContentParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedItemContent,
+		arg.Title,
+		arg.Description,
+		arg.Date,
+		arg.ID,
+	)
+	return err
+}
+
 const upsertFeedItem = `-- name: UpsertFeedItem :many
 INSERT INTO feed_items (feed_id, title, description, link, date, updated_at)
 VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
@@ -0,0 +1,217 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: feed_views.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createFeedView = `-- name: CreateFeedView :one
+INSERT INTO feed_views (feed_id, name, title, max_items)
+VALUES (?, ?, ?, ?)
+RETURNING id, feed_id, name, title, max_items, created_at, updated_at
+`
+
+type CreateFeedViewParams struct {
+	FeedID   int64          `json:"feed_id"`
+	Name     string         `json:"name"`
+	Title    sql.NullString `json:"title"`
+	MaxItems sql.NullInt64  `json:"max_items"`
+}
+
+func (q *Queries) CreateFeedView(ctx context.Context, arg CreateFeedViewParams) (FeedView, error) {
+	row := q.db.QueryRowContext(ctx, createFeedView,
+		arg.FeedID,
+		arg.Name,
+		arg.Title,
+		arg.MaxItems,
+	)
+	var i FeedView
+	err := row.Scan(
+		&i.ID,
+		&i.FeedID,
+		&i.Name,
+		&i.Title,
+		&i.MaxItems,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createFeedViewFilterRule = `-- name: CreateFeedViewFilterRule :exec
+INSERT INTO feed_view_filter_rules (view_id, field, operator, value, position)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateFeedViewFilterRuleParams struct {
+	ViewID   int64  `json:"view_id"`
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+	Position int64  `json:"position"`
+}
+
+func (q *Queries) CreateFeedViewFilterRule(ctx context.Context, arg CreateFeedViewFilterRuleParams) error {
+	_, err := q.db.ExecContext(ctx, createFeedViewFilterRule,
+		arg.ViewID,
+		arg.Field,
+		arg.Operator,
+		arg.Value,
+		arg.Position,
+	)
+	return err
+}
+
+const deleteFeedView = `-- name: DeleteFeedView :exec
+DELETE FROM feed_views
+WHERE id = ? AND feed_id = ?
+`
+
+type DeleteFeedViewParams struct {
+	ID     int64 `json:"id"`
+	FeedID int64 `json:"feed_id"`
+}
+
+func (q *Queries) DeleteFeedView(ctx context.Context, arg DeleteFeedViewParams) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedView, arg.ID, arg.FeedID)
+	return err
+}
+
+const deleteFeedViewFilterRules = `-- name: DeleteFeedViewFilterRules :exec
+DELETE FROM feed_view_filter_rules
+WHERE view_id = ?
+`
+
+func (q *Queries) DeleteFeedViewFilterRules(ctx context.Context, viewID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedViewFilterRules, viewID)
+	return err
+}
+
+const getFeedView = `-- name: GetFeedView :one
+SELECT id, feed_id, name, title, max_items, created_at, updated_at FROM feed_views
+WHERE id = ? AND feed_id = ? LIMIT 1
+`
+
+type GetFeedViewParams struct {
+	ID     int64 `json:"id"`
+	FeedID int64 `json:"feed_id"`
+}
+
+func (q *Queries) GetFeedView(ctx context.Context, arg GetFeedViewParams) (FeedView, error) {
+	row := q.db.QueryRowContext(ctx, getFeedView, arg.ID, arg.FeedID)
+	var i FeedView
+	err := row.Scan(
+		&i.ID,
+		&i.FeedID,
+		&i.Name,
+		&i.Title,
+		&i.MaxItems,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listFeedViewFilterRules = `-- name: ListFeedViewFilterRules :many
+SELECT id, view_id, field, operator, value, position, created_at FROM feed_view_filter_rules
+WHERE view_id = ?
+ORDER BY position, id
+`
+
+func (q *Queries) ListFeedViewFilterRules(ctx context.Context, viewID int64) ([]FeedViewFilterRule, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedViewFilterRules, viewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedViewFilterRule
+	for rows.Next() {
+		var i FeedViewFilterRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.ViewID,
+			&i.Field,
+			&i.Operator,
+			&i.Value,
+			&i.Position,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedViews = `-- name: ListFeedViews :many
+SELECT id, feed_id, name, title, max_items, created_at, updated_at FROM feed_views
+WHERE feed_id = ?
+ORDER BY name, id
+`
+
+func (q *Queries) ListFeedViews(ctx context.Context, feedID int64) ([]FeedView, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedViews, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedView
+	for rows.Next() {
+		var i FeedView
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.Name,
+			&i.Title,
+			&i.MaxItems,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFeedView = `-- name: UpdateFeedView :exec
+UPDATE feed_views
+SET name = ?, title = ?, max_items = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND feed_id = ?
+`
+
+type UpdateFeedViewParams struct {
+	Name     string         `json:"name"`
+	Title    sql.NullString `json:"title"`
+	MaxItems sql.NullInt64  `json:"max_items"`
+	ID       int64          `json:"id"`
+	FeedID   int64          `json:"feed_id"`
+}
+
+func (q *Queries) UpdateFeedView(ctx context.Context, arg UpdateFeedViewParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedView,
+		arg.Name,
+		arg.Title,
+		arg.MaxItems,
+		arg.ID,
+		arg.FeedID,
+	)
+	return err
+}
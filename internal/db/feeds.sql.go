@@ -11,30 +11,61 @@ import (
 )
 
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (name, url, item_selector, title_selector, link_selector, description_selector, date_selector)
-VALUES (?, ?, ?, ?, ?, ?, ?)
-RETURNING id, name, url, item_selector, title_selector, link_selector, description_selector, created_at, updated_at, last_refreshed_at, date_selector
+INSERT INTO feeds (name, url, extra_urls, source_type, item_selector, title_selector, link_selector, description_selector, date_selector, mode, description_format, remove_selectors, filter_expression, map_expression,
+    global_pattern, item_pattern, title_template, link_template, description_template, date_template, language, description)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, url, item_selector, title_selector, link_selector, description_selector, created_at, updated_at, last_refreshed_at, date_selector, mode, last_snapshot, retention_max_items, retention_max_age_days, description_format, remove_selectors, last_item_count, broken_reason, broken_since, extra_urls, source_type, filter_expression, map_expression, global_pattern, item_pattern, title_template, link_template, description_template, date_template, language, description
 `
 
 type CreateFeedParams struct {
 	Name                string         `json:"name"`
 	Url                 string         `json:"url"`
+	ExtraUrls           sql.NullString `json:"extra_urls"`
+	SourceType          string         `json:"source_type"`
 	ItemSelector        sql.NullString `json:"item_selector"`
 	TitleSelector       sql.NullString `json:"title_selector"`
 	LinkSelector        sql.NullString `json:"link_selector"`
 	DescriptionSelector sql.NullString `json:"description_selector"`
 	DateSelector        sql.NullString `json:"date_selector"`
+	Mode                string         `json:"mode"`
+	DescriptionFormat   string         `json:"description_format"`
+	RemoveSelectors     sql.NullString `json:"remove_selectors"`
+	FilterExpression    sql.NullString `json:"filter_expression"`
+	MapExpression       sql.NullString `json:"map_expression"`
+	GlobalPattern       sql.NullString `json:"global_pattern"`
+	ItemPattern         sql.NullString `json:"item_pattern"`
+	TitleTemplate       sql.NullString `json:"title_template"`
+	LinkTemplate        sql.NullString `json:"link_template"`
+	DescriptionTemplate sql.NullString `json:"description_template"`
+	DateTemplate        sql.NullString `json:"date_template"`
+	Language            sql.NullString `json:"language"`
+	Description         sql.NullString `json:"description"`
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
 	row := q.db.QueryRowContext(ctx, createFeed,
 		arg.Name,
 		arg.Url,
+		arg.ExtraUrls,
+		arg.SourceType,
 		arg.ItemSelector,
 		arg.TitleSelector,
 		arg.LinkSelector,
 		arg.DescriptionSelector,
 		arg.DateSelector,
+		arg.Mode,
+		arg.DescriptionFormat,
+		arg.RemoveSelectors,
+		arg.FilterExpression,
+		arg.MapExpression,
+		arg.GlobalPattern,
+		arg.ItemPattern,
+		arg.TitleTemplate,
+		arg.LinkTemplate,
+		arg.DescriptionTemplate,
+		arg.DateTemplate,
+		arg.Language,
+		arg.Description,
 	)
 	var i Feed
 	err := row.Scan(
@@ -49,6 +80,27 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.UpdatedAt,
 		&i.LastRefreshedAt,
 		&i.DateSelector,
+		&i.Mode,
+		&i.LastSnapshot,
+		&i.RetentionMaxItems,
+		&i.RetentionMaxAgeDays,
+		&i.DescriptionFormat,
+		&i.RemoveSelectors,
+		&i.LastItemCount,
+		&i.BrokenReason,
+		&i.BrokenSince,
+		&i.ExtraUrls,
+		&i.SourceType,
+		&i.FilterExpression,
+		&i.MapExpression,
+		&i.GlobalPattern,
+		&i.ItemPattern,
+		&i.TitleTemplate,
+		&i.LinkTemplate,
+		&i.DescriptionTemplate,
+		&i.DateTemplate,
+		&i.Language,
+		&i.Description,
 	)
 	return i, err
 }
@@ -64,7 +116,7 @@ func (q *Queries) DeleteFeed(ctx context.Context, id int64) error {
 }
 
 const getFeed = `-- name: GetFeed :one
-SELECT id, name, url, item_selector, title_selector, link_selector, description_selector, created_at, updated_at, last_refreshed_at, date_selector FROM feeds
+SELECT id, name, url, item_selector, title_selector, link_selector, description_selector, created_at, updated_at, last_refreshed_at, date_selector, mode, last_snapshot, retention_max_items, retention_max_age_days, description_format, remove_selectors, last_item_count, broken_reason, broken_since, extra_urls, source_type, filter_expression, map_expression, global_pattern, item_pattern, title_template, link_template, description_template, date_template, language, description FROM feeds
 WHERE id = ? LIMIT 1
 `
 
@@ -83,12 +135,33 @@ func (q *Queries) GetFeed(ctx context.Context, id int64) (Feed, error) {
 		&i.UpdatedAt,
 		&i.LastRefreshedAt,
 		&i.DateSelector,
+		&i.Mode,
+		&i.LastSnapshot,
+		&i.RetentionMaxItems,
+		&i.RetentionMaxAgeDays,
+		&i.DescriptionFormat,
+		&i.RemoveSelectors,
+		&i.LastItemCount,
+		&i.BrokenReason,
+		&i.BrokenSince,
+		&i.ExtraUrls,
+		&i.SourceType,
+		&i.FilterExpression,
+		&i.MapExpression,
+		&i.GlobalPattern,
+		&i.ItemPattern,
+		&i.TitleTemplate,
+		&i.LinkTemplate,
+		&i.DescriptionTemplate,
+		&i.DateTemplate,
+		&i.Language,
+		&i.Description,
 	)
 	return i, err
 }
 
 const listFeeds = `-- name: ListFeeds :many
-SELECT id, name, url, item_selector, title_selector, link_selector, description_selector, created_at, updated_at, last_refreshed_at, date_selector FROM feeds
+SELECT id, name, url, item_selector, title_selector, link_selector, description_selector, created_at, updated_at, last_refreshed_at, date_selector, mode, last_snapshot, retention_max_items, retention_max_age_days, description_format, remove_selectors, last_item_count, broken_reason, broken_since, extra_urls, source_type, filter_expression, map_expression, global_pattern, item_pattern, title_template, link_template, description_template, date_template, language, description FROM feeds
 ORDER BY id
 `
 
@@ -113,6 +186,27 @@ func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
 			&i.UpdatedAt,
 			&i.LastRefreshedAt,
 			&i.DateSelector,
+			&i.Mode,
+			&i.LastSnapshot,
+			&i.RetentionMaxItems,
+			&i.RetentionMaxAgeDays,
+			&i.DescriptionFormat,
+			&i.RemoveSelectors,
+			&i.LastItemCount,
+			&i.BrokenReason,
+			&i.BrokenSince,
+			&i.ExtraUrls,
+			&i.SourceType,
+			&i.FilterExpression,
+			&i.MapExpression,
+			&i.GlobalPattern,
+			&i.ItemPattern,
+			&i.TitleTemplate,
+			&i.LinkTemplate,
+			&i.DescriptionTemplate,
+			&i.DateTemplate,
+			&i.Language,
+			&i.Description,
 		); err != nil {
 			return nil, err
 		}
@@ -128,7 +222,7 @@ func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const listFeedsWithItemsCount = `-- name: ListFeedsWithItemsCount :many
-SELECT f.id, f.name, f.url, f.item_selector, f.title_selector, f.link_selector, f.description_selector, f.created_at, f.updated_at, f.last_refreshed_at, f.date_selector, COUNT(i.id) AS items_count
+SELECT f.id, f.name, f.url, f.item_selector, f.title_selector, f.link_selector, f.description_selector, f.created_at, f.updated_at, f.last_refreshed_at, f.date_selector, f.mode, f.last_snapshot, f.retention_max_items, f.retention_max_age_days, f.description_format, f.remove_selectors, f.last_item_count, f.broken_reason, f.broken_since, f.extra_urls, f.source_type, f.filter_expression, f.map_expression, f.global_pattern, f.item_pattern, f.title_template, f.link_template, f.description_template, f.date_template, f.language, f.description, COUNT(i.id) AS items_count
 FROM feeds f
 LEFT JOIN feed_items i ON f.id = i.feed_id
 GROUP BY f.id
@@ -147,6 +241,27 @@ type ListFeedsWithItemsCountRow struct {
 	UpdatedAt           sql.NullTime   `json:"updated_at"`
 	LastRefreshedAt     sql.NullTime   `json:"last_refreshed_at"`
 	DateSelector        sql.NullString `json:"date_selector"`
+	Mode                string         `json:"mode"`
+	LastSnapshot        sql.NullString `json:"last_snapshot"`
+	RetentionMaxItems   sql.NullInt64  `json:"retention_max_items"`
+	RetentionMaxAgeDays sql.NullInt64  `json:"retention_max_age_days"`
+	DescriptionFormat   string         `json:"description_format"`
+	RemoveSelectors     sql.NullString `json:"remove_selectors"`
+	LastItemCount       sql.NullInt64  `json:"last_item_count"`
+	BrokenReason        sql.NullString `json:"broken_reason"`
+	BrokenSince         sql.NullTime   `json:"broken_since"`
+	ExtraUrls           sql.NullString `json:"extra_urls"`
+	SourceType          string         `json:"source_type"`
+	FilterExpression    sql.NullString `json:"filter_expression"`
+	MapExpression       sql.NullString `json:"map_expression"`
+	GlobalPattern       sql.NullString `json:"global_pattern"`
+	ItemPattern         sql.NullString `json:"item_pattern"`
+	TitleTemplate       sql.NullString `json:"title_template"`
+	LinkTemplate        sql.NullString `json:"link_template"`
+	DescriptionTemplate sql.NullString `json:"description_template"`
+	DateTemplate        sql.NullString `json:"date_template"`
+	Language            sql.NullString `json:"language"`
+	Description         sql.NullString `json:"description"`
 	ItemsCount          int64          `json:"items_count"`
 }
 
@@ -171,6 +286,27 @@ func (q *Queries) ListFeedsWithItemsCount(ctx context.Context) ([]ListFeedsWithI
 			&i.UpdatedAt,
 			&i.LastRefreshedAt,
 			&i.DateSelector,
+			&i.Mode,
+			&i.LastSnapshot,
+			&i.RetentionMaxItems,
+			&i.RetentionMaxAgeDays,
+			&i.DescriptionFormat,
+			&i.RemoveSelectors,
+			&i.LastItemCount,
+			&i.BrokenReason,
+			&i.BrokenSince,
+			&i.ExtraUrls,
+			&i.SourceType,
+			&i.FilterExpression,
+			&i.MapExpression,
+			&i.GlobalPattern,
+			&i.ItemPattern,
+			&i.TitleTemplate,
+			&i.LinkTemplate,
+			&i.DescriptionTemplate,
+			&i.DateTemplate,
+			&i.Language,
+			&i.Description,
 			&i.ItemsCount,
 		); err != nil {
 			return nil, err
@@ -188,18 +324,53 @@ func (q *Queries) ListFeedsWithItemsCount(ctx context.Context) ([]ListFeedsWithI
 
 const updateFeed = `-- name: UpdateFeed :exec
 UPDATE feeds
-SET name = ?, url = ?, item_selector = ?, title_selector = ?, link_selector = ?, description_selector = ?, date_selector = ?, updated_at = CURRENT_TIMESTAMP
-WHERE id = ?
+SET name = ?1, url = ?2, extra_urls = ?3, source_type = ?4,
+    item_selector = ?5, title_selector = ?6, link_selector = ?7,
+    description_selector = ?8, date_selector = ?9, mode = ?10,
+    description_format = ?11, remove_selectors = ?12,
+    filter_expression = ?13, map_expression = ?14,
+    global_pattern = ?15, item_pattern = ?16, title_template = ?17,
+    link_template = ?18, description_template = ?19, date_template = ?20,
+    language = ?21, description = ?22,
+    -- A different monitored region starts from a new snapshot
+    last_snapshot = CASE WHEN (url, mode, item_selector) IS (?2, ?10, ?5) THEN last_snapshot END,
+    -- New extraction settings start a new baseline for breakage detection
+    (last_item_count, broken_reason, broken_since) = (
+        SELECT last_item_count, broken_reason, broken_since
+        WHERE (url, extra_urls, source_type, item_selector, title_selector, link_selector, description_selector, date_selector, mode,
+               global_pattern, item_pattern, title_template, link_template, description_template, date_template)
+           IS (?2, ?3, ?4, ?5, ?6,
+               ?7, ?8, ?9, ?10,
+               ?15, ?16, ?17, ?18,
+               ?19, ?20)
+    ),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?23
 `
 
 type UpdateFeedParams struct {
 	Name                string         `json:"name"`
 	Url                 string         `json:"url"`
+	ExtraUrls           sql.NullString `json:"extra_urls"`
+	SourceType          string         `json:"source_type"`
 	ItemSelector        sql.NullString `json:"item_selector"`
 	TitleSelector       sql.NullString `json:"title_selector"`
 	LinkSelector        sql.NullString `json:"link_selector"`
 	DescriptionSelector sql.NullString `json:"description_selector"`
 	DateSelector        sql.NullString `json:"date_selector"`
+	Mode                string         `json:"mode"`
+	DescriptionFormat   string         `json:"description_format"`
+	RemoveSelectors     sql.NullString `json:"remove_selectors"`
+	FilterExpression    sql.NullString `json:"filter_expression"`
+	MapExpression       sql.NullString `json:"map_expression"`
+	GlobalPattern       sql.NullString `json:"global_pattern"`
+	ItemPattern         sql.NullString `json:"item_pattern"`
+	TitleTemplate       sql.NullString `json:"title_template"`
+	LinkTemplate        sql.NullString `json:"link_template"`
+	DescriptionTemplate sql.NullString `json:"description_template"`
+	DateTemplate        sql.NullString `json:"date_template"`
+	Language            sql.NullString `json:"language"`
+	Description         sql.NullString `json:"description"`
 	ID                  int64          `json:"id"`
 }
 
@@ -207,11 +378,49 @@ func (q *Queries) UpdateFeed(ctx context.Context, arg UpdateFeedParams) error {
 	_, err := q.db.ExecContext(ctx, updateFeed,
 		arg.Name,
 		arg.Url,
+		arg.ExtraUrls,
+		arg.SourceType,
 		arg.ItemSelector,
 		arg.TitleSelector,
 		arg.LinkSelector,
 		arg.DescriptionSelector,
 		arg.DateSelector,
+		arg.Mode,
+		arg.DescriptionFormat,
+		arg.RemoveSelectors,
+		arg.FilterExpression,
+		arg.MapExpression,
+		arg.GlobalPattern,
+		arg.ItemPattern,
+		arg.TitleTemplate,
+		arg.LinkTemplate,
+		arg.DescriptionTemplate,
+		arg.DateTemplate,
+		arg.Language,
+		arg.Description,
+		arg.ID,
+	)
+	return err
+}
+
+const updateFeedHealth = `-- name: UpdateFeedHealth :exec
+UPDATE feeds
+SET last_item_count = ?, broken_reason = ?, broken_since = ?
+WHERE id = ?
+`
+
+type UpdateFeedHealthParams struct {
+	LastItemCount sql.NullInt64  `json:"last_item_count"`
+	BrokenReason  sql.NullString `json:"broken_reason"`
+	BrokenSince   sql.NullTime   `json:"broken_since"`
+	ID            int64          `json:"id"`
+}
+
+func (q *Queries) UpdateFeedHealth(ctx context.Context, arg UpdateFeedHealthParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedHealth,
+		arg.LastItemCount,
+		arg.BrokenReason,
+		arg.BrokenSince,
 		arg.ID,
 	)
 	return err
@@ -232,3 +441,36 @@ func (q *Queries) UpdateFeedLastRefreshedAt(ctx context.Context, arg UpdateFeedL
 	_, err := q.db.ExecContext(ctx, updateFeedLastRefreshedAt, arg.LastRefreshedAt, arg.ID)
 	return err
 }
+
+const updateFeedRetention = `-- name: UpdateFeedRetention :exec
+UPDATE feeds
+SET retention_max_items = ?, retention_max_age_days = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateFeedRetentionParams struct {
+	RetentionMaxItems   sql.NullInt64 `json:"retention_max_items"`
+	RetentionMaxAgeDays sql.NullInt64 `json:"retention_max_age_days"`
+	ID                  int64         `json:"id"`
+}
+
+func (q *Queries) UpdateFeedRetention(ctx context.Context, arg UpdateFeedRetentionParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedRetention, arg.RetentionMaxItems, arg.RetentionMaxAgeDays, arg.ID)
+	return err
+}
+
+const updateFeedSnapshot = `-- name: UpdateFeedSnapshot :exec
+UPDATE feeds
+SET last_snapshot = ?
+WHERE id = ?
+`
+
+type UpdateFeedSnapshotParams struct {
+	LastSnapshot sql.NullString `json:"last_snapshot"`
+	ID           int64          `json:"id"`
+}
+
+func (q *Queries) UpdateFeedSnapshot(ctx context.Context, arg UpdateFeedSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedSnapshot, arg.LastSnapshot, arg.ID)
+	return err
+}
@@ -8,6 +8,18 @@ import (
 	"database/sql"
 )
 
+type Collection struct {
+	ID        int64        `json:"id"`
+	Name      string       `json:"name"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+type CollectionFeed struct {
+	CollectionID int64 `json:"collection_id"`
+	FeedID       int64 `json:"feed_id"`
+}
+
 type Feed struct {
 	ID                  int64          `json:"id"`
 	Name                string         `json:"name"`
@@ -20,6 +32,50 @@ type Feed struct {
 	UpdatedAt           sql.NullTime   `json:"updated_at"`
 	LastRefreshedAt     sql.NullTime   `json:"last_refreshed_at"`
 	DateSelector        sql.NullString `json:"date_selector"`
+	Mode                string         `json:"mode"`
+	LastSnapshot        sql.NullString `json:"last_snapshot"`
+	RetentionMaxItems   sql.NullInt64  `json:"retention_max_items"`
+	RetentionMaxAgeDays sql.NullInt64  `json:"retention_max_age_days"`
+	DescriptionFormat   string         `json:"description_format"`
+	RemoveSelectors     sql.NullString `json:"remove_selectors"`
+	LastItemCount       sql.NullInt64  `json:"last_item_count"`
+	BrokenReason        sql.NullString `json:"broken_reason"`
+	BrokenSince         sql.NullTime   `json:"broken_since"`
+	ExtraUrls           sql.NullString `json:"extra_urls"`
+	SourceType          string         `json:"source_type"`
+	FilterExpression    sql.NullString `json:"filter_expression"`
+	MapExpression       sql.NullString `json:"map_expression"`
+	GlobalPattern       sql.NullString `json:"global_pattern"`
+	ItemPattern         sql.NullString `json:"item_pattern"`
+	TitleTemplate       sql.NullString `json:"title_template"`
+	LinkTemplate        sql.NullString `json:"link_template"`
+	DescriptionTemplate sql.NullString `json:"description_template"`
+	DateTemplate        sql.NullString `json:"date_template"`
+	Language            sql.NullString `json:"language"`
+	Description         sql.NullString `json:"description"`
+}
+
+type FeedAuth struct {
+	FeedID      int64          `json:"feed_id"`
+	Type        string         `json:"type"`
+	Username    string         `json:"username"`
+	Password    string         `json:"password"`
+	Token       string         `json:"token"`
+	LoginUrl    string         `json:"login_url"`
+	LoginFields string         `json:"login_fields"`
+	LoginCheck  string         `json:"login_check"`
+	Cookies     sql.NullString `json:"cookies"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+}
+
+type FeedFilterRule struct {
+	ID        int64        `json:"id"`
+	FeedID    int64        `json:"feed_id"`
+	Field     string       `json:"field"`
+	Operator  string       `json:"operator"`
+	Value     string       `json:"value"`
+	Position  int64        `json:"position"`
+	CreatedAt sql.NullTime `json:"created_at"`
 }
 
 type FeedItem struct {
@@ -32,3 +88,49 @@ type FeedItem struct {
 	UpdatedAt   sql.NullTime   `json:"updated_at"`
 	Date        sql.NullTime   `json:"date"`
 }
+
+type FeedItemRevision struct {
+	ID          int64          `json:"id"`
+	FeedItemID  int64          `json:"feed_item_id"`
+	Title       string         `json:"title"`
+	Description sql.NullString `json:"description"`
+	Date        sql.NullTime   `json:"date"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+}
+
+type FeedItemTombstone struct {
+	FeedID   int64        `json:"feed_id"`
+	Link     string       `json:"link"`
+	PrunedAt sql.NullTime `json:"pruned_at"`
+}
+
+type FeedTag struct {
+	FeedID int64 `json:"feed_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
+type FeedView struct {
+	ID        int64          `json:"id"`
+	FeedID    int64          `json:"feed_id"`
+	Name      string         `json:"name"`
+	Title     sql.NullString `json:"title"`
+	MaxItems  sql.NullInt64  `json:"max_items"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+	UpdatedAt sql.NullTime   `json:"updated_at"`
+}
+
+type FeedViewFilterRule struct {
+	ID        int64        `json:"id"`
+	ViewID    int64        `json:"view_id"`
+	Field     string       `json:"field"`
+	Operator  string       `json:"operator"`
+	Value     string       `json:"value"`
+	Position  int64        `json:"position"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type Tag struct {
+	ID        int64        `json:"id"`
+	Name      string       `json:"name"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
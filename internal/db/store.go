@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Store is the queries of a database, along with transactions for the
+// writes that must happen together
+type Store struct {
+	*Queries
+	db *sql.DB
+}
+
+// NewStore creates a store for a database
+func NewStore(database *sql.DB) *Store {
+	return &Store{Queries: New(database), db: database}
+}
+
+// InTx runs fn with queries bound to a transaction, committing it when fn
+// succeeds and rolling it back otherwise
+func (s *Store) InTx(ctx context.Context, fn func(q *Queries) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(s.WithTx(tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: tags.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const addFeedTag = `-- name: AddFeedTag :exec
+INSERT INTO feed_tags (feed_id, tag_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING
+`
+
+type AddFeedTagParams struct {
+	FeedID int64 `json:"feed_id"`
+	TagID  int64 `json:"tag_id"`
+}
+
+func (q *Queries) AddFeedTag(ctx context.Context, arg AddFeedTagParams) error {
+	_, err := q.db.ExecContext(ctx, addFeedTag, arg.FeedID, arg.TagID)
+	return err
+}
+
+const deleteFeedTags = `-- name: DeleteFeedTags :exec
+DELETE FROM feed_tags
+WHERE feed_id = ?
+`
+
+func (q *Queries) DeleteFeedTags(ctx context.Context, feedID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedTags, feedID)
+	return err
+}
+
+const deleteUnusedTags = `-- name: DeleteUnusedTags :exec
+DELETE FROM tags
+WHERE id NOT IN (SELECT tag_id FROM feed_tags)
+`
+
+func (q *Queries) DeleteUnusedTags(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteUnusedTags)
+	return err
+}
+
+const getTagByName = `-- name: GetTagByName :one
+SELECT id, name, created_at FROM tags
+WHERE name = ? LIMIT 1
+`
+
+func (q *Queries) GetTagByName(ctx context.Context, name string) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTagByName, name)
+	var i Tag
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
+
+const listFeedTagNames = `-- name: ListFeedTagNames :many
+SELECT ft.feed_id, t.name
+FROM feed_tags ft
+JOIN tags t ON t.id = ft.tag_id
+ORDER BY t.name
+`
+
+type ListFeedTagNamesRow struct {
+	FeedID int64  `json:"feed_id"`
+	Name   string `json:"name"`
+}
+
+func (q *Queries) ListFeedTagNames(ctx context.Context) ([]ListFeedTagNamesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedTagNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFeedTagNamesRow
+	for rows.Next() {
+		var i ListFeedTagNamesRow
+		if err := rows.Scan(&i.FeedID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagFeeds = `-- name: ListTagFeeds :many
+SELECT f.id, f.name, f.url, f.item_selector, f.title_selector, f.link_selector, f.description_selector, f.created_at, f.updated_at, f.last_refreshed_at, f.date_selector, f.mode, f.last_snapshot, f.retention_max_items, f.retention_max_age_days, f.description_format, f.remove_selectors, f.last_item_count, f.broken_reason, f.broken_since, f.extra_urls, f.source_type, f.filter_expression, f.map_expression, f.global_pattern, f.item_pattern, f.title_template, f.link_template, f.description_template, f.date_template, f.language, f.description FROM feeds f
+JOIN feed_tags ft ON f.id = ft.feed_id
+WHERE ft.tag_id = ?
+ORDER BY f.name, f.id
+`
+
+func (q *Queries) ListTagFeeds(ctx context.Context, tagID int64) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listTagFeeds, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.ItemSelector,
+			&i.TitleSelector,
+			&i.LinkSelector,
+			&i.DescriptionSelector,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastRefreshedAt,
+			&i.DateSelector,
+			&i.Mode,
+			&i.LastSnapshot,
+			&i.RetentionMaxItems,
+			&i.RetentionMaxAgeDays,
+			&i.DescriptionFormat,
+			&i.RemoveSelectors,
+			&i.LastItemCount,
+			&i.BrokenReason,
+			&i.BrokenSince,
+			&i.ExtraUrls,
+			&i.SourceType,
+			&i.FilterExpression,
+			&i.MapExpression,
+			&i.GlobalPattern,
+			&i.ItemPattern,
+			&i.TitleTemplate,
+			&i.LinkTemplate,
+			&i.DescriptionTemplate,
+			&i.DateTemplate,
+			&i.Language,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagItems = `-- name: ListTagItems :many
+SELECT i.id, i.feed_id, i.title, i.description, i.link, i.created_at, i.updated_at, i.date, f.name AS feed_name
+FROM feed_items i
+JOIN feeds f ON f.id = i.feed_id
+JOIN feed_tags ft ON ft.feed_id = i.feed_id
+WHERE ft.tag_id = ?1
+ORDER BY COALESCE(i.date, i.created_at) DESC, i.id DESC
+LIMIT ?2
+`
+
+type ListTagItemsParams struct {
+	TagID    int64 `json:"tag_id"`
+	MaxItems int64 `json:"max_items"`
+}
+
+type ListTagItemsRow struct {
+	FeedItem FeedItem `json:"feed_item"`
+	FeedName string   `json:"feed_name"`
+}
+
+func (q *Queries) ListTagItems(ctx context.Context, arg ListTagItemsParams) ([]ListTagItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagItems, arg.TagID, arg.MaxItems)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTagItemsRow
+	for rows.Next() {
+		var i ListTagItemsRow
+		if err := rows.Scan(
+			&i.FeedItem.ID,
+			&i.FeedItem.FeedID,
+			&i.FeedItem.Title,
+			&i.FeedItem.Description,
+			&i.FeedItem.Link,
+			&i.FeedItem.CreatedAt,
+			&i.FeedItem.UpdatedAt,
+			&i.FeedItem.Date,
+			&i.FeedName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagNamesByFeed = `-- name: ListTagNamesByFeed :many
+SELECT t.name
+FROM tags t
+JOIN feed_tags ft ON t.id = ft.tag_id
+WHERE ft.feed_id = ?
+ORDER BY t.name
+`
+
+func (q *Queries) ListTagNamesByFeed(ctx context.Context, feedID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listTagNamesByFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsWithFeedsCount = `-- name: ListTagsWithFeedsCount :many
+SELECT t.id, t.name, t.created_at, COUNT(ft.feed_id) AS feeds_count
+FROM tags t
+JOIN feed_tags ft ON t.id = ft.tag_id
+GROUP BY t.id
+ORDER BY t.name
+`
+
+type ListTagsWithFeedsCountRow struct {
+	ID         int64        `json:"id"`
+	Name       string       `json:"name"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+	FeedsCount int64        `json:"feeds_count"`
+}
+
+func (q *Queries) ListTagsWithFeedsCount(ctx context.Context) ([]ListTagsWithFeedsCountRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsWithFeedsCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTagsWithFeedsCountRow
+	for rows.Next() {
+		var i ListTagsWithFeedsCountRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.FeedsCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTag = `-- name: UpsertTag :one
+INSERT INTO tags (name)
+VALUES (?)
+ON CONFLICT(name) DO UPDATE SET name = tags.name
+RETURNING id, name, created_at
+`
+
+func (q *Queries) UpsertTag(ctx context.Context, name string) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, upsertTag, name)
+	var i Tag
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
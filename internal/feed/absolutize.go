@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// urlAttributes are the attributes holding a single URL
+var urlAttributes = []string{"href", "src", "poster"}
+
+// lazyAttributes maps the attributes lazy-loading scripts read the real URL
+// from to the attribute the browser would load it from
+var lazyAttributes = []struct {
+	from, to string
+}{
+	{"data-src", "src"},
+	{"data-lazy-src", "src"},
+	{"data-original", "src"},
+	{"data-srcset", "srcset"},
+	{"data-lazy-srcset", "srcset"},
+}
+
+// AbsolutizeURLs rewrites the elements of a selection and their descendants
+// so that lazy-loaded images use their real source and every URL attribute
+// is resolved against base, as feed readers have no page to resolve them from
+func AbsolutizeURLs(sel *goquery.Selection, base *url.URL) {
+	elements := sel.Find("*").AddSelection(sel)
+
+	for _, lazy := range lazyAttributes {
+		elements.Filter("[" + lazy.from + "]").Each(func(i int, el *goquery.Selection) {
+			value := strings.TrimSpace(el.AttrOr(lazy.from, ""))
+			if value == "" {
+				return
+			}
+			el.SetAttr(lazy.to, value)
+			el.RemoveAttr(lazy.from)
+		})
+	}
+
+	for _, attr := range urlAttributes {
+		elements.Filter("[" + attr + "]").Each(func(i int, el *goquery.Selection) {
+			el.SetAttr(attr, resolveURL(base, el.AttrOr(attr, "")))
+		})
+	}
+
+	elements.Filter("[srcset]").Each(func(i int, el *goquery.Selection) {
+		el.SetAttr("srcset", resolveSrcset(base, el.AttrOr("srcset", "")))
+	})
+}
+
+// resolveURL resolves ref against base, leaving it untouched if it does not parse
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ref
+	}
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(parsed).String()
+}
+
+// resolveSrcset resolves every candidate URL of a srcset, keeping descriptors
+func resolveSrcset(base *url.URL, srcset string) string {
+	candidates := strings.Split(srcset, ",")
+	resolved := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = resolveURL(base, fields[0])
+		resolved = append(resolved, strings.Join(fields, " "))
+	}
+	return strings.Join(resolved, ", ")
+}
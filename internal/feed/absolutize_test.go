@@ -0,0 +1,65 @@
+package feed
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbsolutizeURLs(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post.html")
+
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{
+			name:     "relative link",
+			fragment: `<a href="other.html">Other</a>`,
+			want:     `<a href="https://example.com/blog/other.html">Other</a>`,
+		},
+		{
+			name:     "root-relative image",
+			fragment: `<img src="/img/a.png"/>`,
+			want:     `<img src="https://example.com/img/a.png"/>`,
+		},
+		{
+			name:     "absolute URLs are kept",
+			fragment: `<a href="https://other.example.com/x">X</a>`,
+			want:     `<a href="https://other.example.com/x">X</a>`,
+		},
+		{
+			name:     "srcset candidates",
+			fragment: `<img srcset="a.png 1x, /b.png 2x"/>`,
+			want:     `<img srcset="https://example.com/blog/a.png 1x, https://example.com/b.png 2x"/>`,
+		},
+		{
+			name:     "lazy-loaded image",
+			fragment: `<img src="data:image/gif;base64,R0lGOD" data-src="/img/real.png"/>`,
+			want:     `<img src="https://example.com/img/real.png"/>`,
+		},
+		{
+			name:     "lazy-loaded srcset",
+			fragment: `<img data-lazy-src="a.png" data-srcset="a.png 1x, a@2x.png 2x"/>`,
+			want:     `<img src="https://example.com/blog/a.png" srcset="https://example.com/blog/a.png 1x, https://example.com/blog/a@2x.png 2x"/>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<div class="item">` + tt.fragment + `</div>`))
+			assert.NoError(t, err)
+
+			sel := doc.Find(".item")
+			AbsolutizeURLs(sel, base)
+
+			got, err := sel.Html()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
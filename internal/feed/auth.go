@@ -0,0 +1,435 @@
+package feed
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// Authentication types
+const (
+	// AuthNone fetches pages anonymously
+	AuthNone = "none"
+	// AuthBasic sends HTTP basic auth credentials with every request
+	AuthBasic = "basic"
+	// AuthBearer sends an Authorization: Bearer token with every request
+	AuthBearer = "bearer"
+	// AuthForm submits a login form and keeps the session cookies
+	AuthForm = "form"
+)
+
+// ErrLoginFailed is returned when a form login is rejected by the site
+var ErrLoginFailed = errors.New("login failed")
+
+// NormalizeAuthType returns a known authentication type, defaulting to AuthNone
+func NormalizeAuthType(authType string) string {
+	switch authType {
+	case AuthBasic, AuthBearer, AuthForm:
+		return authType
+	}
+	return AuthNone
+}
+
+// Auth is how a feed's source is logged into
+type Auth struct {
+	Type     string
+	Username string
+	Password string
+	Token    string
+	// LoginURL is the page holding the login form
+	LoginURL string
+	// LoginFields holds one "name=value" form field per line
+	LoginFields string
+	// LoginCheck is text the page returned after logging in must contain
+	LoginCheck string
+}
+
+// AuthFromRow converts stored authentication settings
+func AuthFromRow(row db.FeedAuth) Auth {
+	return Auth{
+		Type:        NormalizeAuthType(row.Type),
+		Username:    row.Username,
+		Password:    row.Password,
+		Token:       row.Token,
+		LoginURL:    row.LoginUrl,
+		LoginFields: row.LoginFields,
+		LoginCheck:  row.LoginCheck,
+	}
+}
+
+// Validate checks that the settings needed by the authentication type are present
+func (a Auth) Validate() error {
+	switch a.Type {
+	case AuthBasic:
+		if a.Username == "" {
+			return errors.New("basic auth needs a username")
+		}
+	case AuthBearer:
+		if a.Token == "" {
+			return errors.New("bearer auth needs a token")
+		}
+	case AuthForm:
+		if _, err := url.ParseRequestURI(a.LoginURL); err != nil {
+			return fmt.Errorf("invalid login URL %q", a.LoginURL)
+		}
+		fields, err := ParseLoginFields(a.LoginFields)
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return errors.New("form login needs at least one field")
+		}
+	}
+	return nil
+}
+
+// ParseLoginFields reads one "name=value" form field per line, ignoring blank lines
+func ParseLoginFields(text string) (url.Values, error) {
+	fields := url.Values{}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("login field line %d: expected \"name=value\"", i+1)
+		}
+		fields.Set(strings.TrimSpace(name), value)
+	}
+	return fields, nil
+}
+
+// Session fetches pages with a feed's authentication, logging in through the
+// login form whenever there is no session or the site dropped it
+type Session struct {
+	auth     Auth
+	client   *http.Client
+	jar      *sessionJar
+	loggedIn bool
+}
+
+// NewSession creates a session, restoring the unexpired cookies a previous
+// session received, each to the URLs it was set for
+func NewSession(auth Auth, cookies []SessionCookie) *Session {
+	jar := newSessionJar()
+	now := time.Now()
+	for _, c := range cookies {
+		if c.expired(now) || c.Host == "" {
+			continue
+		}
+		jar.SetCookies(c.url(), []*http.Cookie{c.cookie()})
+	}
+
+	return &Session{
+		auth:     auth,
+		client:   &http.Client{Jar: jar},
+		jar:      jar,
+		loggedIn: len(jar.cookies) > 0,
+	}
+}
+
+// Get fetches a page, logging in first or again if needed
+func (s *Session) Get(ctx context.Context, rawURL string) (*http.Response, error) {
+	if s.auth.Type == AuthForm && !s.loggedIn {
+		if err := s.login(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := s.get(ctx, rawURL)
+	if err != nil || s.auth.Type != AuthForm || !s.sessionExpired(resp) {
+		return resp, err
+	}
+
+	// The stored session is no longer valid: log in again and retry once
+	_ = resp.Body.Close()
+	if err := s.login(ctx); err != nil {
+		return nil, err
+	}
+	return s.get(ctx, rawURL)
+}
+
+// Cookies returns the unexpired cookies the session holds, for any URL
+func (s *Session) Cookies() []SessionCookie {
+	return s.jar.list(time.Now())
+}
+
+func (s *Session) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	switch s.auth.Type {
+	case AuthBasic:
+		req.SetBasicAuth(s.auth.Username, s.auth.Password)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+s.auth.Token)
+	}
+
+	return s.client.Do(req)
+}
+
+// sessionExpired reports whether a response shows the session is gone: the
+// site refused access or sent us back to its login page
+func (s *Session) sessionExpired(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return true
+	}
+
+	loginURL, err := url.Parse(s.auth.LoginURL)
+	if err != nil {
+		return false
+	}
+	final := resp.Request.URL
+	return final.Host == loginURL.Host && final.Path == loginURL.Path
+}
+
+// login loads the login page and submits its form with the configured fields,
+// keeping hidden inputs such as CSRF tokens
+func (s *Session) login(ctx context.Context) error {
+	fields, err := ParseLoginFields(s.auth.LoginFields)
+	if err != nil {
+		return err
+	}
+
+	page, err := s.get(ctx, s.auth.LoginURL)
+	if err != nil {
+		return fmt.Errorf("failed to load login page: %w", err)
+	}
+	body, err := io.ReadAll(page.Body)
+	_ = page.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read login page: %w", err)
+	}
+
+	action, values := loginForm(page.Request.URL, body, fields)
+	for name := range fields {
+		values.Set(name, fields.Get(name))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, action, strings.NewReader(values.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create login request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to submit login form: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%w: status %d", ErrLoginFailed, resp.StatusCode)
+	}
+
+	if s.auth.LoginCheck != "" {
+		result, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read login response: %w", err)
+		}
+		if !bytes.Contains(result, []byte(s.auth.LoginCheck)) {
+			return fmt.Errorf("%w: response does not contain %q", ErrLoginFailed, s.auth.LoginCheck)
+		}
+	}
+
+	s.loggedIn = true
+	return nil
+}
+
+// loginForm finds the form to submit on the login page, preferring the one
+// holding the configured fields, and returns where it posts to along with
+// its hidden inputs
+func loginForm(pageURL *url.URL, body []byte, fields url.Values) (string, url.Values) {
+	values := url.Values{}
+	action := pageURL.String()
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return action, values
+	}
+
+	forms := doc.Find("form")
+	form := forms.First()
+	forms.EachWithBreak(func(i int, f *goquery.Selection) bool {
+		for name := range fields {
+			if f.Find(fmt.Sprintf("[name=%q]", name)).Length() > 0 {
+				form = f
+				return false
+			}
+		}
+		return true
+	})
+	if form.Length() == 0 {
+		return action, values
+	}
+
+	if formAction, ok := form.Attr("action"); ok && formAction != "" {
+		action = resolveURL(pageURL, formAction)
+	}
+	form.Find(`input[type="hidden"][name]`).Each(func(i int, input *goquery.Selection) {
+		values.Set(input.AttrOr("name", ""), input.AttrOr("value", ""))
+	})
+
+	return action, values
+}
+
+// SessionCookie is a session cookie as it is kept in the database, with the
+// attributes needed to send it again to the URLs it was set for
+type SessionCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Host is the host the cookie was received from
+	Host string `json:"host"`
+	// Domain is set for cookies the site shares with its subdomains
+	Domain   string    `json:"domain,omitempty"`
+	Path     string    `json:"path"`
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"http_only,omitempty"`
+	Expires  time.Time `json:"expires,omitzero"`
+}
+
+// expired reports whether the cookie is no longer valid. Cookies without an
+// expiry last as long as the site keeps accepting them.
+func (c SessionCookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// url returns a URL the cookie applies to, for restoring it to a cookie jar
+func (c SessionCookie) url() *url.URL {
+	u := &url.URL{Scheme: "http", Host: c.Host, Path: cmp.Or(c.Path, "/")}
+	if c.Secure {
+		u.Scheme = "https"
+	}
+	return u
+}
+
+// cookie returns the cookie as a site would set it
+func (c SessionCookie) cookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		Expires:  c.Expires,
+	}
+}
+
+// sessionJar is a cookie jar that also remembers the attributes of the
+// cookies it accepts, which a cookiejar.Jar does not give back
+type sessionJar struct {
+	*cookiejar.Jar
+	cookies map[string]SessionCookie
+}
+
+func newSessionJar() *sessionJar {
+	// cookiejar.New never fails without options
+	jar, _ := cookiejar.New(nil)
+	return &sessionJar{Jar: jar, cookies: map[string]SessionCookie{}}
+}
+
+// SetCookies stores the cookies a response to u sets, following the rules
+// of cookiejar.Jar for their domain, path and expiry
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.Jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	host := strings.ToLower(u.Hostname())
+	for _, c := range cookies {
+		stored := SessionCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Host:     host,
+			Domain:   strings.TrimPrefix(strings.ToLower(c.Domain), "."),
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			Expires:  c.Expires,
+		}
+		if stored.Domain != "" && stored.Domain != host && !strings.HasSuffix(host, "."+stored.Domain) {
+			// The jar rejects cookies for other sites
+			continue
+		}
+		if stored.Path == "" || !strings.HasPrefix(stored.Path, "/") {
+			stored.Path = defaultCookiePath(u.Path)
+		}
+		if c.MaxAge > 0 {
+			stored.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+
+		key := strings.Join([]string{cmp.Or(stored.Domain, host), stored.Path, stored.Name}, ";")
+		if c.MaxAge < 0 || stored.expired(now) {
+			delete(j.cookies, key)
+			continue
+		}
+		j.cookies[key] = stored
+	}
+}
+
+// list returns the unexpired cookies of the jar in a stable order
+func (j *sessionJar) list(now time.Time) []SessionCookie {
+	cookies := make([]SessionCookie, 0, len(j.cookies))
+	for _, c := range j.cookies {
+		if !c.expired(now) {
+			cookies = append(cookies, c)
+		}
+	}
+	slices.SortFunc(cookies, func(a, b SessionCookie) int {
+		return cmp.Or(
+			cmp.Compare(a.Host, b.Host),
+			cmp.Compare(a.Domain, b.Domain),
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return cookies
+}
+
+// defaultCookiePath is the path a cookie set without one applies to: the
+// directory of the request path (RFC 6265 section 5.1.4)
+func defaultCookiePath(requestPath string) string {
+	i := strings.LastIndex(requestPath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return requestPath[:i]
+}
+
+// EncodeCookies serializes session cookies for storage
+func EncodeCookies(cookies []SessionCookie) (string, error) {
+	encoded, err := json.Marshal(cookies)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cookies: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// DecodeCookies reads session cookies serialized by EncodeCookies. Cookies
+// stored without their host cannot be sent back and are dropped by NewSession.
+func DecodeCookies(encoded string) ([]SessionCookie, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	var cookies []SessionCookie
+	if err := json.Unmarshal([]byte(encoded), &cookies); err != nil {
+		return nil, fmt.Errorf("failed to decode cookies: %w", err)
+	}
+	return cookies, nil
+}
@@ -0,0 +1,260 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionSendsCredentials(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		_, _ = fmt.Fprintf(w, "%s:%s %s", user, pass, r.Header.Get("Authorization"))
+	}))
+	defer ts.Close()
+
+	get := func(auth Auth) string {
+		resp, err := NewSession(auth, nil).Get(context.Background(), ts.URL)
+		assert.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
+
+	assert.Equal(t, ": Bearer t0ken", get(Auth{Type: AuthBearer, Token: "t0ken"}))
+	assert.Equal(t, "me:secret Basic bWU6c2VjcmV0", get(Auth{Type: AuthBasic, Username: "me", Password: "secret"}))
+	assert.Equal(t, ": ", get(Auth{Type: AuthNone}))
+}
+
+// loginServer serves a page only to clients holding the current session,
+// sending the others to a login form protected by a CSRF token
+type loginServer struct {
+	session string
+	logins  int
+}
+
+func (s *loginServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/login":
+		if r.Method == http.MethodGet {
+			_, _ = fmt.Fprint(w, `<form id="search" action="/search"><input name="q"></form>
+				<form action="/session" method="post">
+					<input type="hidden" name="csrf" value="c5rf">
+					<input name="user"><input name="pass" type="password">
+				</form>`)
+			return
+		}
+	case "/session":
+		if r.FormValue("csrf") != "c5rf" || r.FormValue("user") != "me" || r.FormValue("pass") != "secret" {
+			http.Error(w, "Wrong credentials", http.StatusUnauthorized)
+			return
+		}
+		s.logins++
+		s.session = fmt.Sprintf("session-%d", s.logins)
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: s.session, Path: "/"})
+		_, _ = fmt.Fprint(w, "Welcome back")
+		return
+	case "/private":
+		if c, err := r.Cookie("sid"); err != nil || c.Value != s.session {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<div class="item"><h2 class="title">Secret</h2><a class="link" href="/secret">Link</a></div>`)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func TestRefreshFeedWithFormLogin(t *testing.T) {
+	site := &loginServer{}
+	ts := httptest.NewServer(site)
+	defer ts.Close()
+
+	stored := db.FeedAuth{
+		FeedID:      1,
+		Type:        AuthForm,
+		LoginUrl:    ts.URL + "/login",
+		LoginFields: "user=me\npass=secret",
+		LoginCheck:  "Welcome",
+	}
+
+	var upserted []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		GetFeedAuthFn: func(ctx context.Context, feedID int64) (db.FeedAuth, error) {
+			return stored, nil
+		},
+		UpdateFeedAuthCookiesFn: func(ctx context.Context, arg db.UpdateFeedAuthCookiesParams) error {
+			stored.Cookies = arg.Cookies
+			return nil
+		},
+		UpsertFeedItemFn: func(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error) {
+			upserted = append(upserted, arg)
+			return []int64{1}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+	feed := db.Feed{
+		ID:            1,
+		Url:           ts.URL + "/private",
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+	}
+
+	// First refresh logs in and keeps the session
+	assert.NoError(t, svc.RefreshFeed(context.Background(), feed))
+	assert.Equal(t, 1, site.logins)
+	assert.Len(t, upserted, 1)
+	assert.Equal(t, "Secret", upserted[0].Title)
+	assert.Contains(t, stored.Cookies.String, "session-1")
+
+	// The stored session is reused
+	assert.NoError(t, svc.RefreshFeed(context.Background(), feed))
+	assert.Equal(t, 1, site.logins)
+
+	// The site expires the session: log in again
+	site.session = "expired"
+	assert.NoError(t, svc.RefreshFeed(context.Background(), feed))
+	assert.Equal(t, 2, site.logins)
+	assert.Len(t, upserted, 3)
+	assert.Contains(t, stored.Cookies.String, "session-2")
+}
+
+func TestSessionLoginCheckFails(t *testing.T) {
+	ts := httptest.NewServer(&loginServer{})
+	defer ts.Close()
+
+	auth := Auth{
+		Type:        AuthForm,
+		LoginURL:    ts.URL + "/login",
+		LoginFields: "user=me\npass=secret",
+		LoginCheck:  "Dashboard",
+	}
+
+	_, err := NewSession(auth, nil).Get(context.Background(), ts.URL+"/private")
+	assert.ErrorIs(t, err, ErrLoginFailed)
+
+	auth.LoginFields = "user=me\npass=wrong"
+	auth.LoginCheck = ""
+	_, err = NewSession(auth, nil).Get(context.Background(), ts.URL+"/private")
+	assert.ErrorIs(t, err, ErrLoginFailed)
+}
+
+func TestAuthValidate(t *testing.T) {
+	assert.NoError(t, Auth{Type: AuthNone}.Validate())
+	assert.NoError(t, Auth{Type: AuthBasic, Username: "me"}.Validate())
+	assert.Error(t, Auth{Type: AuthBasic}.Validate())
+	assert.Error(t, Auth{Type: AuthBearer}.Validate())
+	assert.NoError(t, Auth{Type: AuthForm, LoginURL: "https://example.com/login", LoginFields: "user=me"}.Validate())
+	assert.Error(t, Auth{Type: AuthForm, LoginURL: "https://example.com/login"}.Validate())
+	assert.Error(t, Auth{Type: AuthForm, LoginURL: "not a url", LoginFields: "user=me"}.Validate())
+	assert.Error(t, Auth{Type: AuthForm, LoginURL: "https://example.com/login", LoginFields: "user"}.Validate())
+}
+
+func TestParseLoginFields(t *testing.T) {
+	fields, err := ParseLoginFields("user=me\n\npass=a=b\n")
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"user": {"me"}, "pass": {"a=b"}}, fields)
+}
+
+func TestEncodeCookies(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	encoded, err := EncodeCookies([]SessionCookie{{
+		Name:     "sid",
+		Value:    "abc",
+		Host:     "www.example.com",
+		Domain:   "example.com",
+		Path:     "/app",
+		Secure:   true,
+		HttpOnly: true,
+		Expires:  expires,
+	}})
+	assert.NoError(t, err)
+
+	cookies, err := DecodeCookies(encoded)
+	assert.NoError(t, err)
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "sid", cookies[0].Name)
+	assert.Equal(t, "abc", cookies[0].Value)
+	assert.Equal(t, "www.example.com", cookies[0].Host)
+	assert.Equal(t, "example.com", cookies[0].Domain)
+	assert.Equal(t, "/app", cookies[0].Path)
+	assert.True(t, cookies[0].Secure)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, expires.Equal(cookies[0].Expires))
+}
+
+func TestSessionKeepsCookieAttributes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc", Path: "/app", MaxAge: 3600, HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "old", Value: "x", MaxAge: -1})
+	}))
+	defer ts.Close()
+
+	session := NewSession(Auth{Type: AuthNone}, nil)
+	resp, err := session.Get(context.Background(), ts.URL+"/app/login")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+
+	cookies := session.Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "127.0.0.1", cookies[0].Host)
+	assert.Equal(t, "/app", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), cookies[0].Expires, time.Minute)
+}
+
+func TestNewSessionRestoresCookiesToTheirURLs(t *testing.T) {
+	var sent []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.URL.Path+": "+r.Header.Get("Cookie"))
+	}))
+	defer ts.Close()
+
+	session := NewSession(Auth{Type: AuthNone}, []SessionCookie{
+		{Name: "app", Value: "1", Host: "127.0.0.1", Path: "/app"},
+		{Name: "site", Value: "2", Host: "127.0.0.1", Path: "/"},
+		{Name: "other", Value: "3", Host: "example.com", Path: "/"},
+		{Name: "expired", Value: "4", Host: "127.0.0.1", Path: "/", Expires: time.Now().Add(-time.Hour)},
+		{Name: "legacy", Value: "5"},
+	})
+	for _, path := range []string{"/app/page", "/home"} {
+		resp, err := session.Get(context.Background(), ts.URL+path)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	assert.Equal(t, []string{"/app/page: app=1; site=2", "/home: site=2"}, sent)
+}
+
+func TestNewSessionWithExpiredCookiesLogsIn(t *testing.T) {
+	// The site would still accept the session, but its cookie has expired
+	site := &loginServer{session: "session-0"}
+	ts := httptest.NewServer(site)
+	defer ts.Close()
+
+	auth := Auth{
+		Type:        AuthForm,
+		LoginURL:    ts.URL + "/login",
+		LoginFields: "user=me\npass=secret",
+	}
+	session := NewSession(auth, []SessionCookie{
+		{Name: "sid", Value: "session-0", Host: "127.0.0.1", Path: "/", Expires: time.Now().Add(-time.Minute)},
+	})
+
+	resp, err := session.Get(context.Background(), ts.URL+"/private")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, 1, site.logins)
+}
@@ -0,0 +1,33 @@
+package feed
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// Diff returns a unified, line-based diff between two versions of a text.
+// It returns an empty string when the versions are identical.
+func Diff(from, to string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(ensureTrailingNewline(from)),
+		B:        difflib.SplitLines(ensureTrailingNewline(to)),
+		FromFile: "previous",
+		ToFile:   "current",
+		Context:  diffContextLines,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
+func ensureTrailingNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
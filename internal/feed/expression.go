@@ -0,0 +1,148 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+// Variables an expression can read besides the title, link and description
+// of the item
+const (
+	// ExprVarText is the description reduced to plain text
+	ExprVarText = "text"
+	// ExprVarDate is the date of the item, the zero timestamp if it has none
+	ExprVarDate = "date"
+)
+
+// maxExpressionCost bounds the work a single evaluation may do, so that an
+// expression looping over long descriptions cannot stall a refresh
+const maxExpressionCost = 100_000
+
+// expressionEnv declares the variables and functions available to
+// expressions: the standard CEL library plus the string extensions
+var expressionEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(FieldTitle, cel.StringType),
+		cel.Variable(FieldLink, cel.StringType),
+		cel.Variable(FieldDescription, cel.StringType),
+		cel.Variable(ExprVarText, cel.StringType),
+		cel.Variable(ExprVarDate, cel.TimestampType),
+		ext.Strings(),
+	)
+})
+
+// Expressions are the CEL expressions of a feed, evaluated for each
+// extracted item:
+//
+//	filter   a bool, false drops the item, e.g. title.contains("Go") && !link.endsWith(".pdf")
+//	mapping  a map overwriting fields of the item, e.g. {"title": title.trim().upperAscii()}
+//
+// A nil *Expressions keeps every item unchanged.
+type Expressions struct {
+	filter  cel.Program
+	mapping cel.Program
+}
+
+// CompileExpressions checks and prepares the filter and mapping expressions
+// of a feed. Either may be empty; it returns nil if both are.
+func CompileExpressions(filter, mapping string) (*Expressions, error) {
+	if filter == "" && mapping == "" {
+		return nil, nil
+	}
+
+	env, err := expressionEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expression environment: %w", err)
+	}
+
+	e := &Expressions{}
+	if filter != "" {
+		if e.filter, err = compileExpression(env, filter, cel.BoolType); err != nil {
+			return nil, fmt.Errorf("filter expression: %w", err)
+		}
+	}
+	if mapping != "" {
+		if e.mapping, err = compileExpression(env, mapping, cel.MapType(cel.StringType, cel.StringType)); err != nil {
+			return nil, fmt.Errorf("mapping expression: %w", err)
+		}
+	}
+	return e, nil
+}
+
+// compileExpression parses and type-checks an expression, which must return
+// the given type. Expressions of dynamic type are checked at evaluation.
+func compileExpression(env *cel.Env, src string, want *cel.Type) (cel.Program, error) {
+	ast, issues := env.Compile(src)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if out := ast.OutputType(); !out.IsExactType(cel.DynType) && !want.IsAssignableType(out) {
+		return nil, fmt.Errorf("expression returns %s, expected %s", out, want)
+	}
+
+	return env.Program(ast, cel.CostLimit(maxExpressionCost))
+}
+
+// Apply evaluates the expressions against an item. It returns whether the
+// item is kept and, if so, the item with the fields the mapping returned.
+func (e *Expressions) Apply(item ExtractedItem) (ExtractedItem, bool, error) {
+	if e == nil {
+		return item, true, nil
+	}
+
+	vars := map[string]any{
+		FieldTitle:       item.Title,
+		FieldLink:        item.Link,
+		FieldDescription: item.Description,
+		ExprVarText:      plainText(item.Description),
+		ExprVarDate:      item.Date,
+	}
+
+	if e.filter != nil {
+		out, _, err := e.filter.Eval(vars)
+		if err != nil {
+			return item, false, fmt.Errorf("filter expression: %w", err)
+		}
+		keep, ok := out.Value().(bool)
+		if !ok {
+			return item, false, fmt.Errorf("filter expression returned %s, expected bool", out.Type())
+		}
+		if !keep {
+			return item, false, nil
+		}
+	}
+
+	if e.mapping != nil {
+		out, _, err := e.mapping.Eval(vars)
+		if err != nil {
+			return item, false, fmt.Errorf("mapping expression: %w", err)
+		}
+		native, err := out.ConvertToNative(reflect.TypeFor[map[string]string]())
+		if err != nil {
+			return item, false, fmt.Errorf("mapping expression: %w", err)
+		}
+		for field, value := range native.(map[string]string) {
+			switch field {
+			case FieldTitle:
+				item.Title = value
+			case FieldLink:
+				item.Link = value
+			case FieldDescription:
+				item.Description = value
+			default:
+				return item, false, fmt.Errorf("mapping expression: unknown field %q", field)
+			}
+		}
+		if item.Link == "" {
+			return item, false, errors.New("mapping expression: item has no link")
+		}
+	}
+
+	return item, true, nil
+}
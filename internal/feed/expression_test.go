@@ -0,0 +1,160 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileExpressions(t *testing.T) {
+	e, err := CompileExpressions("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, e)
+
+	tests := []struct {
+		name    string
+		filter  string
+		mapping string
+		wantErr string
+	}{
+		{name: "filter", filter: `title.contains("Go") && !link.endsWith(".pdf")`},
+		{name: "mapping", mapping: `{"title": title.trim()}`},
+		{name: "conditional mapping", mapping: `text.size() > 100 ? {"description": text.substring(0, 100)} : {}`},
+		{name: "syntax error", filter: `title.contains(`, wantErr: "filter expression: ERROR"},
+		{name: "unknown variable", filter: `author == "me"`, wantErr: "undeclared reference to 'author'"},
+		{name: "filter not bool", filter: `title`, wantErr: "expression returns string, expected bool"},
+		{name: "mapping not map", mapping: `title`, wantErr: "mapping expression: expression returns string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileExpressions(tt.filter, tt.mapping)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExpressionsApply(t *testing.T) {
+	item := ExtractedItem{
+		Title:       " Go 1.24 released ",
+		Link:        "https://example.com/go",
+		Description: "<p>Release <b>notes</b></p>",
+		Date:        time.Date(2025, 2, 11, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name     string
+		filter   string
+		mapping  string
+		wantKeep bool
+		want     ExtractedItem
+		wantErr  string
+	}{
+		{name: "kept", filter: `title.contains("Go")`, wantKeep: true, want: item},
+		{name: "dropped", filter: `link.endsWith(".pdf")`, wantKeep: false},
+		{name: "plain text", filter: `text == "Release notes"`, wantKeep: true, want: item},
+		{name: "date", filter: `date > timestamp("2025-01-01T00:00:00Z")`, wantKeep: true, want: item},
+		{
+			name:     "mapped",
+			mapping:  `{"title": title.trim(), "description": text}`,
+			wantKeep: true,
+			want:     ExtractedItem{Title: "Go 1.24 released", Link: item.Link, Description: "Release notes", Date: item.Date},
+		},
+		{name: "unknown field", mapping: `{"author": "me"}`, wantErr: `unknown field "author"`},
+		{name: "empty link", mapping: `{"link": ""}`, wantErr: "item has no link"},
+		{name: "evaluation error", filter: `title.substring(100) == ""`, wantErr: "filter expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := CompileExpressions(tt.filter, tt.mapping)
+			assert.NoError(t, err)
+
+			got, keep, err := e.Apply(item)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKeep, keep)
+			if keep {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNilExpressionsKeepItems(t *testing.T) {
+	var e *Expressions
+	item := ExtractedItem{Title: "Title", Link: "https://example.com"}
+
+	got, keep, err := e.Apply(item)
+	assert.NoError(t, err)
+	assert.True(t, keep)
+	assert.Equal(t, item, got)
+}
+
+func TestRefreshFeedAppliesExpressions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body>
+			<div class="item"><h2 class="title">go tips</h2><a class="link" href="/tips">Link</a></div>
+			<div class="item"><h2 class="title">go handbook</h2><a class="link" href="/handbook.pdf">Link</a></div>
+		</body></html>`)
+	}))
+	defer ts.Close()
+
+	var upsertedItems []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			upsertedItems = append(upsertedItems, params)
+			return []int64{int64(len(upsertedItems))}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:               1,
+		Url:              ts.URL,
+		ItemSelector:     sql.NullString{String: ".item", Valid: true},
+		TitleSelector:    sql.NullString{String: ".title", Valid: true},
+		LinkSelector:     sql.NullString{String: ".link", Valid: true},
+		FilterExpression: sql.NullString{String: `!link.endsWith(".pdf")`, Valid: true},
+		MapExpression:    sql.NullString{String: `{"title": title.upperAscii()}`, Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	if assert.Len(t, upsertedItems, 1) {
+		assert.Equal(t, "GO TIPS", upsertedItems[0].Title)
+		assert.Equal(t, ts.URL+"/tips", upsertedItems[0].Link)
+	}
+}
+
+func TestRefreshFeedInvalidExpression(t *testing.T) {
+	svc := NewService(&mockQueries{})
+
+	feed := db.Feed{
+		ID:               1,
+		Url:              "http://example.com",
+		ItemSelector:     sql.NullString{String: ".item", Valid: true},
+		TitleSelector:    sql.NullString{String: ".title", Valid: true},
+		LinkSelector:     sql.NullString{String: ".link", Valid: true},
+		FilterExpression: sql.NullString{String: `title`, Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.ErrorContains(t, err, "invalid expressions")
+}
@@ -0,0 +1,154 @@
+package feed
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// dateLayouts are the formats tried, in order, when parsing item dates
+var dateLayouts = []string{
+	"2006-01-02", // YYYY-MM-DD
+	"2006/01/02", // YYYY/MM/DD
+	"02-01-2006", // DD-MM-YYYY
+	time.RFC1123, // Mon, 02 Jan 2006 15:04:05 MST
+	time.RFC3339, // 2006-01-02T15:04:05Z07:00
+}
+
+// ExtractedItem is an item read from a page, before it is filtered and stored
+type ExtractedItem struct {
+	Title       string
+	Link        string
+	Description string
+	Date        time.Time
+}
+
+// Entry returns the item as seen by filter rules
+func (i ExtractedItem) Entry() Entry {
+	return Entry{Title: i.Title, Link: i.Link, Description: i.Description}
+}
+
+// ExtractItems applies the selectors of a list feed to a parsed page, one
+// item per element matched by the item selector. Links and description URLs
+// are resolved against baseURL.
+func (s *Service) ExtractItems(feed db.Feed, doc *goquery.Document, baseURL *url.URL) []ExtractedItem {
+	removeSelectors := ParseRemoveSelectors(feed.RemoveSelectors.String)
+
+	var items []ExtractedItem
+	doc.Find(feed.ItemSelector.String).Each(func(i int, sel *goquery.Selection) {
+		title := strings.TrimSpace(sel.Find(feed.TitleSelector.String).Text())
+		link, exists := sel.Find(feed.LinkSelector.String).Attr("href")
+		if !exists {
+			link = strings.TrimSpace(sel.Find(feed.LinkSelector.String).Text())
+		}
+
+		var date time.Time
+		if feed.DateSelector.Valid && feed.DateSelector.String != "" {
+			date = ParseItemDate(strings.TrimSpace(sel.Find(feed.DateSelector.String).Text()))
+		}
+
+		descriptionSel := sel
+		if feed.DescriptionSelector.Valid {
+			descriptionSel = sel.Find(feed.DescriptionSelector.String)
+		}
+		// Clean up a copy of the description, which may be the whole item, so
+		// that removed elements are only removed from the description
+		descriptionSel = descriptionSel.Clone()
+		RemoveElements(descriptionSel, removeSelectors)
+		AbsolutizeURLs(descriptionSel, baseURL)
+
+		description, err := descriptionSel.Html()
+		if err != nil {
+			log.Printf("Failed to get feed item description: %v", err)
+		}
+		description = s.sanitizer.Sanitize(description, feed.DescriptionFormat)
+
+		// Make link absolute if it's relative
+		if link != "" {
+			parsedLink, err := url.Parse(link)
+			if err == nil {
+				link = baseURL.ResolveReference(parsedLink).String()
+			}
+		}
+
+		items = append(items, ExtractedItem{
+			Title:       title,
+			Link:        link,
+			Description: description,
+			Date:        date,
+		})
+	})
+
+	return items
+}
+
+// cleanDescription runs an HTML fragment through the description pipeline of
+// a feed, as the CSS extractor does with matched elements: without the feed's
+// removed elements, with URLs resolved against base and sanitized
+func (s *Service) cleanDescription(feed db.Feed, fragment string, base *url.URL) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		log.Printf("Failed to parse feed item description: %v", err)
+		return s.sanitizer.Sanitize(fragment, feed.DescriptionFormat)
+	}
+
+	body := doc.Find("body")
+	RemoveElements(body, ParseRemoveSelectors(feed.RemoveSelectors.String))
+	AbsolutizeURLs(body, base)
+
+	if fragment, err = body.Html(); err != nil {
+		log.Printf("Failed to get feed item description: %v", err)
+	}
+	return s.sanitizer.Sanitize(fragment, feed.DescriptionFormat)
+}
+
+// extractPages extracts the items of every page, keeping the first item seen
+// for each link when pages overlap. It also returns the number of items
+// extracted before removing duplicates.
+func (s *Service) extractPages(feed db.Feed, extractor Extractor, pages []Page) ([]ExtractedItem, int, error) {
+	var items []ExtractedItem
+	var matched int
+	seen := make(map[string]bool)
+	for _, page := range pages {
+		extracted, err := extractor.Extract(feed, page)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to extract items from %s: %w", page.URL, err)
+		}
+		matched += len(extracted)
+
+		for _, item := range extracted {
+			if item.Link != "" && seen[item.Link] {
+				continue
+			}
+			seen[item.Link] = true
+			items = append(items, item)
+		}
+	}
+	return items, matched, nil
+}
+
+// ParseItemDate parses the text of a date element, returning the zero time
+// when none of the known layouts match
+func ParseItemDate(dateStr string) time.Time {
+	// Strip weekday in parentheses if present, e.g., "2025-08-09 (土)" → "2025-08-09"
+	if idx := strings.Index(dateStr, " "); idx != -1 {
+		dateStr = dateStr[:idx]
+	}
+
+	var err error
+	for _, layout := range dateLayouts {
+		var date time.Time
+		date, err = time.Parse(layout, dateStr)
+		if err == nil {
+			return date
+		}
+	}
+
+	log.Printf("Failed to parse date '%s': %v", dateStr, err)
+	return time.Time{}
+}
@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// SourceCSS extracts items from HTML pages with the feed's CSS selectors
+const SourceCSS = "css"
+
+// Extractor turns the pages fetched for a list feed into items. Extractors
+// are registered on the service under the source type stored in
+// feeds.source_type.
+type Extractor interface {
+	// Validate reports the settings the feed is missing for this extractor
+	Validate(feed db.Feed) error
+	// Extract returns the items of a page, in page order
+	Extract(feed db.Feed, page Page) ([]ExtractedItem, error)
+}
+
+// ErrUnknownSourceType is returned for feeds whose source type has no
+// registered extractor
+var ErrUnknownSourceType = errors.New("unknown source type")
+
+// NormalizeSourceType returns the source type to use for a feed, defaulting
+// to SourceCSS
+func NormalizeSourceType(sourceType string) string {
+	if sourceType == "" {
+		return SourceCSS
+	}
+	return sourceType
+}
+
+// RegisterExtractor makes an extractor available to feeds of the given source
+// type, replacing any extractor registered for it
+func (s *Service) RegisterExtractor(sourceType string, extractor Extractor) {
+	s.extractorsMu.Lock()
+	defer s.extractorsMu.Unlock()
+	s.extractors[sourceType] = extractor
+}
+
+// SourceTypes returns the registered source types, sorted
+func (s *Service) SourceTypes() []string {
+	s.extractorsMu.RLock()
+	defer s.extractorsMu.RUnlock()
+	types := make([]string, 0, len(s.extractors))
+	for sourceType := range s.extractors {
+		types = append(types, sourceType)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Extractor returns the extractor registered for a feed's source type
+func (s *Service) Extractor(feed db.Feed) (Extractor, error) {
+	sourceType := NormalizeSourceType(feed.SourceType)
+	s.extractorsMu.RLock()
+	extractor, ok := s.extractors[sourceType]
+	s.extractorsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownSourceType, sourceType)
+	}
+	return extractor, nil
+}
+
+// Extract returns the items of a page with the extractor of the feed's source type
+func (s *Service) Extract(feed db.Feed, page Page) ([]ExtractedItem, error) {
+	extractor, err := s.Extractor(feed)
+	if err != nil {
+		return nil, err
+	}
+	return extractor.Extract(feed, page)
+}
+
+// cssExtractor is the default extractor: one item per element matched by the
+// item selector, with title, link, description and date read by selectors
+type cssExtractor struct {
+	service *Service
+}
+
+func (e cssExtractor) Validate(feed db.Feed) error {
+	if !feed.ItemSelector.Valid {
+		return fmt.Errorf("feed %d is missing item selector", feed.ID)
+	}
+
+	if !feed.TitleSelector.Valid {
+		return fmt.Errorf("feed %d is missing title selector", feed.ID)
+	}
+
+	if !feed.LinkSelector.Valid {
+		return fmt.Errorf("feed %d is missing link selector", feed.ID)
+	}
+
+	return nil
+}
+
+func (e cssExtractor) Extract(feed db.Feed, page Page) ([]ExtractedItem, error) {
+	return e.service.ExtractItems(feed, page.Doc, page.URL), nil
+}
@@ -0,0 +1,75 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// lineExtractor makes one item per non-empty line of a plain text page
+type lineExtractor struct{}
+
+func (lineExtractor) Validate(feed db.Feed) error { return nil }
+
+func (lineExtractor) Extract(feed db.Feed, page Page) ([]ExtractedItem, error) {
+	var items []ExtractedItem
+	for line := range strings.Lines(string(page.Body)) {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, ExtractedItem{Title: line, Link: page.URL.String() + "#" + line})
+		}
+	}
+	return items, nil
+}
+
+func TestNormalizeSourceType(t *testing.T) {
+	assert.Equal(t, SourceCSS, NormalizeSourceType(""))
+	assert.Equal(t, "lines", NormalizeSourceType("lines"))
+}
+
+func TestSourceTypes(t *testing.T) {
+	svc := NewService(&mockQueries{})
+	assert.Equal(t, []string{SourceCSS, SourcePattern}, svc.SourceTypes())
+
+	svc.RegisterExtractor("lines", lineExtractor{})
+	assert.Equal(t, []string{SourceCSS, "lines", SourcePattern}, svc.SourceTypes())
+}
+
+func TestRefreshFeedUsesRegisteredExtractor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "first\n\nsecond\n")
+	}))
+	defer ts.Close()
+
+	var upsertedItems []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			upsertedItems = append(upsertedItems, params)
+			return []int64{int64(len(upsertedItems))}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+	svc.RegisterExtractor("lines", lineExtractor{})
+
+	err := svc.RefreshFeed(context.Background(), db.Feed{ID: 1, Url: ts.URL, SourceType: "lines"})
+	assert.NoError(t, err)
+
+	if assert.Len(t, upsertedItems, 2) {
+		assert.Equal(t, "first", upsertedItems[0].Title)
+		assert.Equal(t, ts.URL+"#second", upsertedItems[1].Link)
+	}
+}
+
+func TestRefreshFeedUnknownSourceType(t *testing.T) {
+	svc := NewService(&mockQueries{})
+
+	err := svc.RefreshFeed(context.Background(), db.Feed{ID: 1, Url: "http://example.com", SourceType: "missing"})
+	assert.True(t, errors.Is(err, ErrUnknownSourceType))
+}
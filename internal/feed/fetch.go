@@ -0,0 +1,123 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// Page is a fetched and parsed source page of a feed
+type Page struct {
+	// URL is where the page was served from, after redirects
+	URL *url.URL
+	// Body is the page as served, for extractors that do not read HTML
+	Body []byte
+	Doc  *goquery.Document
+}
+
+// fetchPages gets the pages of all the URLs a feed is built from, with its
+// authentication, keeping the session cookies of form logins for the next
+// refresh. Pages that fail are skipped as long as one of them could be
+// fetched, since dated archives do not always exist for every day.
+func (s *Service) fetchPages(ctx context.Context, feed db.Feed) ([]Page, error) {
+	row, err := s.queries.GetFeedAuth(ctx, feed.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load authentication: %w", err)
+	}
+	auth := AuthFromRow(row)
+
+	urls := SourceURLs(feed, s.now())
+	if feed.Mode == ModeMonitor {
+		// A monitored region is a single page
+		urls = urls[:1]
+	}
+
+	cookies, err := DecodeCookies(row.Cookies.String)
+	if err != nil {
+		log.Printf("Feed %d: discarding stored session: %v", feed.ID, err)
+		cookies = nil
+	}
+
+	session := NewSession(auth, cookies)
+
+	var pages []Page
+	var errs []error
+	for _, u := range urls {
+		page, err := s.fetchPage(ctx, feed.ID, session, u)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u, err))
+			continue
+		}
+		pages = append(pages, page)
+	}
+
+	if auth.Type == AuthForm {
+		encoded, err := EncodeCookies(session.Cookies())
+		if err == nil && encoded != row.Cookies.String {
+			err = s.queries.UpdateFeedAuthCookies(ctx, db.UpdateFeedAuthCookiesParams{
+				FeedID:  feed.ID,
+				Cookies: sql.NullString{String: encoded, Valid: true},
+			})
+		}
+		if err != nil {
+			log.Printf("Feed %d: failed to store session cookies: %v", feed.ID, err)
+		}
+	}
+
+	if len(pages) == 0 {
+		if len(errs) == 1 {
+			return nil, errors.Unwrap(errs[0])
+		}
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		log.Printf("Feed %d: skipping page %v", feed.ID, err)
+	}
+
+	return pages, nil
+}
+
+// maxPageSize bounds the part of a page that is read, parsed and stored
+const maxPageSize = 10 << 20
+
+// fetchPage gets and parses a single page, storing the raw response. Pages
+// are cut at maxPageSize.
+func (s *Service) fetchPage(ctx context.Context, feedID int64, session *Session, rawURL string) (Page, error) {
+	resp, err := session.Get(ctx, rawURL)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("failed to close response body: %v", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Keep what the site served, whatever the outcome of the refresh
+	s.saveResponse(feedID, resp, body)
+
+	if resp.StatusCode != http.StatusOK {
+		return Page{}, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	return Page{URL: resp.Request.URL, Body: body, Doc: doc}, nil
+}
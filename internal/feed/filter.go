@@ -0,0 +1,214 @@
+package feed
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// Entry is an extracted item as seen by filter rules
+type Entry struct {
+	Title       string
+	Link        string
+	Description string
+}
+
+// Fields a rule can inspect
+const (
+	FieldTitle       = "title"
+	FieldLink        = "link"
+	FieldDescription = "description"
+)
+
+// Rule operators
+const (
+	OpContains    = "contains"
+	OpNotContains = "not_contains"
+	OpMatches     = "matches"
+	OpNotMatches  = "not_matches"
+	OpLongerThan  = "longer_than"
+	OpShorterThan = "shorter_than"
+)
+
+// Rule is a single condition an entry must satisfy to be kept
+type Rule struct {
+	Field    string
+	Operator string
+	Value    string
+
+	re     *regexp.Regexp
+	length int
+}
+
+// NewRule validates a rule and prepares it for evaluation
+func NewRule(field, operator, value string) (Rule, error) {
+	r := Rule{Field: field, Operator: operator, Value: value}
+
+	switch field {
+	case FieldTitle, FieldLink, FieldDescription:
+	default:
+		return Rule{}, fmt.Errorf("unknown field %q", field)
+	}
+
+	switch operator {
+	case OpContains, OpNotContains:
+	case OpMatches, OpNotMatches:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+		r.re = re
+	case OpLongerThan, OpShorterThan:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return Rule{}, fmt.Errorf("invalid length %q", value)
+		}
+		r.length = n
+	default:
+		return Rule{}, fmt.Errorf("unknown operator %q", operator)
+	}
+
+	return r, nil
+}
+
+// Holds reports whether the entry satisfies the rule
+func (r Rule) Holds(e Entry) bool {
+	value := e.field(r.Field)
+
+	switch r.Operator {
+	case OpContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(r.Value))
+	case OpNotContains:
+		return !strings.Contains(strings.ToLower(value), strings.ToLower(r.Value))
+	case OpMatches:
+		return r.re.MatchString(value)
+	case OpNotMatches:
+		return !r.re.MatchString(value)
+	case OpLongerThan:
+		return utf8.RuneCountInString(value) > r.length
+	case OpShorterThan:
+		return utf8.RuneCountInString(value) < r.length
+	}
+
+	return true
+}
+
+// String formats the rule the way ParseFilter reads it
+func (r Rule) String() string {
+	return r.Field + " " + r.Operator + " " + r.Value
+}
+
+// field returns the value a rule inspects. Descriptions are compared on
+// their text content rather than their markup.
+func (e Entry) field(name string) string {
+	switch name {
+	case FieldTitle:
+		return e.Title
+	case FieldLink:
+		return e.Link
+	case FieldDescription:
+		return plainText(e.Description)
+	}
+	return ""
+}
+
+// ruleParts is the number of space-separated parts of a rule line
+const ruleParts = 3
+
+// Filter keeps the entries that satisfy every one of its rules
+type Filter []Rule
+
+// ParseFilter reads one rule per line, as "<field> <operator> <value>".
+// Blank lines and lines starting with # are ignored.
+func ParseFilter(text string) (Filter, error) {
+	var f Filter
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, " ", ruleParts)
+		if len(parts) < ruleParts {
+			return nil, fmt.Errorf("line %d: expected \"<field> <operator> <value>\"", i+1)
+		}
+
+		rule, err := NewRule(parts[0], parts[1], strings.TrimSpace(parts[2]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		f = append(f, rule)
+	}
+	return f, nil
+}
+
+// FilterFromRules builds a filter from stored rules
+func FilterFromRules(rows []db.FeedFilterRule) (Filter, error) {
+	f := make(Filter, 0, len(rows))
+	for _, row := range rows {
+		rule, err := NewRule(row.Field, row.Operator, row.Value)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", row.ID, err)
+		}
+		f = append(f, rule)
+	}
+	return f, nil
+}
+
+// Check reports whether the entry is kept and, if not, the rule it failed
+func (f Filter) Check(e Entry) (bool, string) {
+	for _, rule := range f {
+		if !rule.Holds(e) {
+			return false, rule.String()
+		}
+	}
+	return true, ""
+}
+
+// ItemCheck is the outcome of a feed's expressions and filter rules for an
+// extracted item
+type ItemCheck struct {
+	// Item is the item as rewritten by the mapping expression
+	Item ExtractedItem
+	// Err is set when the expressions could not be evaluated, and
+	// DroppedByExpression when the filter expression drops the item. The
+	// filter rules are only checked otherwise.
+	Err                 error
+	DroppedByExpression bool
+	// Rule is the filter rule the item fails, if any
+	Rule string
+}
+
+// Kept reports whether the item is stored
+func (c ItemCheck) Kept() bool {
+	return c.Err == nil && !c.DroppedByExpression && c.Rule == ""
+}
+
+// RulesChecked reports whether the item reached the filter rules
+func (c ItemCheck) RulesChecked() bool {
+	return c.Err == nil && !c.DroppedByExpression
+}
+
+// CheckItem applies a feed's expressions and then its filter rules to an
+// item, so that the rules see the item as rewritten by the mapping
+func CheckItem(item ExtractedItem, expressions *Expressions, filter Filter) ItemCheck {
+	mapped, keep, err := expressions.Apply(item)
+	check := ItemCheck{Item: mapped, Err: err, DroppedByExpression: err == nil && !keep}
+	if check.RulesChecked() {
+		_, check.Rule = filter.Check(mapped.Entry())
+	}
+	return check
+}
+
+// String formats the filter the way ParseFilter reads it
+func (f Filter) String() string {
+	lines := make([]string, len(f))
+	for i, rule := range f {
+		lines[i] = rule.String()
+	}
+	return strings.Join(lines, "\n")
+}
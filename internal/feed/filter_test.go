@@ -0,0 +1,107 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter(`
+		# Only Go jobs
+		title contains Go
+		title not_matches (?i)senior|lead
+		description longer_than 10
+	`)
+	assert.NoError(t, err)
+	assert.Len(t, filter, 3)
+	assert.Equal(t, "title contains Go\ntitle not_matches (?i)senior|lead\ndescription longer_than 10", filter.String())
+
+	// Round trip
+	again, err := ParseFilter(filter.String())
+	assert.NoError(t, err)
+	assert.Equal(t, filter.String(), again.String())
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing value", "title contains"},
+		{"unknown field", "author contains Bob"},
+		{"unknown operator", "title startswith Go"},
+		{"invalid regex", "title matches ("},
+		{"invalid length", "description longer_than many"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFilterCheck(t *testing.T) {
+	filter, err := ParseFilter("title contains go\ntitle not_contains senior\ndescription shorter_than 20")
+	assert.NoError(t, err)
+
+	kept, reason := filter.Check(Entry{Title: "Go Developer", Description: "<p>Remote <b>OK</b></p>"})
+	assert.True(t, kept)
+	assert.Empty(t, reason)
+
+	kept, reason = filter.Check(Entry{Title: "Rust Developer"})
+	assert.False(t, kept)
+	assert.Equal(t, "title contains go", reason)
+
+	kept, reason = filter.Check(Entry{Title: "Senior Go Developer"})
+	assert.False(t, kept)
+	assert.Equal(t, "title not_contains senior", reason)
+
+	// Description length is measured on text, not markup
+	kept, reason = filter.Check(Entry{Title: "Go", Description: "<p>" + "a long description text" + "</p>"})
+	assert.False(t, kept)
+	assert.Equal(t, "description shorter_than 20", reason)
+
+	// An empty filter keeps everything
+	kept, _ = Filter(nil).Check(Entry{})
+	assert.True(t, kept)
+}
+
+func TestCheckItem(t *testing.T) {
+	filter, err := ParseFilter("title not_contains senior")
+	assert.NoError(t, err)
+	expressions, err := CompileExpressions(`!link.endsWith(".pdf")`, `{"title": title.replace("Sr.", "Senior")}`)
+	assert.NoError(t, err)
+
+	// Rules see the item as rewritten by the mapping
+	check := CheckItem(ExtractedItem{Title: "Sr. Go Developer", Link: "https://example.com/sr"}, expressions, filter)
+	assert.False(t, check.Kept())
+	assert.True(t, check.RulesChecked())
+	assert.Equal(t, "Senior Go Developer", check.Item.Title)
+	assert.Equal(t, "title not_contains senior", check.Rule)
+
+	// Items dropped by the filter expression never reach them
+	check = CheckItem(ExtractedItem{Title: "Senior Handbook", Link: "https://example.com/go.pdf"}, expressions, filter)
+	assert.False(t, check.Kept())
+	assert.True(t, check.DroppedByExpression)
+	assert.Empty(t, check.Rule)
+
+	check = CheckItem(ExtractedItem{Title: "Go Developer", Link: "https://example.com/go"}, expressions, filter)
+	assert.True(t, check.Kept())
+}
+
+func TestFilterFromRules(t *testing.T) {
+	filter, err := FilterFromRules([]db.FeedFilterRule{
+		{ID: 1, Field: "link", Operator: "not_matches", Value: `\.pdf$`},
+	})
+	assert.NoError(t, err)
+
+	kept, _ := filter.Check(Entry{Link: "https://example.com/file.pdf"})
+	assert.False(t, kept)
+
+	_, err = FilterFromRules([]db.FeedFilterRule{{ID: 2, Field: "title", Operator: "bogus", Value: "x"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/notify"
+)
+
+const (
+	// minItemsForDropCheck is the smallest previous match count for which a
+	// drop is considered meaningful; small lists fluctuate naturally
+	minItemsForDropCheck = 5
+	// dropDivisor flags a refresh matching less than 1/dropDivisor of the
+	// elements the previous refresh matched
+	dropDivisor = 2
+)
+
+// CheckBreakage tells why a refresh of the feed matching the given number of
+// elements looks broken compared to the previous refresh, or returns "" if it
+// does not
+func CheckBreakage(feed db.Feed, matched int) string {
+	previous := feed.LastItemCount
+	if matched == 0 {
+		return describeMatches(feed, 0)
+	}
+	if previous.Valid && previous.Int64 >= minItemsForDropCheck && int64(matched*dropDivisor) < previous.Int64 {
+		return fmt.Sprintf("%s, down from %d", describeMatches(feed, matched), previous.Int64)
+	}
+	return ""
+}
+
+// describeMatches words what a refresh of the feed found, in the terms of
+// its source type
+func describeMatches(feed db.Feed, matched int) string {
+	switch {
+	case feed.Mode != ModeMonitor && feed.SourceType == SourcePattern:
+		if matched == 0 {
+			return "pattern matched no items"
+		}
+		return fmt.Sprintf("pattern matched %d items", matched)
+	case feed.Mode != ModeMonitor && strings.HasPrefix(feed.SourceType, SourceWASMPrefix):
+		if matched == 0 {
+			return "plugin returned no items"
+		}
+		return fmt.Sprintf("plugin returned %d items", matched)
+	default:
+		if matched == 0 {
+			return "item selector matched nothing"
+		}
+		return fmt.Sprintf("item selector matched %d elements", matched)
+	}
+}
+
+// recordHealth stores whether the feed looks broken after a refresh that
+// matched the given number of elements, and notifies when that changes
+func (s *Service) recordHealth(ctx context.Context, feed db.Feed, matched int) error {
+	reason := CheckBreakage(feed, matched)
+	wasBroken := feed.BrokenReason.Valid
+
+	params := db.UpdateFeedHealthParams{
+		ID:            feed.ID,
+		LastItemCount: sql.NullInt64{Int64: int64(matched), Valid: true},
+	}
+	if reason != "" {
+		params.BrokenReason = sql.NullString{String: reason, Valid: true}
+		params.BrokenSince = feed.BrokenSince
+		if !wasBroken {
+			params.BrokenSince = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		}
+		// Keep comparing against the count from before the breakage until it recovers
+		params.LastItemCount = feed.LastItemCount
+	}
+
+	if err := s.queries.UpdateFeedHealth(ctx, params); err != nil {
+		return fmt.Errorf("failed to store feed health: %w", err)
+	}
+
+	switch {
+	case reason != "" && !wasBroken:
+		log.Printf("Feed %d looks broken: %s", feed.ID, reason)
+		s.notify(ctx, notify.Event{
+			Kind:    notify.KindBroken,
+			FeedID:  feed.ID,
+			Feed:    feed.Name,
+			URL:     feed.Url,
+			Message: fmt.Sprintf("Feed %q looks broken: %s", feed.Name, reason),
+		})
+	case reason == "" && wasBroken:
+		log.Printf("Feed %d recovered", feed.ID)
+		s.notify(ctx, notify.Event{
+			Kind:    notify.KindRecovered,
+			FeedID:  feed.ID,
+			Feed:    feed.Name,
+			URL:     feed.Url,
+			Message: fmt.Sprintf("Feed %q is working again", feed.Name),
+		})
+	}
+
+	return nil
+}
+
+// checkHealth records the feed's health after a refresh matching the given
+// number of elements. A redesign usually shows up as the item selector or
+// pattern matching nothing, or far less than it used to; matching nothing
+// fails the refresh.
+func (s *Service) checkHealth(ctx context.Context, feed db.Feed, matched int) error {
+	if err := s.recordHealth(ctx, feed, matched); err != nil {
+		log.Printf("Failed to record health of feed %d: %v", feed.ID, err)
+	}
+	if matched == 0 {
+		return fmt.Errorf("feed %d: %s", feed.ID, describeMatches(feed, 0))
+	}
+	return nil
+}
+
+// notify sends an event through the configured channels, if any
+func (s *Service) notify(ctx context.Context, event notify.Event) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, event); err != nil {
+		log.Printf("Failed to send %s notification for feed %d: %v", event.Kind, event.FeedID, err)
+	}
+}
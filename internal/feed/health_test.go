@@ -0,0 +1,130 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	events []notify.Event
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event notify.Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestCheckBreakage(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous sql.NullInt64
+		matched  int
+		broken   bool
+	}{
+		{"first refresh", sql.NullInt64{}, 3, false},
+		{"zero matches", sql.NullInt64{Int64: 10, Valid: true}, 0, true},
+		{"zero matches on first refresh", sql.NullInt64{}, 0, true},
+		{"steady", sql.NullInt64{Int64: 10, Valid: true}, 9, false},
+		{"sharp drop", sql.NullInt64{Int64: 20, Valid: true}, 3, true},
+		{"half is fine", sql.NullInt64{Int64: 20, Valid: true}, 10, false},
+		{"small lists fluctuate", sql.NullInt64{Int64: 4, Valid: true}, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := db.Feed{LastItemCount: tt.previous}
+			assert.Equal(t, tt.broken, CheckBreakage(feed, tt.matched) != "")
+		})
+	}
+}
+
+func TestCheckBreakageWordsBySourceType(t *testing.T) {
+	previous := sql.NullInt64{Int64: 20, Valid: true}
+	tests := []struct {
+		sourceType string
+		mode       string
+		empty      string
+		drop       string
+	}{
+		{SourceCSS, ModeList, "item selector matched nothing", "item selector matched 3 elements, down from 20"},
+		{SourcePattern, ModeList, "pattern matched no items", "pattern matched 3 items, down from 20"},
+		{SourceWASMPrefix + "example", ModeList, "plugin returned no items", "plugin returned 3 items, down from 20"},
+		{SourcePattern, ModeMonitor, "item selector matched nothing", "item selector matched 3 elements, down from 20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sourceType+"/"+tt.mode, func(t *testing.T) {
+			feed := db.Feed{SourceType: tt.sourceType, Mode: tt.mode, LastItemCount: previous}
+			assert.Equal(t, tt.empty, CheckBreakage(feed, 0))
+			assert.Equal(t, tt.drop, CheckBreakage(feed, 3))
+		})
+	}
+}
+
+func TestRefreshFeedDetectsBreakage(t *testing.T) {
+	html := `<div class="item"><h2 class="title">A</h2><a class="link" href="/a">Link</a></div>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, html)
+	}))
+	defer ts.Close()
+
+	var health []db.UpdateFeedHealthParams
+	mockQ := &mockQueries{
+		UpdateFeedHealthFn: func(ctx context.Context, arg db.UpdateFeedHealthParams) error {
+			health = append(health, arg)
+			return nil
+		},
+	}
+
+	notifier := &recordingNotifier{}
+	svc := NewService(mockQ)
+	svc.SetNotifier(notifier)
+
+	feed := db.Feed{
+		ID:            1,
+		Name:          "Blog",
+		Url:           ts.URL,
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+		LastItemCount: sql.NullInt64{Int64: 1, Valid: true},
+	}
+
+	// The site is redesigned and the item selector no longer matches
+	html = `<article><h1>A</h1></article>`
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.Error(t, err)
+
+	assert.Len(t, health, 1)
+	assert.Equal(t, "item selector matched nothing", health[0].BrokenReason.String)
+	assert.True(t, health[0].BrokenSince.Valid)
+	assert.Equal(t, int64(1), health[0].LastItemCount.Int64)
+	assert.Len(t, notifier.events, 1)
+	assert.Equal(t, notify.KindBroken, notifier.events[0].Kind)
+
+	// Still broken: no new notification
+	feed.BrokenReason = health[0].BrokenReason
+	feed.BrokenSince = health[0].BrokenSince
+	err = svc.RefreshFeed(context.Background(), feed)
+	assert.Error(t, err)
+	assert.Len(t, notifier.events, 1)
+	assert.Equal(t, feed.BrokenSince, health[1].BrokenSince)
+
+	// The selectors match again
+	html = strings.Repeat(`<div class="item"><h2 class="title">A</h2><a class="link" href="/a">Link</a></div>`, 2)
+	err = svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+	assert.False(t, health[2].BrokenReason.Valid)
+	assert.Equal(t, int64(2), health[2].LastItemCount.Int64)
+	assert.Len(t, notifier.events, 2)
+	assert.Equal(t, notify.KindRecovered, notifier.events[1].Kind)
+}
@@ -20,6 +20,24 @@ type mockQueries struct {
 	ListFeedItemsFn             func(ctx context.Context, feedID int64) ([]db.FeedItem, error)
 	UpsertFeedItemFn            func(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error)
 	DeleteItemsByFeedIDFn       func(ctx context.Context, feedID int64) error
+	GetFeedItemFn               func(ctx context.Context, id int64) (db.FeedItem, error)
+	GetFeedItemByLinkFn         func(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error)
+	UpdateFeedItemContentFn     func(ctx context.Context, arg db.UpdateFeedItemContentParams) error
+	CreateFeedItemRevisionFn    func(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error)
+	ListFeedItemRevisionsFn     func(ctx context.Context, feedItemID int64) ([]db.FeedItemRevision, error)
+	UpdateFeedSnapshotFn        func(ctx context.Context, arg db.UpdateFeedSnapshotParams) error
+	ListFeedFilterRulesFn       func(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error)
+	CreateFeedFilterRuleFn      func(ctx context.Context, arg db.CreateFeedFilterRuleParams) error
+	DeleteFeedFilterRulesFn     func(ctx context.Context, feedID int64) error
+	PruneFeedItemsByAgeFn       func(ctx context.Context, arg db.PruneFeedItemsByAgeParams) (int64, error)
+	PruneFeedItemsByCountFn     func(ctx context.Context, arg db.PruneFeedItemsByCountParams) (int64, error)
+	UpdateFeedRetentionFn       func(ctx context.Context, arg db.UpdateFeedRetentionParams) error
+	UpdateFeedHealthFn          func(ctx context.Context, arg db.UpdateFeedHealthParams) error
+	GetFeedAuthFn               func(ctx context.Context, feedID int64) (db.FeedAuth, error)
+	UpdateFeedAuthCookiesFn     func(ctx context.Context, arg db.UpdateFeedAuthCookiesParams) error
+	TombstoneFeedItemsByAgeFn   func(ctx context.Context, arg db.TombstoneFeedItemsByAgeParams) error
+	TombstoneFeedItemsByCountFn func(ctx context.Context, arg db.TombstoneFeedItemsByCountParams) error
+	TrimFeedItemTombstonesFn    func(ctx context.Context, arg db.TrimFeedItemTombstonesParams) error
 }
 
 func (m *mockQueries) GetFeed(ctx context.Context, id int64) (db.Feed, error) {
@@ -82,3 +100,111 @@ func (m *mockQueries) DeleteItemsByFeedID(ctx context.Context, feedID int64) err
 	}
 	return nil
 }
+func (m *mockQueries) GetFeedItem(ctx context.Context, id int64) (db.FeedItem, error) {
+	if m.GetFeedItemFn != nil {
+		return m.GetFeedItemFn(ctx, id)
+	}
+	return db.FeedItem{}, nil
+}
+func (m *mockQueries) GetFeedItemByLink(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error) {
+	if m.GetFeedItemByLinkFn != nil {
+		return m.GetFeedItemByLinkFn(ctx, arg)
+	}
+	return db.FeedItem{}, nil
+}
+func (m *mockQueries) UpdateFeedItemContent(ctx context.Context, arg db.UpdateFeedItemContentParams) error {
+	if m.UpdateFeedItemContentFn != nil {
+		return m.UpdateFeedItemContentFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) CreateFeedItemRevision(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error) {
+	if m.CreateFeedItemRevisionFn != nil {
+		return m.CreateFeedItemRevisionFn(ctx, arg)
+	}
+	return db.FeedItemRevision{}, nil
+}
+func (m *mockQueries) ListFeedItemRevisions(ctx context.Context, feedItemID int64) ([]db.FeedItemRevision, error) {
+	if m.ListFeedItemRevisionsFn != nil {
+		return m.ListFeedItemRevisionsFn(ctx, feedItemID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) UpdateFeedSnapshot(ctx context.Context, arg db.UpdateFeedSnapshotParams) error {
+	if m.UpdateFeedSnapshotFn != nil {
+		return m.UpdateFeedSnapshotFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) ListFeedFilterRules(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error) {
+	if m.ListFeedFilterRulesFn != nil {
+		return m.ListFeedFilterRulesFn(ctx, feedID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) CreateFeedFilterRule(ctx context.Context, arg db.CreateFeedFilterRuleParams) error {
+	if m.CreateFeedFilterRuleFn != nil {
+		return m.CreateFeedFilterRuleFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteFeedFilterRules(ctx context.Context, feedID int64) error {
+	if m.DeleteFeedFilterRulesFn != nil {
+		return m.DeleteFeedFilterRulesFn(ctx, feedID)
+	}
+	return nil
+}
+func (m *mockQueries) PruneFeedItemsByAge(ctx context.Context, arg db.PruneFeedItemsByAgeParams) (int64, error) {
+	if m.PruneFeedItemsByAgeFn != nil {
+		return m.PruneFeedItemsByAgeFn(ctx, arg)
+	}
+	return 0, nil
+}
+func (m *mockQueries) PruneFeedItemsByCount(ctx context.Context, arg db.PruneFeedItemsByCountParams) (int64, error) {
+	if m.PruneFeedItemsByCountFn != nil {
+		return m.PruneFeedItemsByCountFn(ctx, arg)
+	}
+	return 0, nil
+}
+func (m *mockQueries) UpdateFeedRetention(ctx context.Context, arg db.UpdateFeedRetentionParams) error {
+	if m.UpdateFeedRetentionFn != nil {
+		return m.UpdateFeedRetentionFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) UpdateFeedHealth(ctx context.Context, arg db.UpdateFeedHealthParams) error {
+	if m.UpdateFeedHealthFn != nil {
+		return m.UpdateFeedHealthFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) GetFeedAuth(ctx context.Context, feedID int64) (db.FeedAuth, error) {
+	if m.GetFeedAuthFn != nil {
+		return m.GetFeedAuthFn(ctx, feedID)
+	}
+	return db.FeedAuth{}, nil
+}
+func (m *mockQueries) UpdateFeedAuthCookies(ctx context.Context, arg db.UpdateFeedAuthCookiesParams) error {
+	if m.UpdateFeedAuthCookiesFn != nil {
+		return m.UpdateFeedAuthCookiesFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) TombstoneFeedItemsByAge(ctx context.Context, arg db.TombstoneFeedItemsByAgeParams) error {
+	if m.TombstoneFeedItemsByAgeFn != nil {
+		return m.TombstoneFeedItemsByAgeFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) TombstoneFeedItemsByCount(ctx context.Context, arg db.TombstoneFeedItemsByCountParams) error {
+	if m.TombstoneFeedItemsByCountFn != nil {
+		return m.TombstoneFeedItemsByCountFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) TrimFeedItemTombstones(ctx context.Context, arg db.TrimFeedItemTombstonesParams) error {
+	if m.TrimFeedItemTombstonesFn != nil {
+		return m.TrimFeedItemTombstonesFn(ctx, arg)
+	}
+	return nil
+}
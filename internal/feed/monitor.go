@@ -0,0 +1,101 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// Feed modes
+const (
+	// ModeList extracts one item per element matched by the item selector
+	ModeList = "list"
+	// ModeMonitor snapshots the region matched by the item selector and
+	// creates an item each time its content changes
+	ModeMonitor = "monitor"
+)
+
+// NormalizeMode returns a known feed mode, defaulting to ModeList
+func NormalizeMode(mode string) string {
+	if mode == ModeMonitor {
+		return ModeMonitor
+	}
+	return ModeList
+}
+
+// RegionSnapshot returns the normalized text content of the elements matched
+// by selector: one line per block of text, with runs of whitespace collapsed,
+// so that markup-only changes are ignored.
+func RegionSnapshot(doc *goquery.Document, selector string) string {
+	var sb strings.Builder
+	doc.Find(selector).Each(func(i int, sel *goquery.Selection) {
+		for _, n := range sel.Nodes {
+			writeBlockText(&sb, n)
+			sb.WriteString("\n")
+		}
+	})
+	return normalizeLines(sb.String())
+}
+
+// refreshMonitoredRegion compares the current snapshot of the monitored
+// region with the previous one and records a new item when it changed. Items
+// link to the page as fetched, with any URL template resolved.
+func (s *Service) refreshMonitoredRegion(ctx context.Context, feed db.Feed, page Page) error {
+	snapshot := RegionSnapshot(page.Doc, feed.ItemSelector.String)
+	if snapshot == "" {
+		return fmt.Errorf("feed %d: selector %q matched no content", feed.ID, feed.ItemSelector.String)
+	}
+
+	previous := feed.LastSnapshot.String
+	if feed.LastSnapshot.Valid && previous == snapshot {
+		log.Printf("Feed %d: monitored region unchanged.", feed.ID)
+		return nil
+	}
+
+	now := time.Now().UTC()
+
+	title := fmt.Sprintf("%s changed", feed.Name)
+	description := "<pre>" + html.EscapeString(Diff(previous, snapshot)) + "</pre>"
+	if !feed.LastSnapshot.Valid {
+		title = fmt.Sprintf("%s: monitoring started", feed.Name)
+		description = "<pre>" + html.EscapeString(snapshot) + "</pre>"
+	}
+
+	if _, err := s.queries.UpsertFeedItem(ctx, db.UpsertFeedItemParams{
+		FeedID:      feed.ID,
+		Title:       title,
+		Description: sql.NullString{String: description, Valid: true},
+		// The fragment keeps links unique per change, as items are keyed by link
+		Link: changeLink(page.URL, now),
+		Date: sql.NullTime{Time: now, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("failed to store change: %w", err)
+	}
+
+	if err := s.queries.UpdateFeedSnapshot(ctx, db.UpdateFeedSnapshotParams{
+		ID:           feed.ID,
+		LastSnapshot: sql.NullString{String: snapshot, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("failed to store snapshot: %w", err)
+	}
+
+	log.Printf("Feed %d: monitored region changed.", feed.ID)
+
+	return nil
+}
+
+// changeLink returns the link of a change to a monitored page: the page's
+// URL with a fragment naming the time of the change
+func changeLink(pageURL *url.URL, changedAt time.Time) string {
+	link := *pageURL
+	link.Fragment = "changed-" + changedAt.Format("20060102T150405Z")
+	return link.String()
+}
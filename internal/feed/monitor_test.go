@@ -0,0 +1,139 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionSnapshot(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`
+		<div class="notice">
+			<p>All   systems
+			   operational</p>
+
+			<p><b>Last</b> checked today</p>
+		</div>
+	`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "All systems\noperational\nLast checked today", RegionSnapshot(doc, ".notice"))
+	assert.Equal(t, "", RegionSnapshot(doc, ".missing"))
+}
+
+func TestRefreshFeedMonitorMode(t *testing.T) {
+	status := "All systems operational"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<html><body><div class="status">%s</div></body></html>`, status)
+	}))
+	defer ts.Close()
+
+	var upserted []db.UpsertFeedItemParams
+	var snapshots []db.UpdateFeedSnapshotParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error) {
+			upserted = append(upserted, arg)
+			return []int64{1}, nil
+		},
+		UpdateFeedSnapshotFn: func(ctx context.Context, arg db.UpdateFeedSnapshotParams) error {
+			snapshots = append(snapshots, arg)
+			return nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:           1,
+		Name:         "Status",
+		Url:          ts.URL,
+		Mode:         ModeMonitor,
+		ItemSelector: sql.NullString{String: ".status", Valid: true},
+	}
+
+	// First refresh records the baseline
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+	assert.Len(t, upserted, 1)
+	assert.Equal(t, "Status: monitoring started", upserted[0].Title)
+	assert.Len(t, snapshots, 1)
+	assert.Equal(t, "All systems operational", snapshots[0].LastSnapshot.String)
+
+	// Unchanged content creates no item
+	feed.LastSnapshot = snapshots[0].LastSnapshot
+	err = svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+	assert.Len(t, upserted, 1)
+
+	// Changed content creates an item carrying the diff
+	status = "Degraded performance"
+	err = svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+	assert.Len(t, upserted, 2)
+	assert.Equal(t, "Status changed", upserted[1].Title)
+	assert.Contains(t, upserted[1].Description.String, "-All systems operational")
+	assert.Contains(t, upserted[1].Description.String, "+Degraded performance")
+	assert.True(t, strings.HasPrefix(upserted[1].Link, ts.URL+"#changed-"))
+	assert.Len(t, snapshots, 2)
+}
+
+func TestRefreshFeedMonitorModeDatedURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><div class="status">All systems operational</div></body></html>`)
+	}))
+	defer ts.Close()
+
+	var upserted []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error) {
+			upserted = append(upserted, arg)
+			return []int64{1}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:           1,
+		Name:         "Status",
+		Url:          ts.URL + "/status/{year}",
+		Mode:         ModeMonitor,
+		ItemSelector: sql.NullString{String: ".status", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	// Items link to the page fetched, not to the template
+	if assert.Len(t, upserted, 1) {
+		assert.True(t, strings.HasPrefix(upserted[0].Link, fmt.Sprintf("%s/status/%d#changed-", ts.URL, time.Now().Year())), upserted[0].Link)
+	}
+}
+
+func TestRefreshFeedMonitorModeNoMatch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body></body></html>`)
+	}))
+	defer ts.Close()
+
+	svc := NewService(&mockQueries{})
+
+	feed := db.Feed{
+		ID:           1,
+		Url:          ts.URL,
+		Mode:         ModeMonitor,
+		ItemSelector: sql.NullString{String: ".status", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.Error(t, err)
+}
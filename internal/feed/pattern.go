@@ -0,0 +1,207 @@
+package feed
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// SourcePattern extracts items by matching Feed43-style text patterns
+// against the source of the page, for pages too badly structured for CSS
+// selectors
+const SourcePattern = "pattern"
+
+// Templates used when a pattern feed leaves them empty: the first capture
+// is the title and the second the link
+const (
+	DefaultTitleTemplate = "{%1}"
+	DefaultLinkTemplate  = "{%2}"
+)
+
+var (
+	// patternToken matches the placeholders of a search pattern
+	patternToken = regexp.MustCompile(`\{[%*]\}`)
+	// patternSpace matches whitespace in the literal parts of a pattern
+	patternSpace = regexp.MustCompile(`\s+`)
+	// captureRef matches the references to captures in item templates
+	captureRef = regexp.MustCompile(`\{%(\d+)\}`)
+)
+
+// CompilePattern turns a search pattern into a regular expression. In a
+// pattern
+//
+//	{%}  captures any text, as little as possible
+//	{*}  skips any text, as little as possible
+//
+// whitespace matches any amount of whitespace, including none, and
+// everything else matches literally.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?s)")
+
+	last := 0
+	for _, loc := range patternToken.FindAllStringIndex(pattern, -1) {
+		sb.WriteString(quotePatternLiteral(pattern[last:loc[0]]))
+		if pattern[loc[0]+1] == '%' {
+			sb.WriteString("(.*?)")
+		} else {
+			sb.WriteString(".*?")
+		}
+		last = loc[1]
+	}
+	sb.WriteString(quotePatternLiteral(pattern[last:]))
+
+	return regexp.Compile(sb.String())
+}
+
+// quotePatternLiteral escapes the literal part of a pattern, letting its
+// whitespace match any whitespace of the page
+func quotePatternLiteral(literal string) string {
+	parts := patternSpace.Split(literal, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, `\s*`)
+}
+
+// Patterns are the compiled search patterns and item templates of a
+// pattern feed
+type Patterns struct {
+	// global isolates the part of the page holding the items, nil for the
+	// whole page
+	global *regexp.Regexp
+	item   *regexp.Regexp
+
+	title       string
+	link        string
+	description string
+	date        string
+}
+
+// CompilePatterns checks and prepares the patterns of a feed. Templates
+// refer to the captures of the item pattern as {%1}, {%2}, ...
+func CompilePatterns(feed db.Feed) (*Patterns, error) {
+	if feed.ItemPattern.String == "" {
+		return nil, errors.New("missing item pattern")
+	}
+
+	p := &Patterns{
+		title:       cmp.Or(feed.TitleTemplate.String, DefaultTitleTemplate),
+		link:        cmp.Or(feed.LinkTemplate.String, DefaultLinkTemplate),
+		description: feed.DescriptionTemplate.String,
+		date:        feed.DateTemplate.String,
+	}
+
+	var err error
+	if feed.GlobalPattern.String != "" {
+		if p.global, err = CompilePattern(feed.GlobalPattern.String); err != nil {
+			return nil, fmt.Errorf("invalid global pattern: %w", err)
+		}
+		if p.global.NumSubexp() > 1 {
+			return nil, errors.New("global pattern may capture at most one {%}")
+		}
+	}
+
+	if p.item, err = CompilePattern(feed.ItemPattern.String); err != nil {
+		return nil, fmt.Errorf("invalid item pattern: %w", err)
+	}
+	if p.item.NumSubexp() == 0 {
+		return nil, errors.New("item pattern has no {%} capture")
+	}
+	// A lazy capture at the very end would always be empty
+	if strings.HasSuffix(strings.TrimSpace(feed.ItemPattern.String), "{%}") {
+		return nil, errors.New("item pattern cannot end with {%}")
+	}
+
+	templates := []struct{ name, template string }{
+		{"title", p.title},
+		{"link", p.link},
+		{"description", p.description},
+		{"date", p.date},
+	}
+	for _, t := range templates {
+		for _, ref := range captureRef.FindAllStringSubmatch(t.template, -1) {
+			if n, _ := strconv.Atoi(ref[1]); n > p.item.NumSubexp() {
+				return nil, fmt.Errorf("%s template refers to %s but the item pattern has %d captures", t.name, ref[0], p.item.NumSubexp())
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// Match returns the captures of every item of a page, {%0} being the whole
+// text an item matched
+func (p *Patterns) Match(source string) [][]string {
+	if p.global != nil {
+		m := p.global.FindStringSubmatch(source)
+		if m == nil {
+			return nil
+		}
+		source = m[len(m)-1]
+	}
+	return p.item.FindAllStringSubmatch(source, -1)
+}
+
+// expandTemplate replaces the capture references of a template
+func expandTemplate(template string, captures []string) string {
+	return captureRef.ReplaceAllStringFunc(template, func(ref string) string {
+		n, _ := strconv.Atoi(ref[2 : len(ref)-1])
+		if n < len(captures) {
+			return captures[n]
+		}
+		return ""
+	})
+}
+
+// patternExtractor extracts one item per match of the item pattern
+type patternExtractor struct {
+	service *Service
+}
+
+func (e patternExtractor) Validate(feed db.Feed) error {
+	if _, err := CompilePatterns(feed); err != nil {
+		return fmt.Errorf("feed %d has invalid patterns: %w", feed.ID, err)
+	}
+	return nil
+}
+
+func (e patternExtractor) Extract(feed db.Feed, page Page) ([]ExtractedItem, error) {
+	patterns, err := CompilePatterns(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []ExtractedItem
+	for _, captures := range patterns.Match(string(page.Body)) {
+		item := ExtractedItem{
+			Title: plainText(expandTemplate(patterns.title, captures)),
+			Link:  strings.TrimSpace(html.UnescapeString(expandTemplate(patterns.link, captures))),
+		}
+
+		if item.Link != "" {
+			if parsedLink, err := url.Parse(item.Link); err == nil {
+				item.Link = page.URL.ResolveReference(parsedLink).String()
+			}
+		}
+
+		if patterns.description != "" {
+			item.Description = e.service.cleanDescription(feed, expandTemplate(patterns.description, captures), page.URL)
+		}
+
+		if patterns.date != "" {
+			item.Date = ParseItemDate(plainText(expandTemplate(patterns.date, captures)))
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,168 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// patternPage is laid out in a table without classes to select items by
+const patternPage = `<html><body>
+<table>
+<tr><td>Menu</td><td><a href="/about">About</a></td></tr>
+</table>
+<!-- news -->
+<table>
+<tr>
+  <td class="x">First &amp; best</td>
+  <td>2025-03-01</td>
+  <td><a href="/news/1?a=1&amp;b=2">more</a> <p>Body <img src="/1.png"></p></td>
+</tr>
+<tr>
+  <td class="x">Second</td>
+  <td>2025-03-02</td>
+  <td><a href="https://other.com/2">more</a> <p>Other body</p></td>
+</tr>
+</table>
+<!-- /news -->
+</body></html>`
+
+func patternFeed() db.Feed {
+	return db.Feed{
+		ID:                  1,
+		SourceType:          SourcePattern,
+		GlobalPattern:       sql.NullString{String: "<!-- news -->{%}<!-- /news -->", Valid: true},
+		ItemPattern:         sql.NullString{String: `<td class="x">{%}</td> <td>{%}</td>{*}<a href="{%}">more</a> {%}</td>`, Valid: true},
+		TitleTemplate:       sql.NullString{String: "{%1}", Valid: true},
+		LinkTemplate:        sql.NullString{String: "{%3}", Valid: true},
+		DescriptionTemplate: sql.NullString{String: "{%4}", Valid: true},
+		DateTemplate:        sql.NullString{String: "{%2}", Valid: true},
+		DescriptionFormat:   FormatHTML,
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    []string
+	}{
+		{pattern: `<b>{%}</b>`, input: `<i>x</i><b>bold</b>`, want: []string{"<b>bold</b>", "bold"}},
+		{pattern: `<b>{*}</b>{%}<`, input: `<b>skip</b>kept<`, want: []string{"<b>skip</b>kept<", "kept"}},
+		{pattern: `<td> {%} </td>`, input: "<td>\n  cell\n</td>", want: []string{"<td>\n  cell\n</td>", "cell"}},
+		{pattern: `(a.b) {%}!`, input: `(a.b)x!`, want: []string{"(a.b)x!", "x"}},
+		{pattern: `(a.b) {%}!`, input: `(aXb)x!`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := CompilePattern(tt.pattern)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, re.FindStringSubmatch(tt.input))
+		})
+	}
+}
+
+func TestCompilePatternsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*db.Feed)
+		wantErr string
+	}{
+		{name: "missing item pattern", change: func(f *db.Feed) { f.ItemPattern.String = "" }, wantErr: "missing item pattern"},
+		{name: "no capture", change: func(f *db.Feed) { f.ItemPattern.String = "<td>{*}</td>" }, wantErr: "has no {%} capture"},
+		{name: "trailing capture", change: func(f *db.Feed) { f.ItemPattern.String = "<td>{%}" }, wantErr: "cannot end with {%}"},
+		{name: "global captures", change: func(f *db.Feed) { f.GlobalPattern.String = "{%}<hr>{%}<hr>" }, wantErr: "at most one {%}"},
+		{name: "unknown capture", change: func(f *db.Feed) { f.LinkTemplate.String = "{%5}" }, wantErr: "link template refers to {%5} but the item pattern has 4 captures"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := patternFeed()
+			tt.change(&f)
+			_, err := CompilePatterns(f)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPatternExtract(t *testing.T) {
+	svc := NewService(&mockQueries{})
+	base, _ := url.Parse("https://example.com/list")
+
+	items, err := svc.Extract(patternFeed(), Page{URL: base, Body: []byte(patternPage)})
+	assert.NoError(t, err)
+
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, "First & best", items[0].Title)
+		assert.Equal(t, "https://example.com/news/1?a=1&b=2", items[0].Link)
+		assert.Equal(t, `<p>Body <img src="https://example.com/1.png"/></p>`, items[0].Description)
+		assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), items[0].Date)
+
+		assert.Equal(t, "Second", items[1].Title)
+		assert.Equal(t, "https://other.com/2", items[1].Link)
+	}
+}
+
+func TestPatternExtractDefaultTemplates(t *testing.T) {
+	svc := NewService(&mockQueries{})
+	base, _ := url.Parse("https://example.com/")
+
+	feed := db.Feed{
+		SourceType:  SourcePattern,
+		ItemPattern: sql.NullString{String: `<td>{%}</td><td><a href="{%}">`, Valid: true},
+	}
+
+	items, err := svc.Extract(feed, Page{URL: base, Body: []byte(patternPage)})
+	assert.NoError(t, err)
+	assert.Equal(t, []ExtractedItem{{Title: "Menu", Link: "https://example.com/about"}}, items)
+}
+
+func TestPatternExtractGlobalPatternMissing(t *testing.T) {
+	svc := NewService(&mockQueries{})
+	base, _ := url.Parse("https://example.com/")
+
+	feed := patternFeed()
+	feed.GlobalPattern.String = "<!-- jobs -->{%}<!-- /jobs -->"
+
+	items, err := svc.Extract(feed, Page{URL: base, Body: []byte(patternPage)})
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestRefreshFeedWithPatterns(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, patternPage)
+	}))
+	defer ts.Close()
+
+	var upsertedItems []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			upsertedItems = append(upsertedItems, params)
+			return []int64{int64(len(upsertedItems))}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := patternFeed()
+	feed.Url = ts.URL
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	if assert.Len(t, upsertedItems, 2) {
+		assert.Equal(t, "First & best", upsertedItems[0].Title)
+		assert.Equal(t, ts.URL+"/news/1?a=1&b=2", upsertedItems[0].Link)
+		assert.True(t, upsertedItems[0].Date.Valid)
+	}
+}
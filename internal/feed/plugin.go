@@ -0,0 +1,329 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// SourceWASMPrefix starts the source type of feeds extracted by a
+// WebAssembly plugin, followed by the name of the plugin
+const SourceWASMPrefix = "wasm:"
+
+const (
+	// pluginExt is the extension of plugin files in the plugin directory
+	pluginExt = ".wasm"
+	// wasmPageSize is the size of a WebAssembly memory page
+	wasmPageSize = 64 << 10
+	// maxWASMPages is the most memory a 32-bit WebAssembly module can address
+	maxWASMPages = 65536
+	// maxPluginOutput bounds the JSON a plugin may write for a single page
+	maxPluginOutput = 8 << 20
+	// maxPluginStderr bounds the diagnostics kept from a failed run
+	maxPluginStderr = 4 << 10
+
+	pluginDirPerm  = 0755
+	pluginFilePerm = 0644
+)
+
+// pluginName matches the names plugins can be installed under
+var pluginName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ErrInvalidPluginName is returned for plugin names that are not usable as
+// file names and source types
+var ErrInvalidPluginName = errors.New("plugin names may only contain lowercase letters, digits, dashes and underscores")
+
+// ErrPluginsDisabled is returned when installing a plugin without a plugin host
+var ErrPluginsDisabled = errors.New("plugins are disabled")
+
+// PluginLimits bound the resources of a single plugin run
+type PluginLimits struct {
+	// MemoryMB is the most memory a plugin may use
+	MemoryMB int
+	// Timeout is how long a plugin may take to extract the items of a page
+	Timeout time.Duration
+}
+
+// PluginHost runs extractor plugins: WebAssembly modules stored as
+// <name>.wasm in a directory, registered as the "wasm:<name>" source type.
+//
+// A plugin is a WASI command. It reads the fetched page from stdin, gets the
+// page URL as its first argument and writes the items to stdout as a JSON
+// array of {"title", "link", "description", "date"} objects. It has no
+// access to the file system or the network, and is stopped when it exceeds
+// the memory or time limits.
+type PluginHost struct {
+	dir     string
+	limits  PluginLimits
+	runtime wazero.Runtime
+}
+
+// NewPluginHost creates a host for the plugins of a directory, creating the
+// directory if needed
+func NewPluginHost(ctx context.Context, dir string, limits PluginLimits) (*PluginHost, error) {
+	if err := os.MkdirAll(dir, pluginDirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+
+	pages := min(max(limits.MemoryMB, 1)*(1<<20)/wasmPageSize, maxWASMPages)
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(pages)).
+		WithCloseOnContextDone(true))
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+
+	return &PluginHost{dir: dir, limits: limits, runtime: runtime}, nil
+}
+
+// Close releases the compiled plugins
+func (h *PluginHost) Close(ctx context.Context) error {
+	return h.runtime.Close(ctx)
+}
+
+// compile prepares a plugin module for running
+func (h *PluginHost) compile(ctx context.Context, wasm []byte) (wazero.CompiledModule, error) {
+	module, err := h.runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebAssembly module: %w", err)
+	}
+	if _, ok := module.ExportedFunctions()["_start"]; !ok {
+		_ = module.Close(ctx)
+		return nil, errors.New("module is not a WASI command: it exports no _start function")
+	}
+	return module, nil
+}
+
+// SetPluginHost registers the plugins of a host's directory as extractors.
+// Plugins that fail to compile are logged and skipped.
+func (s *Service) SetPluginHost(ctx context.Context, host *PluginHost) error {
+	s.plugins = host
+
+	paths, err := filepath.Glob(filepath.Join(host.dir, "*"+pluginExt))
+	if err != nil {
+		return fmt.Errorf("failed to list plugins: %w", err)
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), pluginExt)
+		if !pluginName.MatchString(name) {
+			log.Printf("Skipping plugin %s: %v", path, ErrInvalidPluginName)
+			continue
+		}
+
+		wasm, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Skipping plugin %s: %v", path, err)
+			continue
+		}
+
+		module, err := host.compile(ctx, wasm)
+		if err != nil {
+			log.Printf("Skipping plugin %s: %v", path, err)
+			continue
+		}
+
+		s.RegisterExtractor(SourceWASMPrefix+name, &wasmExtractor{service: s, host: host, name: name, module: module, digest: sha256.Sum256(wasm)})
+		log.Printf("Loaded plugin %s", name)
+	}
+
+	return nil
+}
+
+// InstallPlugin compiles a plugin, stores it in the plugin directory and
+// registers it, replacing any plugin of the same name. It returns the
+// source type feeds use to select it.
+func (s *Service) InstallPlugin(ctx context.Context, name string, wasm []byte) (string, error) {
+	if s.plugins == nil {
+		return "", ErrPluginsDisabled
+	}
+	if !pluginName.MatchString(name) {
+		return "", ErrInvalidPluginName
+	}
+
+	module, err := s.plugins.compile(ctx, wasm)
+	if err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so that a failed upload never leaves
+	// a truncated plugin behind
+	path := filepath.Join(s.plugins.dir, name+pluginExt)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, wasm, pluginFilePerm); err != nil {
+		_ = module.Close(ctx)
+		return "", fmt.Errorf("failed to write plugin: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		_ = module.Close(ctx)
+		return "", fmt.Errorf("failed to write plugin: %w", err)
+	}
+
+	sourceType := SourceWASMPrefix + name
+	extractor := &wasmExtractor{service: s, host: s.plugins, name: name, module: module, digest: sha256.Sum256(wasm)}
+	s.extractorsMu.Lock()
+	previous, _ := s.extractors[sourceType].(*wasmExtractor)
+	s.extractors[sourceType] = extractor
+	s.extractorsMu.Unlock()
+
+	// Release the replaced version. The runtime shares compiled modules of
+	// the same code, so reinstalling a plugin unchanged keeps it. A refresh
+	// still running the replaced version fails and uses the new one next time.
+	if previous != nil && previous.digest != extractor.digest {
+		_ = previous.module.Close(ctx)
+	}
+	return sourceType, nil
+}
+
+// Plugins returns the names of the installed plugins, sorted
+func (s *Service) Plugins() []string {
+	var names []string
+	for _, sourceType := range s.SourceTypes() {
+		if name, ok := strings.CutPrefix(sourceType, SourceWASMPrefix); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// PluginsEnabled reports whether plugins can be installed
+func (s *Service) PluginsEnabled() bool {
+	return s.plugins != nil
+}
+
+// wasmExtractor extracts items by running a plugin on each page
+type wasmExtractor struct {
+	service *Service
+	host    *PluginHost
+	name    string
+	module  wazero.CompiledModule
+	// digest identifies the code of the module
+	digest [sha256.Size]byte
+}
+
+// pluginItem is an item as written by a plugin
+type pluginItem struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Description string `json:"description"`
+	// Date is RFC 3339, or any of the layouts date selectors understand
+	Date string `json:"date"`
+}
+
+func (e *wasmExtractor) Validate(feed db.Feed) error {
+	return nil
+}
+
+func (e *wasmExtractor) Extract(feed db.Feed, page Page) ([]ExtractedItem, error) {
+	output, err := e.run(page)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []pluginItem
+	if err := json.Unmarshal(output, &written); err != nil {
+		return nil, fmt.Errorf("plugin %s wrote invalid JSON: %w", e.name, err)
+	}
+
+	items := make([]ExtractedItem, 0, len(written))
+	for _, w := range written {
+		item := ExtractedItem{
+			Title:       plainText(w.Title),
+			Link:        strings.TrimSpace(w.Link),
+			Description: e.service.cleanDescription(feed, w.Description, page.URL),
+		}
+
+		if item.Link != "" {
+			if parsedLink, err := url.Parse(item.Link); err == nil {
+				item.Link = page.URL.ResolveReference(parsedLink).String()
+			}
+		}
+
+		if date, err := time.Parse(time.RFC3339, w.Date); err == nil {
+			item.Date = date
+		} else if w.Date != "" {
+			item.Date = ParseItemDate(strings.TrimSpace(w.Date))
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// run runs the plugin on a page and returns what it wrote to stdout
+func (e *wasmExtractor) run(page Page) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.host.limits.Timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxPluginOutput}
+	stderr := &limitedBuffer{limit: maxPluginStderr}
+
+	// An empty name keeps the instance out of the runtime's namespace, so
+	// that the plugin can run for several feeds at once
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(e.name, page.URL.String()).
+		WithStdin(bytes.NewReader(page.Body)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithSysWalltime()
+
+	module, err := e.host.runtime.InstantiateModule(ctx, e.module, config)
+	if module != nil {
+		_ = module.Close(ctx)
+	}
+
+	var exitErr *sys.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("plugin %s timed out after %s", e.name, e.host.limits.Timeout)
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 0:
+	case err != nil:
+		if msg := strings.TrimSpace(stderr.buf.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", e.name, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", e.name, err)
+	}
+
+	if stdout.truncated {
+		return nil, fmt.Errorf("plugin %s wrote more than %d bytes", e.name, maxPluginOutput)
+	}
+
+	return stdout.buf.Bytes(), nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and drops the
+// rest, so that a plugin cannot exhaust the memory of the host
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
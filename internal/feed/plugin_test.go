@@ -0,0 +1,194 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The plugins in testdata/plugins are built from the .wat files next to them
+
+func newTestPluginService(t *testing.T, q Querier, limits PluginLimits) *Service {
+	t.Helper()
+
+	host, err := NewPluginHost(context.Background(), t.TempDir(), limits)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = host.Close(context.Background()) })
+
+	svc := NewService(q)
+	require.NoError(t, svc.SetPluginHost(context.Background(), host))
+	return svc
+}
+
+func installTestPlugin(t *testing.T, svc *Service, name string) string {
+	t.Helper()
+
+	wasm, err := os.ReadFile(filepath.Join("testdata", "plugins", name+".wasm"))
+	require.NoError(t, err)
+
+	sourceType, err := svc.InstallPlugin(context.Background(), name, wasm)
+	require.NoError(t, err)
+	return sourceType
+}
+
+func TestPluginExtract(t *testing.T) {
+	svc := newTestPluginService(t, &mockQueries{}, PluginLimits{MemoryMB: 16, Timeout: 5 * time.Second})
+	sourceType := installTestPlugin(t, svc, "echo")
+	assert.Equal(t, "wasm:echo", sourceType)
+	assert.Equal(t, []string{"echo"}, svc.Plugins())
+	assert.Contains(t, svc.SourceTypes(), "wasm:echo")
+
+	pageURL, _ := url.Parse("https://example.com/page")
+	items, err := svc.Extract(db.Feed{SourceType: sourceType}, Page{URL: pageURL, Body: []byte("Hello plugin")})
+	assert.NoError(t, err)
+	assert.Equal(t, []ExtractedItem{{Title: "Hello plugin", Link: "https://example.com/echo"}}, items)
+}
+
+func TestPluginExtractCleansDescriptions(t *testing.T) {
+	svc := newTestPluginService(t, &mockQueries{}, PluginLimits{MemoryMB: 16, Timeout: 5 * time.Second})
+	sourceType := installTestPlugin(t, svc, "echo")
+
+	// The echo plugin writes the page into its JSON output as is, so the
+	// page can add a description to the item
+	body := `Hello","description":"<p>Story <img src=\"/a.png\"></p><div class=\"ad\">Buy</div><script>x()</script>`
+
+	pageURL, _ := url.Parse("https://example.com/news/page")
+	items, err := svc.Extract(db.Feed{
+		SourceType:      sourceType,
+		RemoveSelectors: sql.NullString{String: ".ad", Valid: true},
+	}, Page{URL: pageURL, Body: []byte(body)})
+	assert.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Hello", items[0].Title)
+	assert.Equal(t, `<p>Story <img src="https://example.com/a.png"/></p>`, items[0].Description)
+}
+
+func TestPluginExtractPlainTextTitles(t *testing.T) {
+	svc := newTestPluginService(t, &mockQueries{}, PluginLimits{MemoryMB: 16, Timeout: 5 * time.Second})
+	sourceType := installTestPlugin(t, svc, "echo")
+
+	pageURL, _ := url.Parse("https://example.com/page")
+	items, err := svc.Extract(db.Feed{SourceType: sourceType}, Page{URL: pageURL, Body: []byte(`<b>First</b>   &amp; best`)})
+	assert.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "First & best", items[0].Title)
+}
+
+func TestInstallPluginClosesReplacedModule(t *testing.T) {
+	svc := newTestPluginService(t, &mockQueries{}, PluginLimits{MemoryMB: 16, Timeout: 5 * time.Second})
+	sourceType := installTestPlugin(t, svc, "echo")
+	pageURL, _ := url.Parse("https://example.com/page")
+	page := Page{URL: pageURL, Body: []byte("Hello")}
+
+	// Reinstalling the same code keeps the plugin working
+	installTestPlugin(t, svc, "echo")
+	extractor, err := svc.Extractor(db.Feed{SourceType: sourceType})
+	require.NoError(t, err)
+	_, err = extractor.Extract(db.Feed{SourceType: sourceType}, page)
+	assert.NoError(t, err)
+
+	// Replacing it with other code releases the previous module
+	grow, err := os.ReadFile(filepath.Join("testdata", "plugins", "grow.wasm"))
+	require.NoError(t, err)
+	_, err = svc.InstallPlugin(context.Background(), "echo", grow)
+	require.NoError(t, err)
+	_, err = extractor.Extract(db.Feed{SourceType: sourceType}, page)
+	assert.Error(t, err)
+}
+
+func TestPluginTimeout(t *testing.T) {
+	svc := newTestPluginService(t, &mockQueries{}, PluginLimits{MemoryMB: 16, Timeout: 100 * time.Millisecond})
+	sourceType := installTestPlugin(t, svc, "loop")
+
+	pageURL, _ := url.Parse("https://example.com/page")
+	start := time.Now()
+	_, err := svc.Extract(db.Feed{SourceType: sourceType}, Page{URL: pageURL})
+	assert.ErrorContains(t, err, "plugin loop timed out after 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestPluginMemoryLimit(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/page")
+
+	// 1000 more pages fit in 128 MiB but not in 16 MiB
+	svc := newTestPluginService(t, &mockQueries{}, PluginLimits{MemoryMB: 128, Timeout: 5 * time.Second})
+	_, err := svc.Extract(db.Feed{SourceType: installTestPlugin(t, svc, "grow")}, Page{URL: pageURL})
+	assert.ErrorContains(t, err, "invalid JSON")
+
+	svc = newTestPluginService(t, &mockQueries{}, PluginLimits{MemoryMB: 16, Timeout: 5 * time.Second})
+	_, err = svc.Extract(db.Feed{SourceType: installTestPlugin(t, svc, "grow")}, Page{URL: pageURL})
+	assert.ErrorContains(t, err, "plugin grow failed")
+	assert.ErrorContains(t, err, "unreachable")
+}
+
+func TestInstallPluginRejectsInvalidModules(t *testing.T) {
+	svc := newTestPluginService(t, &mockQueries{}, PluginLimits{MemoryMB: 16, Timeout: time.Second})
+	wasm, err := os.ReadFile(filepath.Join("testdata", "plugins", "echo.wasm"))
+	require.NoError(t, err)
+
+	_, err = svc.InstallPlugin(context.Background(), "../echo", wasm)
+	assert.ErrorIs(t, err, ErrInvalidPluginName)
+
+	_, err = svc.InstallPlugin(context.Background(), "broken", []byte("not wasm"))
+	assert.ErrorContains(t, err, "invalid WebAssembly module")
+
+	assert.Empty(t, svc.Plugins())
+}
+
+func TestInstallPluginWithoutHost(t *testing.T) {
+	_, err := NewService(&mockQueries{}).InstallPlugin(context.Background(), "echo", nil)
+	assert.ErrorIs(t, err, ErrPluginsDisabled)
+}
+
+func TestSetPluginHostLoadsDirectory(t *testing.T) {
+	dir := t.TempDir()
+	wasm, err := os.ReadFile(filepath.Join("testdata", "plugins", "echo.wasm"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "echo.wasm"), wasm, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.wasm"), []byte("not wasm"), 0600))
+
+	host, err := NewPluginHost(context.Background(), dir, PluginLimits{MemoryMB: 16, Timeout: time.Second})
+	require.NoError(t, err)
+	defer func() { _ = host.Close(context.Background()) }()
+
+	svc := NewService(&mockQueries{})
+	require.NoError(t, svc.SetPluginHost(context.Background(), host))
+	assert.Equal(t, []string{"echo"}, svc.Plugins())
+}
+
+func TestRefreshFeedWithPlugin(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "Plain text page")
+	}))
+	defer ts.Close()
+
+	var upsertedItems []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			upsertedItems = append(upsertedItems, params)
+			return []int64{int64(len(upsertedItems))}, nil
+		},
+	}
+
+	svc := newTestPluginService(t, mockQ, PluginLimits{MemoryMB: 16, Timeout: 5 * time.Second})
+	sourceType := installTestPlugin(t, svc, "echo")
+
+	err := svc.RefreshFeed(context.Background(), db.Feed{ID: 1, Url: ts.URL, SourceType: sourceType})
+	assert.NoError(t, err)
+
+	if assert.Len(t, upsertedItems, 1) {
+		assert.Equal(t, "Plain text page", upsertedItems[0].Title)
+		assert.Equal(t, "https://example.com/echo", upsertedItems[0].Link)
+	}
+}
@@ -0,0 +1,27 @@
+package feed
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ParseRemoveSelectors reads one CSS selector per line, ignoring blank lines
+func ParseRemoveSelectors(text string) []string {
+	var selectors []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			selectors = append(selectors, line)
+		}
+	}
+	return selectors
+}
+
+// RemoveElements deletes the descendants of a selection matching any of the
+// selectors, e.g. ads, share buttons or "read more" links caught by the
+// description selector
+func RemoveElements(sel *goquery.Selection, selectors []string) {
+	for _, selector := range selectors {
+		sel.Find(selector).Remove()
+	}
+}
@@ -0,0 +1,67 @@
+package feed
+
+import (
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemoveSelectors(t *testing.T) {
+	assert.Equal(t, []string{".ad", "a.read-more"}, ParseRemoveSelectors("  .ad\n\n a.read-more \n"))
+	assert.Nil(t, ParseRemoveSelectors(""))
+}
+
+func TestRemoveElements(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`
+		<div class="item"><p>Content</p><div class="ad">Ad</div><a class="read-more" href="/more">Read more</a></div>
+		<div class="ad">Outside</div>
+	`))
+	assert.NoError(t, err)
+
+	sel := doc.Find(".item")
+	RemoveElements(sel, []string{".ad", "a.read-more"})
+
+	html, err := sel.Html()
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>Content</p>", html)
+	// Only the item subtree is cleaned
+	assert.Equal(t, 1, doc.Find(".ad").Length())
+}
+
+func TestExtractItemsRemovesElementsFromDescriptionOnly(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`
+		<div class="item">
+			<a class="link" href="/post">Post</a>
+			<div class="meta"><span class="date">2025-01-02</span></div>
+			<p>Content</p>
+		</div>
+	`))
+	assert.NoError(t, err)
+
+	base, err := url.Parse("https://example.com/")
+	assert.NoError(t, err)
+
+	svc := NewService(&mockQueries{})
+	items := svc.ExtractItems(db.Feed{
+		ItemSelector:    sql.NullString{String: ".item", Valid: true},
+		TitleSelector:   sql.NullString{String: ".link", Valid: true},
+		LinkSelector:    sql.NullString{String: ".link", Valid: true},
+		DateSelector:    sql.NullString{String: ".date", Valid: true},
+		RemoveSelectors: sql.NullString{String: ".meta\n.link", Valid: true},
+	}, doc, base)
+
+	// The date and link are read although their elements are removed from
+	// the description
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "Post", items[0].Title)
+		assert.Equal(t, "https://example.com/post", items[0].Link)
+		assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), items[0].Date)
+		assert.Equal(t, "<p>Content</p>", items[0].Description)
+	}
+}
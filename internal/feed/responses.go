@@ -0,0 +1,263 @@
+package feed
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	responseDirPerm  = 0755
+	responseFilePerm = 0644
+	// responseExt is the extension of stored responses: gzipped JSON
+	responseExt = ".json.gz"
+	// responseNameLayout names files by fetch time, so that they sort chronologically
+	responseNameLayout = "20060102T150405.000000000Z"
+)
+
+// ErrResponseNotFound is returned when a stored response does not exist
+var ErrResponseNotFound = errors.New("response not found")
+
+// RawResponse is an HTTP response as served by the site, kept to debug feeds
+// whose selectors stopped matching
+type RawResponse struct {
+	URL       string      `json:"url"`
+	Status    int         `json:"status"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body"`
+	FetchedAt time.Time   `json:"fetched_at"`
+}
+
+// StoredResponse is a raw response along with the name it is stored under
+type StoredResponse struct {
+	Name string
+	RawResponse
+}
+
+// ResponseInfo describes a stored response without its body. It is kept in
+// the gzip header of the file, so that responses are listed without being
+// decompressed.
+type ResponseInfo struct {
+	Name      string    `json:"-"`
+	URL       string    `json:"url"`
+	Status    int       `json:"status"`
+	Size      int       `json:"size"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Info returns the description of a stored response
+func (r StoredResponse) Info() ResponseInfo {
+	return ResponseInfo{
+		Name:      r.Name,
+		URL:       r.URL,
+		Status:    r.Status,
+		Size:      len(r.Body),
+		FetchedAt: r.FetchedAt,
+	}
+}
+
+// credentialHeaders are masked before responses are stored: the session
+// cookies of form logins must not be readable from the UI
+var credentialHeaders = []string{"Set-Cookie", "Set-Cookie2", "Authorization", "Proxy-Authorization", "Cookie"}
+
+// redactedValue replaces the values of credential headers
+const redactedValue = "[redacted]"
+
+// redactHeader returns a copy of the header with credentials masked
+func redactHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range credentialHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			masked := make([]string, len(values))
+			for i := range masked {
+				masked[i] = redactedValue
+			}
+			header[http.CanonicalHeaderKey(name)] = masked
+		}
+	}
+	return header
+}
+
+// ResponseStore keeps the last few raw responses of each feed as compressed
+// files, one directory per feed
+type ResponseStore struct {
+	dir  string
+	keep int
+}
+
+// NewResponseStore creates a store under dir keeping keep responses per feed.
+// A store keeping no responses discards everything it is given.
+func NewResponseStore(dir string, keep int) *ResponseStore {
+	return &ResponseStore{dir: dir, keep: keep}
+}
+
+// Save stores a response and deletes the oldest ones beyond the limit
+func (s *ResponseStore) Save(feedID int64, resp RawResponse) error {
+	if s.keep <= 0 {
+		return nil
+	}
+
+	dir := s.feedDir(feedID)
+	if err := os.MkdirAll(dir, responseDirPerm); err != nil {
+		return fmt.Errorf("failed to create response directory: %w", err)
+	}
+
+	resp.Header = redactHeader(resp.Header)
+	name := resp.FetchedAt.UTC().Format(responseNameLayout)
+	if err := writeResponse(filepath.Join(dir, name+responseExt), resp); err != nil {
+		return err
+	}
+
+	names, err := s.names(feedID)
+	if err != nil {
+		return err
+	}
+	for len(names) > s.keep {
+		oldest := names[len(names)-1]
+		if err := os.Remove(filepath.Join(dir, oldest+responseExt)); err != nil {
+			return fmt.Errorf("failed to remove old response: %w", err)
+		}
+		names = names[:len(names)-1]
+	}
+
+	return nil
+}
+
+// List describes the stored responses of a feed, newest first, reading only
+// the headers of their files
+func (s *ResponseStore) List(feedID int64) ([]ResponseInfo, error) {
+	names, err := s.names(feedID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]ResponseInfo, 0, len(names))
+	for _, name := range names {
+		info, err := s.info(feedID, name)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// info reads the description of a stored response from its gzip header
+func (s *ResponseStore) info(feedID int64, name string) (ResponseInfo, error) {
+	f, err := os.Open(filepath.Join(s.feedDir(feedID), name+responseExt))
+	if err != nil {
+		return ResponseInfo{}, fmt.Errorf("failed to open response: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return ResponseInfo{}, fmt.Errorf("failed to decompress response: %w", err)
+	}
+
+	info := ResponseInfo{Name: name}
+	if err := json.Unmarshal(zr.Extra, &info); err != nil {
+		return ResponseInfo{}, fmt.Errorf("failed to read response description: %w", err)
+	}
+	return info, nil
+}
+
+// Load reads a stored response by name
+func (s *ResponseStore) Load(feedID int64, name string) (StoredResponse, error) {
+	// Names come from URLs, so never let them leave the feed directory
+	if name == "" || filepath.Base(name) != name || strings.HasPrefix(name, ".") {
+		return StoredResponse{}, ErrResponseNotFound
+	}
+
+	f, err := os.Open(filepath.Join(s.feedDir(feedID), name+responseExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return StoredResponse{}, ErrResponseNotFound
+	}
+	if err != nil {
+		return StoredResponse{}, fmt.Errorf("failed to open response: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return StoredResponse{}, fmt.Errorf("failed to decompress response: %w", err)
+	}
+
+	stored := StoredResponse{Name: name}
+	if err := json.NewDecoder(zr).Decode(&stored.RawResponse); err != nil {
+		return StoredResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return stored, nil
+}
+
+// DeleteFeed removes every stored response of a feed
+func (s *ResponseStore) DeleteFeed(feedID int64) error {
+	if err := os.RemoveAll(s.feedDir(feedID)); err != nil {
+		return fmt.Errorf("failed to remove responses: %w", err)
+	}
+	return nil
+}
+
+func (s *ResponseStore) feedDir(feedID int64) string {
+	return filepath.Join(s.dir, strconv.FormatInt(feedID, 10))
+}
+
+// names lists the stored responses of a feed, newest first
+func (s *ResponseStore) names(feedID int64) ([]string, error) {
+	entries, err := os.ReadDir(s.feedDir(feedID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list responses: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), responseExt); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	slices.Reverse(names)
+	return names, nil
+}
+
+// writeResponse writes a compressed response to a temporary file first, so
+// that a crash never leaves a truncated file behind
+func writeResponse(path string, resp RawResponse) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, responseFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create response file: %w", err)
+	}
+
+	zw := gzip.NewWriter(f)
+	zw.Extra, err = json.Marshal(StoredResponse{RawResponse: resp}.Info())
+	if err == nil {
+		err = json.NewEncoder(zw).Encode(resp)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to store response: %w", err)
+	}
+	return nil
+}
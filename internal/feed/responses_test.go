@@ -0,0 +1,96 @@
+package feed
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseStoreKeepsNewest(t *testing.T) {
+	store := NewResponseStore(t.TempDir(), 2)
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	for i := range 3 {
+		err := store.Save(1, RawResponse{
+			URL:       "https://example.com",
+			Status:    http.StatusOK,
+			Header:    http.Header{"Content-Type": {"text/html"}},
+			Body:      []byte{byte('a' + i)},
+			FetchedAt: start.Add(time.Duration(i) * time.Hour),
+		})
+		assert.NoError(t, err)
+	}
+
+	responses, err := store.List(1)
+	assert.NoError(t, err)
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "https://example.com", responses[0].URL)
+	assert.Equal(t, http.StatusOK, responses[0].Status)
+	assert.Equal(t, 1, responses[0].Size)
+	assert.True(t, responses[0].FetchedAt.Equal(start.Add(2*time.Hour)))
+	assert.True(t, responses[1].FetchedAt.Equal(start.Add(time.Hour)))
+
+	loaded, err := store.Load(1, responses[1].Name)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(loaded.Body))
+	assert.Equal(t, "text/html", loaded.Header.Get("Content-Type"))
+
+	// Other feeds are unaffected
+	responses, err = store.List(2)
+	assert.NoError(t, err)
+	assert.Empty(t, responses)
+}
+
+func TestResponseStoreMasksCredentials(t *testing.T) {
+	store := NewResponseStore(t.TempDir(), 2)
+
+	err := store.Save(1, RawResponse{
+		URL:    "https://example.com/login",
+		Status: http.StatusOK,
+		Header: http.Header{
+			"Content-Type": {"text/html"},
+			"Set-Cookie":   {"session=secret; Path=/", "csrf=token"},
+		},
+		FetchedAt: time.Now(),
+	})
+	assert.NoError(t, err)
+
+	responses, err := store.List(1)
+	assert.NoError(t, err)
+	loaded, err := store.Load(1, responses[0].Name)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"[redacted]", "[redacted]"}, loaded.Header.Values("Set-Cookie"))
+	assert.Equal(t, "text/html", loaded.Header.Get("Content-Type"))
+}
+
+func TestResponseStoreLoadRejectsPaths(t *testing.T) {
+	store := NewResponseStore(t.TempDir(), 2)
+
+	for _, name := range []string{"", "../1/x", "..", "missing"} {
+		_, err := store.Load(1, name)
+		assert.ErrorIs(t, err, ErrResponseNotFound, name)
+	}
+}
+
+func TestResponseStoreDisabled(t *testing.T) {
+	store := NewResponseStore(t.TempDir(), 0)
+
+	assert.NoError(t, store.Save(1, RawResponse{Status: http.StatusOK, FetchedAt: time.Now()}))
+
+	responses, err := store.List(1)
+	assert.NoError(t, err)
+	assert.Empty(t, responses)
+}
+
+func TestResponseStoreDeleteFeed(t *testing.T) {
+	store := NewResponseStore(t.TempDir(), 2)
+	assert.NoError(t, store.Save(1, RawResponse{Status: http.StatusOK, FetchedAt: time.Now()}))
+
+	assert.NoError(t, store.DeleteFeed(1))
+
+	responses, err := store.List(1)
+	assert.NoError(t, err)
+	assert.Empty(t, responses)
+}
@@ -0,0 +1,121 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// RetentionPolicy limits how many items a feed keeps. Zero values mean no limit.
+type RetentionPolicy struct {
+	MaxItems   int
+	MaxAgeDays int
+}
+
+// EffectiveRetention returns the policy for a feed: its own settings where
+// present, the defaults otherwise
+func EffectiveRetention(feed db.Feed, defaults RetentionPolicy) RetentionPolicy {
+	policy := defaults
+	if feed.RetentionMaxItems.Valid {
+		policy.MaxItems = int(feed.RetentionMaxItems.Int64)
+	}
+	if feed.RetentionMaxAgeDays.Valid {
+		policy.MaxAgeDays = int(feed.RetentionMaxAgeDays.Int64)
+	}
+	return policy
+}
+
+// PruneAllFeeds applies the retention policy of every feed and returns the
+// number of items removed
+func (s *Service) PruneAllFeeds(ctx context.Context, defaults RetentionPolicy) (int64, error) {
+	feeds, err := s.queries.ListFeeds(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	var total int64
+	for _, feed := range feeds {
+		pruned, err := s.PruneFeed(ctx, feed, EffectiveRetention(feed, defaults))
+		if err != nil {
+			log.Printf("Failed to prune feed %d (%s): %v", feed.ID, feed.Name, err)
+			continue
+		}
+		total += pruned
+	}
+
+	return total, nil
+}
+
+// maxTombstones is the number of pruned links kept per feed. Source pages
+// list far fewer items, so older tombstones no longer stop anything.
+const maxTombstones = 1000
+
+// PruneFeed deletes the items of a feed that fall outside the policy. Their
+// links are kept as tombstones, so that items still listed on the source page
+// are not stored again as new ones on the next refresh.
+func (s *Service) PruneFeed(ctx context.Context, feed db.Feed, policy RetentionPolicy) (int64, error) {
+	var pruned int64
+
+	if policy.MaxAgeDays > 0 {
+		cutoff := sql.NullTime{Time: time.Now().UTC().AddDate(0, 0, -policy.MaxAgeDays), Valid: true}
+		err := s.inTx(ctx, func(q Querier) error {
+			if err := q.TombstoneFeedItemsByAge(ctx, db.TombstoneFeedItemsByAgeParams{
+				FeedID:    feed.ID,
+				CreatedAt: cutoff,
+			}); err != nil {
+				return fmt.Errorf("failed to record pruned items: %w", err)
+			}
+			n, err := q.PruneFeedItemsByAge(ctx, db.PruneFeedItemsByAgeParams{
+				FeedID:    feed.ID,
+				CreatedAt: cutoff,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to prune items by age: %w", err)
+			}
+			pruned += n
+			return nil
+		})
+		if err != nil {
+			return pruned, err
+		}
+	}
+
+	if policy.MaxItems > 0 {
+		err := s.inTx(ctx, func(q Querier) error {
+			if err := q.TombstoneFeedItemsByCount(ctx, db.TombstoneFeedItemsByCountParams{
+				FeedID:   feed.ID,
+				MaxItems: int64(policy.MaxItems),
+			}); err != nil {
+				return fmt.Errorf("failed to record pruned items: %w", err)
+			}
+			n, err := q.PruneFeedItemsByCount(ctx, db.PruneFeedItemsByCountParams{
+				FeedID:   feed.ID,
+				MaxItems: int64(policy.MaxItems),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to prune items by count: %w", err)
+			}
+			pruned += n
+			return nil
+		})
+		if err != nil {
+			return pruned, err
+		}
+	}
+
+	if pruned > 0 {
+		if err := s.queries.TrimFeedItemTombstones(ctx, db.TrimFeedItemTombstonesParams{
+			FeedID:        feed.ID,
+			MaxTombstones: maxTombstones,
+		}); err != nil {
+			return pruned, fmt.Errorf("failed to trim pruned links: %w", err)
+		}
+		log.Printf("Feed %d: pruned %d items.", feed.ID, pruned)
+	}
+
+	return pruned, nil
+}
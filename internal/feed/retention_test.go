@@ -0,0 +1,77 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveRetention(t *testing.T) {
+	defaults := RetentionPolicy{MaxItems: 100, MaxAgeDays: 30}
+
+	// Feed without settings uses the defaults
+	assert.Equal(t, defaults, EffectiveRetention(db.Feed{}, defaults))
+
+	// Feed settings override the defaults, 0 meaning no limit
+	policy := EffectiveRetention(db.Feed{
+		RetentionMaxItems:   sql.NullInt64{Int64: 10, Valid: true},
+		RetentionMaxAgeDays: sql.NullInt64{Int64: 0, Valid: true},
+	}, defaults)
+	assert.Equal(t, RetentionPolicy{MaxItems: 10, MaxAgeDays: 0}, policy)
+}
+
+func TestPruneAllFeeds(t *testing.T) {
+	var byAge []db.PruneFeedItemsByAgeParams
+	var byCount []db.PruneFeedItemsByCountParams
+	var tombstoned, trimmed []int64
+	mockQ := &mockQueries{
+		TrimFeedItemTombstonesFn: func(ctx context.Context, arg db.TrimFeedItemTombstonesParams) error {
+			trimmed = append(trimmed, arg.FeedID)
+			return nil
+		},
+		TombstoneFeedItemsByAgeFn: func(ctx context.Context, arg db.TombstoneFeedItemsByAgeParams) error {
+			tombstoned = append(tombstoned, arg.FeedID)
+			return nil
+		},
+		TombstoneFeedItemsByCountFn: func(ctx context.Context, arg db.TombstoneFeedItemsByCountParams) error {
+			tombstoned = append(tombstoned, arg.FeedID)
+			return nil
+		},
+		ListFeedsFn: func(ctx context.Context) ([]db.Feed, error) {
+			return []db.Feed{
+				{ID: 1},
+				{ID: 2, RetentionMaxItems: sql.NullInt64{Int64: 5, Valid: true}},
+			}, nil
+		},
+		PruneFeedItemsByAgeFn: func(ctx context.Context, arg db.PruneFeedItemsByAgeParams) (int64, error) {
+			byAge = append(byAge, arg)
+			return 2, nil
+		},
+		PruneFeedItemsByCountFn: func(ctx context.Context, arg db.PruneFeedItemsByCountParams) (int64, error) {
+			byCount = append(byCount, arg)
+			return 3, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	pruned, err := svc.PruneAllFeeds(context.Background(), RetentionPolicy{MaxAgeDays: 7})
+	assert.NoError(t, err)
+
+	// Both feeds are pruned by age, only feed 2 by count
+	assert.Equal(t, int64(2+2+3), pruned)
+	assert.Len(t, byAge, 2)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -7), byAge[0].CreatedAt.Time, time.Minute)
+	assert.Len(t, byCount, 1)
+	assert.Equal(t, db.PruneFeedItemsByCountParams{FeedID: 2, MaxItems: 5}, byCount[0])
+
+	// The links of pruned items are recorded before they are deleted
+	assert.Equal(t, []int64{1, 2, 2}, tombstoned)
+
+	// And the tombstones of each pruned feed are capped
+	assert.Equal(t, []int64{1, 2}, trimmed)
+}
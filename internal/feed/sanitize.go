@@ -0,0 +1,107 @@
+package feed
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// Description formats
+const (
+	// FormatHTML keeps the description as HTML, restricted to the allowlist
+	FormatHTML = "html"
+	// FormatText strips the description down to plain text
+	FormatText = "text"
+)
+
+// NormalizeDescriptionFormat returns a known description format, defaulting to FormatHTML
+func NormalizeDescriptionFormat(format string) string {
+	if format == FormatText {
+		return FormatText
+	}
+	return FormatHTML
+}
+
+// trackingPixelSelector matches images too small to be anything but beacons
+const trackingPixelSelector = `img[width="0"], img[width="1"], img[height="0"], img[height="1"]`
+
+// allowedAttributes are the attributes kept on allowlisted elements
+var allowedAttributes = map[string][]string{
+	"a":      {"href", "title"},
+	"img":    {"src", "srcset", "alt", "title", "width", "height"},
+	"source": {"src", "srcset", "type", "media"},
+	"td":     {"colspan", "rowspan"},
+	"th":     {"colspan", "rowspan"},
+}
+
+// Sanitizer cleans scraped descriptions before they are stored, so that
+// scripts, event handlers, iframes and tracking pixels of the source site
+// are never republished
+type Sanitizer struct {
+	policy *bluemonday.Policy
+}
+
+// NewSanitizer creates a sanitizer allowing the given elements, or a
+// sensible set of formatting, link, image and table elements when none are given
+func NewSanitizer(allowedElements []string) *Sanitizer {
+	if len(allowedElements) == 0 {
+		policy := bluemonday.UGCPolicy()
+		// Keep responsive images, whose URLs are absolutized at ingest
+		policy.AllowElements("picture")
+		policy.AllowAttrs("srcset").OnElements("img", "source")
+		policy.AllowAttrs("type", "media").OnElements("source")
+		return &Sanitizer{policy: policy}
+	}
+
+	policy := bluemonday.NewPolicy()
+	policy.AllowStandardURLs()
+	policy.AllowElements(allowedElements...)
+
+	// Allowing attributes also allows their element, so only add the
+	// attributes of elements that are on the list
+	for _, element := range allowedElements {
+		if attrs, ok := allowedAttributes[element]; ok {
+			policy.AllowAttrs(attrs...).OnElements(element)
+		}
+	}
+
+	return &Sanitizer{policy: policy}
+}
+
+// Sanitize cleans an HTML fragment and renders it in the given format
+func (s *Sanitizer) Sanitize(fragment, format string) string {
+	if fragment == "" {
+		return ""
+	}
+
+	if format == FormatText {
+		return htmlToText(fragment)
+	}
+
+	return strings.TrimSpace(s.policy.Sanitize(removeTrackingPixels(fragment)))
+}
+
+// removeTrackingPixels drops 0 and 1 pixel images from an HTML fragment
+func removeTrackingPixels(fragment string) string {
+	if !strings.Contains(fragment, "<img") {
+		return fragment
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return fragment
+	}
+
+	pixels := doc.Find(trackingPixelSelector)
+	if pixels.Length() == 0 {
+		return fragment
+	}
+	pixels.Remove()
+
+	cleaned, err := doc.Find("body").Html()
+	if err != nil {
+		return fragment
+	}
+	return cleaned
+}
@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	s := NewSanitizer(nil)
+
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{
+			name:     "keeps formatting and links",
+			fragment: `<p>Hello <b>world</b> <a href="https://example.com">link</a></p>`,
+			want:     `<p>Hello <b>world</b> <a href="https://example.com" rel="nofollow">link</a></p>`,
+		},
+		{
+			name:     "removes scripts",
+			fragment: `<p>Hello</p><script>alert(1)</script>`,
+			want:     `<p>Hello</p>`,
+		},
+		{
+			name:     "removes event handlers",
+			fragment: `<p onclick="track()">Hello</p>`,
+			want:     `<p>Hello</p>`,
+		},
+		{
+			name:     "removes iframes",
+			fragment: `<p>Hello</p><iframe src="https://ads.example.com"></iframe>`,
+			want:     `<p>Hello</p>`,
+		},
+		{
+			name:     "removes javascript links",
+			fragment: `<a href="javascript:alert(1)">link</a>`,
+			want:     `link`,
+		},
+		{
+			name:     "keeps responsive images",
+			fragment: `<img src="https://example.com/a.png" srcset="https://example.com/a.png 1x, https://example.com/b.png 2x">`,
+			want:     `<img src="https://example.com/a.png" srcset="https://example.com/a.png 1x, https://example.com/b.png 2x">`,
+		},
+		{
+			name:     "removes tracking pixels",
+			fragment: `<p>Hello</p><img src="https://t.example.com/p.gif" width="1" height="1"><img src="https://example.com/a.png" alt="photo"/>`,
+			want:     `<p>Hello</p><img src="https://example.com/a.png" alt="photo"/>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.Sanitize(tt.fragment, FormatHTML))
+		})
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	s := NewSanitizer(nil)
+
+	got := s.Sanitize(`<p>Hello <b>world</b></p><script>alert(1)</script><p>Second</p>`, FormatText)
+	assert.Equal(t, "Hello world\nSecond", got)
+}
+
+func TestSanitizeAllowedElements(t *testing.T) {
+	s := NewSanitizer([]string{"p", "a"})
+
+	got := s.Sanitize(`<p>Hello <b>world</b> <a href="https://example.com" onclick="x()">link</a></p><img src="https://example.com/a.png">`, FormatHTML)
+	assert.Equal(t, `<p>Hello world <a href="https://example.com" rel="nofollow">link</a></p>`, got)
+}
+
+func TestNormalizeDescriptionFormat(t *testing.T) {
+	assert.Equal(t, FormatText, NormalizeDescriptionFormat("text"))
+	assert.Equal(t, FormatHTML, NormalizeDescriptionFormat("html"))
+	assert.Equal(t, FormatHTML, NormalizeDescriptionFormat(""))
+}
@@ -3,15 +3,16 @@ package feed
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
-	"strings"
+	"sync"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/notify"
 )
 
 // Querier defines the interface for database operations needed by the service
@@ -24,23 +25,101 @@ type Querier interface {
 	CreateFeed(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error)
 	UpdateFeed(ctx context.Context, arg db.UpdateFeedParams) error
 	UpdateFeedLastRefreshedAt(ctx context.Context, arg db.UpdateFeedLastRefreshedAtParams) error
+	UpdateFeedSnapshot(ctx context.Context, arg db.UpdateFeedSnapshotParams) error
+	UpdateFeedHealth(ctx context.Context, arg db.UpdateFeedHealthParams) error
+	GetFeedAuth(ctx context.Context, feedID int64) (db.FeedAuth, error)
+	UpdateFeedAuthCookies(ctx context.Context, arg db.UpdateFeedAuthCookiesParams) error
 	DeleteFeed(ctx context.Context, id int64) error
 	ListFeedItems(ctx context.Context, feedID int64) ([]db.FeedItem, error)
 	UpsertFeedItem(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error)
 	DeleteItemsByFeedID(ctx context.Context, feedID int64) error
+	GetFeedItemByLink(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error)
+	UpdateFeedItemContent(ctx context.Context, arg db.UpdateFeedItemContentParams) error
+	CreateFeedItemRevision(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error)
+	ListFeedFilterRules(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error)
+	TombstoneFeedItemsByAge(ctx context.Context, arg db.TombstoneFeedItemsByAgeParams) error
+	TombstoneFeedItemsByCount(ctx context.Context, arg db.TombstoneFeedItemsByCountParams) error
+	PruneFeedItemsByAge(ctx context.Context, arg db.PruneFeedItemsByAgeParams) (int64, error)
+	PruneFeedItemsByCount(ctx context.Context, arg db.PruneFeedItemsByCountParams) (int64, error)
+	TrimFeedItemTombstones(ctx context.Context, arg db.TrimFeedItemTombstonesParams) error
+}
+
+// transactor is implemented by queries that can run writes in a transaction,
+// such as a db.Store
+type transactor interface {
+	InTx(ctx context.Context, fn func(q *db.Queries) error) error
 }
 
 type Service struct {
-	queries Querier
+	queries   Querier
+	sanitizer *Sanitizer
+	responses *ResponseStore
+	notifier  notify.Notifier
+	location  *time.Location
+	plugins   *PluginHost
+	// extractors turn fetched pages into items, by source type. Installing
+	// a plugin adds one while feeds refresh.
+	extractors   map[string]Extractor
+	extractorsMu sync.RWMutex
 }
 
 func NewService(q Querier) *Service {
-	return &Service{queries: q}
+	s := &Service{
+		queries:    q,
+		sanitizer:  NewSanitizer(nil),
+		location:   time.UTC,
+		extractors: make(map[string]Extractor),
+	}
+	s.RegisterExtractor(SourceCSS, cssExtractor{service: s})
+	s.RegisterExtractor(SourcePattern, patternExtractor{service: s})
+	return s
 }
 
+// inTx runs writes that must happen together in a transaction, or directly
+// on queries that do not support transactions
+func (s *Service) inTx(ctx context.Context, fn func(q Querier) error) error {
+	if t, ok := s.queries.(transactor); ok {
+		return t.InTx(ctx, func(q *db.Queries) error { return fn(q) })
+	}
+	return fn(s.queries)
+}
+
+// SetLocation sets the time zone URL date placeholders are resolved in
+func (s *Service) SetLocation(loc *time.Location) {
+	s.location = loc
+}
+
+// SetSanitizer replaces the sanitizer applied to item descriptions at ingest
+func (s *Service) SetSanitizer(sanitizer *Sanitizer) {
+	s.sanitizer = sanitizer
+}
+
+// SetResponseStore enables keeping the raw responses fetched for each feed
+func (s *Service) SetResponseStore(store *ResponseStore) {
+	s.responses = store
+}
+
+// SetNotifier sets the channels told about feeds breaking and recovering
+func (s *Service) SetNotifier(notifier notify.Notifier) {
+	s.notifier = notifier
+}
+
+// now returns the current time in the service's time zone
+func (s *Service) now() time.Time {
+	return time.Now().In(s.location)
+}
+
+// Responses returns the store of raw responses, or nil if they are not kept
+func (s *Service) Responses() *ResponseStore {
+	return s.responses
+}
+
+// RefreshInterval is how often the scheduler refreshes all feeds
+const RefreshInterval = time.Hour
+
 // StartScheduler starts a background goroutine that refreshes all feeds every hour
 func (s *Service) StartScheduler() {
-	ticker := time.NewTicker(1 * time.Hour)
+	ticker := time.NewTicker(RefreshInterval)
 	go func() {
 		defer ticker.Stop()
 
@@ -81,128 +160,202 @@ func (s *Service) RefreshAllFeeds() {
 
 // RefreshFeed fetches and updates a single feed
 func (s *Service) RefreshFeed(ctx context.Context, feed db.Feed) error {
-	if !feed.ItemSelector.Valid {
-		return fmt.Errorf("feed %d is missing item selector", feed.ID)
-	}
-
-	if !feed.TitleSelector.Valid {
-		return fmt.Errorf("feed %d is missing title selector", feed.ID)
-	}
-
-	if !feed.LinkSelector.Valid {
-		return fmt.Errorf("feed %d is missing link selector", feed.ID)
-	}
-
-	// Fetch the webpage
-	resp, err := http.Get(feed.Url)
-	if err != nil {
-		return fmt.Errorf("failed to fetch URL: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("failed to close response body: %v", err)
+	// Monitored regions only need the region selector, list feeds whatever
+	// their extractor needs
+	var extractor Extractor
+	var expressions *Expressions
+	if feed.Mode == ModeMonitor {
+		if !feed.ItemSelector.Valid {
+			return fmt.Errorf("feed %d is missing item selector", feed.ID)
+		}
+	} else {
+		var err error
+		if extractor, err = s.Extractor(feed); err != nil {
+			return fmt.Errorf("feed %d: %w", feed.ID, err)
+		}
+		if err := extractor.Validate(feed); err != nil {
+			return err
+		}
+		if expressions, err = CompileExpressions(feed.FilterExpression.String, feed.MapExpression.String); err != nil {
+			return fmt.Errorf("feed %d has invalid expressions: %w", feed.ID, err)
 		}
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP error: %d", resp.StatusCode)
 	}
 
-	// Parse HTML
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	// Fetch the webpages the feed is built from
+	pages, err := s.fetchPages(ctx, feed)
 	if err != nil {
-		return fmt.Errorf("failed to parse HTML: %w", err)
+		return err
 	}
 
-	// Extract items using selectors
-	var newItemsCount int
-	var count []int64
-	doc.Find(feed.ItemSelector.String).Each(func(i int, sel *goquery.Selection) {
-		title := strings.TrimSpace(sel.Find(feed.TitleSelector.String).Text())
-		link, exists := sel.Find(feed.LinkSelector.String).Attr("href")
-		if !exists {
-			link = strings.TrimSpace(sel.Find(feed.LinkSelector.String).Text())
+	if feed.Mode == ModeMonitor {
+		matched := pages[0].Doc.Find(feed.ItemSelector.String).Length()
+		if err := s.checkHealth(ctx, feed, matched); err != nil {
+			return err
 		}
 
-		var description string
-		if feed.DescriptionSelector.Valid {
-			description, err = sel.Find(feed.DescriptionSelector.String).Html()
-			if err != nil {
-				log.Printf("Failed to get feed item description: %v", err)
-			}
-		} else {
-			description, err = sel.Html()
-			if err != nil {
-				log.Printf("Failed to get feed item description: %v", err)
-			}
+		if err := s.refreshMonitoredRegion(ctx, feed, pages[0]); err != nil {
+			return err
+		}
+	} else {
+		items, matched, err := s.extractPages(feed, extractor, pages)
+		if err != nil {
+			return err
+		}
+		if err := s.checkHealth(ctx, feed, matched); err != nil {
+			return err
 		}
 
-		var date time.Time
-		if feed.DateSelector.Valid && feed.DateSelector.String != "" {
-			// Extract the date string from the HTML
-			dateStr := strings.TrimSpace(sel.Find(feed.DateSelector.String).Text())
-			// fmt.Println("dateStr:", dateStr) // Removed debug print
+		rules, err := s.queries.ListFeedFilterRules(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load filter rules: %w", err)
+		}
 
-			// Strip weekday in parentheses if present, e.g., "2025-08-09 (土)" → "2025-08-09"
-			if idx := strings.Index(dateStr, " "); idx != -1 {
-				dateStr = dateStr[:idx]
-			}
+		filter, err := FilterFromRules(rules)
+		if err != nil {
+			return fmt.Errorf("invalid filter rules: %w", err)
+		}
 
-			var err error
-			// Try common formats
-			layouts := []string{
-				"2006-01-02", // YYYY-MM-DD
-				"2006/01/02", // YYYY/MM/DD
-				"02-01-2006", // DD-MM-YYYY
-				time.RFC1123, // Mon, 02 Jan 2006 15:04:05 MST
-				time.RFC3339, // 2006-01-02T15:04:05Z07:00
-			}
+		s.refreshListItems(ctx, feed, pages[0].URL, items, expressions, filter)
+	}
 
-			for _, layout := range layouts {
-				date, err = time.Parse(layout, dateStr)
-				if err == nil {
-					break
-				}
-			}
+	// Update the feed's last_refreshed_at timestamp
+	if err := s.queries.UpdateFeedLastRefreshedAt(ctx, db.UpdateFeedLastRefreshedAtParams{
+		ID:              feed.ID,
+		LastRefreshedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	}); err != nil {
+		log.Printf("Failed to update last_refreshed_at for feed %d: %v", feed.ID, err)
+	}
 
-			if err != nil {
-				log.Printf("Failed to parse date '%s': %v", dateStr, err)
-			}
+	return nil
+}
+
+// refreshListItems stores the new or changed items that pass the feed's
+// expressions and filter rules, as rewritten by its mapping expression. base
+// is the URL of the first page, which stored descriptions are resolved against.
+func (s *Service) refreshListItems(ctx context.Context, feed db.Feed, base *url.URL, items []ExtractedItem, expressions *Expressions, filter Filter) {
+	var newItemsCount, revisedItemsCount, filteredItemsCount int
+	for _, item := range items {
+		check := CheckItem(item, expressions, filter)
+		item := check.Item
+		switch {
+		case check.Err != nil:
+			log.Printf("Feed %d: skipping item %s: %v", feed.ID, item.Link, check.Err)
+		case check.DroppedByExpression:
+			log.Printf("Feed %d: skipping item %s: fails filter expression", feed.ID, item.Link)
+		case check.Rule != "":
+			log.Printf("Feed %d: skipping item %s: fails rule %q", feed.ID, item.Link, check.Rule)
+		}
+		if !check.Kept() {
+			filteredItemsCount++
+			continue
 		}
 
-		// Make link absolute if it's relative
-		if link != "" {
-			parsedLink, err := url.Parse(link)
-			if err == nil {
-				link = resp.Request.URL.ResolveReference(parsedLink).String()
-			}
+		params := db.UpsertFeedItemParams{
+			FeedID:      feed.ID,
+			Title:       item.Title,
+			Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
+			Link:        item.Link,
+			Date:        sql.NullTime{Time: item.Date, Valid: !item.Date.IsZero()},
 		}
 
 		// Upsert the item (will update if exists, insert if new)
-		count, err = s.queries.UpsertFeedItem(ctx, db.UpsertFeedItemParams{
-			FeedID:      feed.ID,
-			Title:       title,
-			Description: sql.NullString{String: description, Valid: description != ""}, // START MODIFICATION: db.NewNullString might not be available or imported? Checking source...
-			Link:        link,
-			Date:        sql.NullTime{Time: date, Valid: !date.IsZero()}, // START MODIFICATION
-		})
+		count, err := s.queries.UpsertFeedItem(ctx, params)
 
 		if err != nil {
 			log.Printf("Failed to upsert feed item: %v", err)
 		} else if len(count) > 0 {
 			newItemsCount++
+		} else {
+			revised, err := s.reviseFeedItem(ctx, feed, base, params)
+			if err != nil {
+				log.Printf("Failed to revise feed item %s: %v", item.Link, err)
+			} else if revised {
+				revisedItemsCount++
+			}
 		}
+	}
+
+	log.Printf("Feed %d: processed items. Updated %d new items, revised %d items, filtered out %d items.", feed.ID, newItemsCount, revisedItemsCount, filteredItemsCount)
+}
+
+// saveResponse stores a fetched response if raw responses are kept
+func (s *Service) saveResponse(feedID int64, resp *http.Response, body []byte) {
+	if s.responses == nil {
+		return
+	}
+
+	if err := s.responses.Save(feedID, RawResponse{
+		URL:       resp.Request.URL.String(),
+		Status:    resp.StatusCode,
+		Header:    resp.Header,
+		Body:      body,
+		FetchedAt: time.Now().UTC(),
+	}); err != nil {
+		log.Printf("Failed to store response for feed %d: %v", feedID, err)
+	}
+}
+
+// reviseFeedItem compares freshly extracted content with the stored item
+// and, when the title, description or date changed, keeps the previous
+// version in feed_item_revisions before overwriting it. The stored
+// description is first cleaned up again, so that items stored before a
+// change to the description pipeline (sanitizing, absolutized URLs, removed
+// elements) are brought up to date without a revision.
+func (s *Service) reviseFeedItem(ctx context.Context, feed db.Feed, base *url.URL, params db.UpsertFeedItemParams) (bool, error) {
+	existing, err := s.queries.GetFeedItemByLink(ctx, db.GetFeedItemByLinkParams{
+		FeedID: params.FeedID,
+		Link:   params.Link,
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Not inserted because retention removed it: it stays removed
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load existing item: %w", err)
+	}
 
-	log.Printf("Feed %d: processed items. Updated %d new items.", feed.ID, newItemsCount)
+	cleaned := existing.Description
+	if cleaned.Valid {
+		description := s.cleanDescription(feed, cleaned.String, base)
+		cleaned = sql.NullString{String: description, Valid: description != ""}
+	}
 
-	// Update the feed's last_refreshed_at timestamp
-	if err := s.queries.UpdateFeedLastRefreshedAt(ctx, db.UpdateFeedLastRefreshedAtParams{
-		ID:              feed.ID,
-		LastRefreshedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	sameDate := existing.Date.Valid == params.Date.Valid && existing.Date.Time.Equal(params.Date.Time)
+	if existing.Title == params.Title && cleaned == params.Description && sameDate {
+		if existing.Description == params.Description {
+			return false, nil
+		}
+		if err := s.updateFeedItemContent(ctx, existing.ID, params); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if _, err := s.queries.CreateFeedItemRevision(ctx, db.CreateFeedItemRevisionParams{
+		FeedItemID:  existing.ID,
+		Title:       existing.Title,
+		Description: existing.Description,
+		Date:        existing.Date,
 	}); err != nil {
-		log.Printf("Failed to update last_refreshed_at for feed %d: %v", feed.ID, err)
+		return false, fmt.Errorf("failed to store revision: %w", err)
 	}
 
+	if err := s.updateFeedItemContent(ctx, existing.ID, params); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// updateFeedItemContent overwrites the content of a stored item
+func (s *Service) updateFeedItemContent(ctx context.Context, id int64, params db.UpsertFeedItemParams) error {
+	if err := s.queries.UpdateFeedItemContent(ctx, db.UpdateFeedItemContentParams{
+		ID:          id,
+		Title:       params.Title,
+		Description: params.Description,
+		Date:        params.Date,
+	}); err != nil {
+		return fmt.Errorf("failed to update item: %w", err)
+	}
 	return nil
 }
@@ -78,3 +78,453 @@ func TestRefreshFeed(t *testing.T) {
 	assert.Equal(t, time.Month(12), upsertedItems[1].Date.Time.Month())
 	assert.Equal(t, 26, upsertedItems[1].Date.Time.Day())
 }
+
+func TestRefreshFeedRecordsRevision(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html := `
+			<html>
+				<body>
+					<div class="item">
+						<h2 class="title">Pricing</h2>
+						<a class="link" href="/pricing">Link</a>
+						<p class="desc">Pro plan: $20</p>
+					</div>
+				</body>
+			</html>
+		`
+		_, _ = fmt.Fprint(w, html)
+	}))
+	defer ts.Close()
+
+	var revisions []db.CreateFeedItemRevisionParams
+	var updates []db.UpdateFeedItemContentParams
+	mockQ := &mockQueries{
+		// Nothing inserted: the item already exists
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			return nil, nil
+		},
+		GetFeedItemByLinkFn: func(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error) {
+			return db.FeedItem{
+				ID:          7,
+				FeedID:      arg.FeedID,
+				Title:       "Pricing",
+				Link:        arg.Link,
+				Description: sql.NullString{String: "Pro plan: $10", Valid: true},
+			}, nil
+		},
+		CreateFeedItemRevisionFn: func(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error) {
+			revisions = append(revisions, arg)
+			return db.FeedItemRevision{}, nil
+		},
+		UpdateFeedItemContentFn: func(ctx context.Context, arg db.UpdateFeedItemContentParams) error {
+			updates = append(updates, arg)
+			return nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:                  1,
+		Url:                 ts.URL,
+		ItemSelector:        sql.NullString{String: ".item", Valid: true},
+		TitleSelector:       sql.NullString{String: ".title", Valid: true},
+		LinkSelector:        sql.NullString{String: ".link", Valid: true},
+		DescriptionSelector: sql.NullString{String: ".desc", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	// The previous content is kept as a revision
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, int64(7), revisions[0].FeedItemID)
+	assert.Equal(t, "Pro plan: $10", revisions[0].Description.String)
+
+	// And the item is updated with the new content
+	assert.Len(t, updates, 1)
+	assert.Equal(t, int64(7), updates[0].ID)
+	assert.Equal(t, "Pro plan: $20", updates[0].Description.String)
+}
+
+func TestRefreshFeedUnchangedItemHasNoRevision(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<div class="item"><h2 class="title">Same</h2><a class="link" href="/same">Link</a></div>`)
+	}))
+	defer ts.Close()
+
+	var revisionsCount int
+	mockQ := &mockQueries{
+		GetFeedItemByLinkFn: func(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error) {
+			return db.FeedItem{
+				ID:    3,
+				Title: "Same",
+				Link:  arg.Link,
+				// Stored descriptions are absolutized and sanitized
+				Description: sql.NullString{String: NewSanitizer(nil).Sanitize(`<h2 class="title">Same</h2><a class="link" href="`+arg.Link+`">Link</a>`, FormatHTML), Valid: true},
+			}, nil
+		},
+		CreateFeedItemRevisionFn: func(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error) {
+			revisionsCount++
+			return db.FeedItemRevision{}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:            1,
+		Url:           ts.URL,
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, revisionsCount)
+}
+
+func TestRefreshFeedCleansStoredDescriptionWithoutRevision(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<div class="item"><h2 class="title">Same</h2><a class="link" href="/same">Link</a><div class="desc"><a href="/more" onclick="track()">More</a></div></div>`)
+	}))
+	defer ts.Close()
+
+	var revisionsCount int
+	var updates []db.UpdateFeedItemContentParams
+	mockQ := &mockQueries{
+		GetFeedItemByLinkFn: func(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error) {
+			return db.FeedItem{
+				ID:    3,
+				Title: "Same",
+				Link:  arg.Link,
+				// Stored before descriptions were absolutized and sanitized
+				Description: sql.NullString{String: `<a href="/more" onclick="track()">More</a>`, Valid: true},
+			}, nil
+		},
+		CreateFeedItemRevisionFn: func(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error) {
+			revisionsCount++
+			return db.FeedItemRevision{}, nil
+		},
+		UpdateFeedItemContentFn: func(ctx context.Context, arg db.UpdateFeedItemContentParams) error {
+			updates = append(updates, arg)
+			return nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:                  1,
+		Url:                 ts.URL,
+		ItemSelector:        sql.NullString{String: ".item", Valid: true},
+		TitleSelector:       sql.NullString{String: ".title", Valid: true},
+		LinkSelector:        sql.NullString{String: ".link", Valid: true},
+		DescriptionSelector: sql.NullString{String: ".desc", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	// The stored description is brought up to date, without a revision
+	assert.Equal(t, 0, revisionsCount)
+	if assert.Len(t, updates, 1) {
+		assert.Contains(t, updates[0].Description.String, `href="`+ts.URL+`/more"`)
+	}
+}
+
+func TestRefreshFeedRecordsDateRevision(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<div class="item"><h2 class="title">Same</h2><a class="link" href="/same">Link</a><span class="date">2025-01-02</span></div>`)
+	}))
+	defer ts.Close()
+
+	var revisions []db.CreateFeedItemRevisionParams
+	var updates []db.UpdateFeedItemContentParams
+	mockQ := &mockQueries{
+		GetFeedItemByLinkFn: func(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error) {
+			return db.FeedItem{
+				ID:    3,
+				Title: "Same",
+				Link:  arg.Link,
+				Date:  sql.NullTime{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+			}, nil
+		},
+		CreateFeedItemRevisionFn: func(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error) {
+			revisions = append(revisions, arg)
+			return db.FeedItemRevision{}, nil
+		},
+		UpdateFeedItemContentFn: func(ctx context.Context, arg db.UpdateFeedItemContentParams) error {
+			updates = append(updates, arg)
+			return nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:                  1,
+		Url:                 ts.URL,
+		ItemSelector:        sql.NullString{String: ".item", Valid: true},
+		TitleSelector:       sql.NullString{String: ".title", Valid: true},
+		LinkSelector:        sql.NullString{String: ".link", Valid: true},
+		DescriptionSelector: sql.NullString{String: ".missing", Valid: true},
+		DateSelector:        sql.NullString{String: ".date", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	// A new date alone is a change
+	assert.Len(t, revisions, 1)
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), updates[0].Date.Time)
+	}
+}
+
+func TestRefreshFeedSkipsFilteredItems(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html := `
+			<html>
+				<body>
+					<div class="item"><h2 class="title">Go Developer</h2><a class="link" href="/go">Link</a></div>
+					<div class="item"><h2 class="title">Rust Developer</h2><a class="link" href="/rust">Link</a></div>
+				</body>
+			</html>
+		`
+		_, _ = fmt.Fprint(w, html)
+	}))
+	defer ts.Close()
+
+	var upsertedItems []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		ListFeedFilterRulesFn: func(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error) {
+			return []db.FeedFilterRule{{FeedID: feedID, Field: "title", Operator: "contains", Value: "Go"}}, nil
+		},
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			upsertedItems = append(upsertedItems, params)
+			return []int64{int64(len(upsertedItems))}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:            1,
+		Url:           ts.URL,
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	assert.Len(t, upsertedItems, 1)
+	assert.Equal(t, "Go Developer", upsertedItems[0].Title)
+}
+
+func TestRefreshFeedCleansDescriptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html := `
+			<html>
+				<body>
+					<div class="item" onclick="track()">
+						<h2 class="title">Title</h2>
+						<a class="link" href="/post">Link</a>
+						<img src="data:image/gif;base64,R0lGOD" data-src="/cover.png">
+						<script>alert(1)</script>
+					</div>
+				</body>
+			</html>
+		`
+		_, _ = fmt.Fprint(w, html)
+	}))
+	defer ts.Close()
+
+	var upsertedItems []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			upsertedItems = append(upsertedItems, params)
+			return []int64{int64(len(upsertedItems))}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:            1,
+		Url:           ts.URL,
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	assert.Len(t, upsertedItems, 1)
+	description := upsertedItems[0].Description.String
+	assert.Contains(t, description, "<h2>Title</h2>")
+	assert.NotContains(t, description, "script")
+	assert.NotContains(t, description, "onclick")
+	assert.Contains(t, description, `<a href="`+ts.URL+`/post" rel="nofollow">Link</a>`)
+	assert.Contains(t, description, `<img src="`+ts.URL+`/cover.png"/>`)
+}
+
+func TestRefreshFeedKeepsRawResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprint(w, "<html>Down for maintenance</html>")
+	}))
+	defer ts.Close()
+
+	store := NewResponseStore(t.TempDir(), 3)
+	svc := NewService(&mockQueries{})
+	svc.SetResponseStore(store)
+
+	feed := db.Feed{
+		ID:            1,
+		Url:           ts.URL,
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.Error(t, err)
+
+	responses, err := store.List(1)
+	assert.NoError(t, err)
+	assert.Len(t, responses, 1)
+	assert.Equal(t, http.StatusServiceUnavailable, responses[0].Status)
+
+	stored, err := store.Load(1, responses[0].Name)
+	assert.NoError(t, err)
+	assert.Equal(t, "120", stored.Header.Get("Retry-After"))
+	assert.Equal(t, "<html>Down for maintenance</html>", string(stored.Body))
+}
+
+func TestRefreshFeedMergesDatedPages(t *testing.T) {
+	today := time.Now().UTC()
+	pages := map[string]string{
+		"/archive/" + today.Format("2006-01-02"): `
+			<div class="item"><h2 class="title">Today</h2><a class="link" href="/today">Link</a></div>
+			<div class="item"><h2 class="title">Late night</h2><a class="link" href="/late">Link</a></div>`,
+		"/archive/" + today.AddDate(0, 0, -1).Format("2006-01-02"): `
+			<div class="item"><h2 class="title">Late night</h2><a class="link" href="/late">Link</a></div>
+			<div class="item"><h2 class="title">Yesterday</h2><a class="link" href="/yesterday">Link</a></div>`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "<html><body>%s</body></html>", page)
+	}))
+	defer ts.Close()
+
+	var upsertedItems []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			upsertedItems = append(upsertedItems, params)
+			return []int64{int64(len(upsertedItems))}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:            1,
+		Url:           ts.URL + "/archive/{date:2006-01-02}{days:3}",
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+	}
+
+	// The page of two days ago does not exist, which must not fail the refresh
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	var links []string
+	for _, item := range upsertedItems {
+		links = append(links, item.Link)
+	}
+	assert.Equal(t, []string{ts.URL + "/today", ts.URL + "/late", ts.URL + "/yesterday"}, links)
+}
+
+func TestRefreshFeedFailsWhenNoDatedPageExists(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	svc := NewService(&mockQueries{})
+
+	feed := db.Feed{
+		ID:            1,
+		Url:           ts.URL + "/archive/{year}/{month}/{day}{days:2}",
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.ErrorContains(t, err, "HTTP error: 404")
+}
+
+func TestRefreshFeedMergesExtraURLs(t *testing.T) {
+	pages := map[string]string{
+		"/shoes": `<div class="item"><h2 class="title">Boots</h2><a class="link" href="/p/boots">Link</a></div>
+			<div class="item"><h2 class="title">Gift card</h2><a class="link" href="/p/gift-card">Link</a></div>`,
+		"/bags": `<div class="item"><h2 class="title">Gift card</h2><a class="link" href="/p/gift-card">Link</a></div>
+			<div class="item"><h2 class="title">Tote</h2><a class="link" href="/p/tote">Link</a></div>`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "<html><body>%s</body></html>", pages[r.URL.Path])
+	}))
+	defer ts.Close()
+
+	var upsertedItems []db.UpsertFeedItemParams
+	mockQ := &mockQueries{
+		UpsertFeedItemFn: func(ctx context.Context, params db.UpsertFeedItemParams) ([]int64, error) {
+			upsertedItems = append(upsertedItems, params)
+			return []int64{int64(len(upsertedItems))}, nil
+		},
+	}
+
+	svc := NewService(mockQ)
+
+	feed := db.Feed{
+		ID:            1,
+		Url:           ts.URL + "/shoes",
+		ExtraUrls:     sql.NullString{String: ts.URL + "/bags\n" + ts.URL + "/shoes", Valid: true},
+		ItemSelector:  sql.NullString{String: ".item", Valid: true},
+		TitleSelector: sql.NullString{String: ".title", Valid: true},
+		LinkSelector:  sql.NullString{String: ".link", Valid: true},
+	}
+
+	err := svc.RefreshFeed(context.Background(), feed)
+	assert.NoError(t, err)
+
+	var links []string
+	for _, item := range upsertedItems {
+		links = append(links, item.Link)
+	}
+	assert.Equal(t, []string{ts.URL + "/p/boots", ts.URL + "/p/gift-card", ts.URL + "/p/tote"}, links)
+}
+
+func TestFetchPageCutsLargePages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "<html><body>")
+		_, _ = w.Write(make([]byte, maxPageSize))
+	}))
+	defer ts.Close()
+
+	svc := NewService(&mockQueries{})
+
+	page, err := svc.fetchPage(context.Background(), 1, NewSession(Auth{}, nil), ts.URL)
+	assert.NoError(t, err)
+	assert.Len(t, page.Body, maxPageSize)
+}
@@ -0,0 +1,53 @@
+package feed
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// ParseExtraURLs reads one URL per line, ignoring blank lines, and checks
+// that each one is absolute and a valid template
+func ParseExtraURLs(text string) ([]string, error) {
+	var urls []string
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if u, err := url.ParseRequestURI(line); err != nil || u.Host == "" {
+			return nil, fmt.Errorf("line %d: %q is not an absolute URL", i+1, line)
+		}
+		if err := ValidateURLTemplate(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		urls = append(urls, line)
+	}
+	return urls, nil
+}
+
+// SourceURLs returns the concrete URLs a feed is built from: its main URL
+// followed by its extra URLs, each with its placeholders resolved for now,
+// without duplicates
+func SourceURLs(feed db.Feed, now time.Time) []string {
+	sources := []string{feed.Url}
+	for _, line := range strings.Split(feed.ExtraUrls.String, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			sources = append(sources, line)
+		}
+	}
+
+	var urls []string
+	for _, source := range sources {
+		for _, u := range ExpandURL(source, now) {
+			if !slices.Contains(urls, u) {
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,45 @@
+package feed
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExtraURLs(t *testing.T) {
+	urls, err := ParseExtraURLs(" https://example.com/a \n\nhttps://example.com/{year}\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/a", "https://example.com/{year}"}, urls)
+
+	_, err = ParseExtraURLs("https://example.com/a\n/relative")
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = ParseExtraURLs("https://example.com/{date:20060102}{days:90}")
+	assert.ErrorContains(t, err, "line 1: {days:N} must be between 1 and 31")
+
+	urls, err = ParseExtraURLs("")
+	assert.NoError(t, err)
+	assert.Empty(t, urls)
+}
+
+func TestSourceURLs(t *testing.T) {
+	now := time.Date(2025, time.March, 2, 10, 0, 0, 0, time.UTC)
+
+	feed := db.Feed{
+		Url: "https://example.com/news",
+		ExtraUrls: sql.NullString{
+			String: "https://example.com/archive/{year}\nhttps://example.com/news\n",
+			Valid:  true,
+		},
+	}
+
+	assert.Equal(t, []string{
+		"https://example.com/news",
+		"https://example.com/archive/2025",
+	}, SourceURLs(feed, now))
+
+	assert.Equal(t, []string{"https://example.com/"}, SourceURLs(db.Feed{Url: "https://example.com/"}, now))
+}
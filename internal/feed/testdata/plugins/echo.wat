@@ -0,0 +1,20 @@
+;; Reads the page from stdin and returns it as the title of a single item
+(module
+  (import "wasi_snapshot_preview1" "fd_read" (func $fd_read (param i32 i32 i32 i32) (result i32)))
+  (import "wasi_snapshot_preview1" "fd_write" (func $fd_write (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 16) "[{\"title\":\"")
+  (data (i32.const 64) "\",\"link\":\"https://example.com/echo\"}]")
+  (func (export "_start")
+    ;; read up to 4096 bytes of stdin at 1024
+    (i32.store (i32.const 0) (i32.const 1024))
+    (i32.store (i32.const 4) (i32.const 4096))
+    (drop (call $fd_read (i32.const 0) (i32.const 0) (i32.const 1) (i32.const 8)))
+    ;; write prefix, page and suffix to stdout
+    (i32.store (i32.const 200) (i32.const 16))
+    (i32.store (i32.const 204) (i32.const 11))
+    (i32.store (i32.const 208) (i32.const 1024))
+    (i32.store (i32.const 212) (i32.load (i32.const 8)))
+    (i32.store (i32.const 216) (i32.const 64))
+    (i32.store (i32.const 220) (i32.const 37))
+    (drop (call $fd_write (i32.const 1) (i32.const 200) (i32.const 3) (i32.const 12)))))
@@ -0,0 +1,6 @@
+;; Asks for 1000 more pages (62.5 MiB) and traps if they are refused
+(module
+  (memory (export "memory") 1)
+  (func (export "_start")
+    (if (i32.eq (memory.grow (i32.const 1000)) (i32.const -1))
+      (then unreachable))))
@@ -0,0 +1,97 @@
+package feed
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements are the elements that start a new line of text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"br": true, "dd": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "header": true, "hr": true, "li": true, "main": true,
+	"nav": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// writeBlockText writes the text of n, breaking lines around block elements
+func writeBlockText(sb *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		sb.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if n.Data == "script" || n.Data == "style" {
+			return
+		}
+	}
+
+	block := n.Type == html.ElementNode && blockElements[n.Data]
+	if block {
+		sb.WriteString("\n")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeBlockText(sb, c)
+	}
+	if block {
+		sb.WriteString("\n")
+	}
+}
+
+// normalizeLines collapses runs of whitespace within each line and drops
+// empty lines
+func normalizeLines(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlToText returns the text content of an HTML fragment, one line per
+// block of text
+func htmlToText(fragment string) string {
+	if !strings.ContainsRune(fragment, '<') {
+		return normalizeLines(html.UnescapeString(fragment))
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), nil)
+	if err != nil {
+		return normalizeLines(fragment)
+	}
+
+	var sb strings.Builder
+	for _, n := range nodes {
+		writeBlockText(&sb, n)
+	}
+	return normalizeLines(sb.String())
+}
+
+// plainText returns the text content of an HTML fragment on a single line
+func plainText(fragment string) string {
+	return strings.Join(strings.Fields(htmlToText(fragment)), " ")
+}
+
+// maxSummaryLength is the number of characters a summary is cut to
+const maxSummaryLength = 300
+
+// Summary returns the text of an HTML description on a single line, cut at a
+// word boundary to about maxSummaryLength characters
+func Summary(description string) string {
+	text := []rune(plainText(description))
+	if len(text) <= maxSummaryLength {
+		return string(text)
+	}
+
+	cut := string(text[:maxSummaryLength])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,17 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "Story text & more", Summary("<p>Story <b>text</b></p><p>&amp; more</p>"))
+	assert.Equal(t, "", Summary(""))
+
+	long := Summary("<p>" + strings.Repeat("word ", 100) + "</p>")
+	assert.True(t, strings.HasSuffix(long, "word…"))
+	assert.LessOrEqual(t, len([]rune(long)), maxSummaryLength+1)
+}
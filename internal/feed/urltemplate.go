@@ -0,0 +1,136 @@
+package feed
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTemplateURLs bounds the URLs a single template expands to, through
+// {days:N} and {seq:FROM-TO}, so that a typo cannot make a single refresh
+// fetch hundreds of pages
+const maxTemplateURLs = 31
+
+// urlVariable matches the placeholders a feed URL may contain:
+//
+//	{year}          four digit year
+//	{month}         two digit month
+//	{day}           two digit day of the month
+//	{week}          two digit ISO week number
+//	{yday}          three digit day of the year
+//	{date:LAYOUT}   the date formatted with a Go time layout, e.g. {date:2006/01/02}
+//	{days:N}        expands the URL once for each of the last N days, newest first
+//	{seq:FROM-TO}   expands the URL once for each number from FROM to TO, in
+//	                that order and zero padded to the width of FROM, e.g. {seq:01-12}
+var urlVariable = regexp.MustCompile(`\{(year|month|day|week|yday|date:[^{}]+|days:\d+|seq:\d+-\d+)\}`)
+
+// IsTemplateURL reports whether a feed URL contains placeholders
+func IsTemplateURL(rawURL string) bool {
+	return urlVariable.MatchString(rawURL)
+}
+
+// ValidateURLTemplate checks that the expansions of a feed URL stay within
+// maxTemplateURLs
+func ValidateURLTemplate(rawURL string) error {
+	days, seq := urlExpansions(rawURL)
+	if days < 1 || days > maxTemplateURLs {
+		return fmt.Errorf("{days:N} must be between 1 and %d", maxTemplateURLs)
+	}
+	if n := days * len(seq); n > maxTemplateURLs {
+		return fmt.Errorf("expands to %d URLs, more than the limit of %d", n, maxTemplateURLs)
+	}
+	return nil
+}
+
+// ExpandURL resolves the placeholders of a feed URL for the given time and
+// returns the concrete URLs to fetch, newest first and without duplicates.
+// URLs without placeholders are returned unchanged, and expansions beyond
+// maxTemplateURLs are dropped.
+func ExpandURL(rawURL string, now time.Time) []string {
+	days, seq := urlExpansions(rawURL)
+	days = min(max(days, 1), maxTemplateURLs)
+
+	urls := make([]string, 0, min(days*len(seq), maxTemplateURLs))
+	for i := range days {
+		for _, n := range seq {
+			if len(urls) == maxTemplateURLs {
+				return urls
+			}
+			u := expandVariables(rawURL, now.AddDate(0, 0, -i), n)
+			if !slices.Contains(urls, u) {
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls
+}
+
+// urlExpansions returns the number of days a feed URL covers and the numbers
+// of its sequence, a single empty one if it has none. The sequence stops
+// growing past maxTemplateURLs.
+func urlExpansions(rawURL string) (int, []string) {
+	days := 1
+	seq := []string{""}
+	for _, match := range urlVariable.FindAllStringSubmatch(rawURL, -1) {
+		if n, ok := strings.CutPrefix(match[1], "days:"); ok {
+			days, _ = strconv.Atoi(n)
+		}
+		if r, ok := strings.CutPrefix(match[1], "seq:"); ok {
+			seq = sequence(r)
+		}
+	}
+	return days, seq
+}
+
+// sequence returns the numbers of a FROM-TO range, zero padded to the width
+// of FROM
+func sequence(r string) []string {
+	fromText, toText, _ := strings.Cut(r, "-")
+	from, _ := strconv.Atoi(fromText)
+	to, _ := strconv.Atoi(toText)
+
+	step := 1
+	if to < from {
+		step = -1
+	}
+	var seq []string
+	for n := from; len(seq) <= maxTemplateURLs; n += step {
+		seq = append(seq, fmt.Sprintf("%0*d", len(fromText), n))
+		if n == to {
+			break
+		}
+	}
+	return seq
+}
+
+// expandVariables replaces the placeholders of a feed URL with the parts of
+// t and the number n of its sequence
+func expandVariables(rawURL string, t time.Time, n string) string {
+	return urlVariable.ReplaceAllStringFunc(rawURL, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		switch name {
+		case "year":
+			return fmt.Sprintf("%04d", t.Year())
+		case "month":
+			return fmt.Sprintf("%02d", int(t.Month()))
+		case "day":
+			return fmt.Sprintf("%02d", t.Day())
+		case "week":
+			_, week := t.ISOWeek()
+			return fmt.Sprintf("%02d", week)
+		case "yday":
+			return fmt.Sprintf("%03d", t.YearDay())
+		}
+		if layout, ok := strings.CutPrefix(name, "date:"); ok {
+			return t.Format(layout)
+		}
+		if strings.HasPrefix(name, "seq:") {
+			return n
+		}
+		// {days:N} only drives the expansion
+		return ""
+	})
+}
@@ -0,0 +1,113 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandURL(t *testing.T) {
+	now := time.Date(2025, time.March, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		url  string
+		want []string
+	}{
+		{
+			name: "plain URL is unchanged",
+			url:  "https://example.com/news?page=1",
+			want: []string{"https://example.com/news?page=1"},
+		},
+		{
+			name: "date parts",
+			url:  "https://example.com/{year}/{month}/{day}/",
+			want: []string{"https://example.com/2025/03/02/"},
+		},
+		{
+			name: "week and day of year",
+			url:  "https://example.com/w{week}/d{yday}",
+			want: []string{"https://example.com/w09/d061"},
+		},
+		{
+			name: "custom layout",
+			url:  "https://example.com/archive/{date:20060102}.html",
+			want: []string{"https://example.com/archive/20250302.html"},
+		},
+		{
+			name: "last days, crossing a month",
+			url:  "https://example.com/{date:2006-01-02}{days:3}",
+			want: []string{
+				"https://example.com/2025-03-02",
+				"https://example.com/2025-03-01",
+				"https://example.com/2025-02-28",
+			},
+		},
+		{
+			name: "duplicates are dropped",
+			url:  "https://example.com/{year}/{month}{days:3}",
+			want: []string{"https://example.com/2025/03", "https://example.com/2025/02"},
+		},
+		{
+			name: "sequence, zero padded",
+			url:  "https://example.com/issue-{seq:08-11}",
+			want: []string{
+				"https://example.com/issue-08",
+				"https://example.com/issue-09",
+				"https://example.com/issue-10",
+				"https://example.com/issue-11",
+			},
+		},
+		{
+			name: "descending sequence for each day",
+			url:  "https://example.com/{date:0102}/page/{seq:2-1}{days:2}",
+			want: []string{
+				"https://example.com/0302/page/2",
+				"https://example.com/0302/page/1",
+				"https://example.com/0301/page/2",
+				"https://example.com/0301/page/1",
+			},
+		},
+		{
+			name: "days are capped",
+			url:  "https://example.com/{yday}{days:1000}",
+			want: func() []string {
+				var urls []string
+				for i := range maxTemplateURLs {
+					urls = append(urls, "https://example.com/"+now.AddDate(0, 0, -i).Format("002"))
+				}
+				return urls
+			}(),
+		},
+		{
+			name: "unknown placeholders are kept",
+			url:  "https://example.com/{id}",
+			want: []string{"https://example.com/{id}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExpandURL(tt.url, now))
+		})
+	}
+}
+
+func TestValidateURLTemplate(t *testing.T) {
+	assert.NoError(t, ValidateURLTemplate("https://example.com/news"))
+	assert.NoError(t, ValidateURLTemplate("https://example.com/{yday}{days:31}"))
+	assert.NoError(t, ValidateURLTemplate("https://example.com/page/{seq:1-10}{days:3}"))
+	assert.Error(t, ValidateURLTemplate("https://example.com/{yday}{days:32}"))
+	assert.Error(t, ValidateURLTemplate("https://example.com/{yday}{days:0}"))
+	assert.Error(t, ValidateURLTemplate("https://example.com/page/{seq:1-100}"))
+	assert.Error(t, ValidateURLTemplate("https://example.com/page/{seq:1-11}{days:3}"))
+}
+
+func TestIsTemplateURL(t *testing.T) {
+	assert.True(t, IsTemplateURL("https://example.com/{year}"))
+	assert.True(t, IsTemplateURL("https://example.com/{date:2006}{days:7}"))
+	assert.True(t, IsTemplateURL("https://example.com/page/{seq:1-5}"))
+	assert.False(t, IsTemplateURL("https://example.com/{id}"))
+	assert.False(t, IsTemplateURL("https://example.com/"))
+}
@@ -0,0 +1,102 @@
+// Package notify delivers feed events, such as a feed breaking, to the
+// channels configured by the user.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Event kinds
+const (
+	// KindBroken is sent when a feed's selectors stop matching the page
+	KindBroken = "broken"
+	// KindRecovered is sent when a broken feed matches again
+	KindRecovered = "recovered"
+)
+
+// webhookTimeout bounds how long a single webhook delivery may take
+const webhookTimeout = 10 * time.Second
+
+// Event is something about a feed worth telling the user
+type Event struct {
+	Kind    string `json:"kind"`
+	FeedID  int64  `json:"feed_id"`
+	Feed    string `json:"feed"`
+	URL     string `json:"url"`
+	Message string `json:"message"`
+}
+
+// Notifier delivers events to a channel
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Multi delivers events to every one of its notifiers
+type Multi []Notifier
+
+// Notify sends the event to all notifiers, returning the errors of those that failed
+func (m Multi) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Webhook posts events as JSON to a URL. The payload carries a "text" field
+// so that Slack and Mattermost compatible endpoints display the message.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook creates a notifier posting to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Notify posts the event to the webhook
+func (w *Webhook) Notify(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(struct {
+		Event
+		Text string `json:"text"`
+	}{Event: event, Text: event.Message})
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// FromWebhookURLs builds a notifier posting to each of the URLs
+func FromWebhookURLs(urls []string) Multi {
+	m := make(Multi, 0, len(urls))
+	for _, url := range urls {
+		m = append(m, NewWebhook(url))
+	}
+	return m
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	var received map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer ts.Close()
+
+	err := NewWebhook(ts.URL).Notify(context.Background(), Event{
+		Kind:    KindBroken,
+		FeedID:  3,
+		Feed:    "Blog",
+		URL:     "https://example.com",
+		Message: `Feed "Blog" looks broken`,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "broken", received["kind"])
+	assert.Equal(t, float64(3), received["feed_id"])
+	assert.Equal(t, `Feed "Blog" looks broken`, received["text"])
+}
+
+func TestMultiNotifyReportsFailures(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	err := FromWebhookURLs([]string{ok.URL, failing.URL}).Notify(context.Background(), Event{Kind: KindRecovered})
+	assert.ErrorContains(t, err, "webhook returned status 500")
+}
@@ -3,10 +3,12 @@ package server
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/alessandrocuzzocrea/web2rss/internal/config"
@@ -100,4 +102,164 @@ func TestIntegration(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "Blog Post 1")
 	assert.Contains(t, w.Body.String(), ts.URL+"/post1")
+
+	// 6. Query parameters select the items
+	for query, listed := range map[string]bool{
+		"q=blog+post":      true,
+		"q=nothing":        false,
+		"since=2000-01-01": true,
+		"since=2999-01-01": false,
+		"limit=1&q=POST+1": true,
+	} {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/feed/%d/rss?%s", feed.ID, query), nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		assert.Equal(t, listed, strings.Contains(w.Body.String(), "Blog Post 1"), query)
+	}
+}
+
+func TestStoreInTx(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := sql.Open("sqlite", tmpDir+"/test.sqlite3")
+	assert.NoError(t, err)
+	defer func() { _ = database.Close() }()
+
+	schema, err := os.ReadFile("../../db/schema.sql")
+	assert.NoError(t, err)
+	_, err = database.Exec(string(schema))
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	store := db.NewStore(database)
+	feed, err := store.CreateFeed(ctx, db.CreateFeedParams{Name: "Test", Url: "https://example.com", Mode: "list"})
+	assert.NoError(t, err)
+
+	// A failed write leaves nothing behind
+	err = store.InTx(ctx, func(q *db.Queries) error {
+		created, err := q.CreateCollection(ctx, "Broken")
+		assert.NoError(t, err)
+		assert.NoError(t, q.AddCollectionFeed(ctx, db.AddCollectionFeedParams{CollectionID: created.ID, FeedID: feed.ID}))
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+	collections, err := store.ListCollectionsWithFeedsCount(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, collections)
+
+	err = store.InTx(ctx, func(q *db.Queries) error {
+		created, err := q.CreateCollection(ctx, "Team")
+		if err != nil {
+			return err
+		}
+		return q.AddCollectionFeed(ctx, db.AddCollectionFeedParams{CollectionID: created.ID, FeedID: feed.ID})
+	})
+	assert.NoError(t, err)
+	collections, err = store.ListCollectionsWithFeedsCount(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, collections, 1) {
+		assert.Equal(t, "Team", collections[0].Name)
+		assert.Equal(t, int64(1), collections[0].FeedsCount)
+	}
+}
+
+func TestTrimFeedItemTombstones(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := sql.Open("sqlite", tmpDir+"/test.sqlite3")
+	assert.NoError(t, err)
+	defer func() { _ = database.Close() }()
+
+	schema, err := os.ReadFile("../../db/schema.sql")
+	assert.NoError(t, err)
+	_, err = database.Exec(string(schema))
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	queries := db.New(database)
+	feed, err := queries.CreateFeed(ctx, db.CreateFeedParams{Name: "Test", Url: "https://example.com", Mode: "list"})
+	assert.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		_, err = database.ExecContext(ctx, "INSERT INTO feed_item_tombstones (feed_id, link, pruned_at) VALUES (?, ?, datetime('now', ?))",
+			feed.ID, fmt.Sprintf("https://example.com/%d", i), fmt.Sprintf("-%d days", 4-i))
+		assert.NoError(t, err)
+	}
+
+	// Only the most recently pruned links are kept
+	err = queries.TrimFeedItemTombstones(ctx, db.TrimFeedItemTombstonesParams{FeedID: feed.ID, MaxTombstones: 2})
+	assert.NoError(t, err)
+
+	var links []string
+	rows, err := database.QueryContext(ctx, "SELECT link FROM feed_item_tombstones WHERE feed_id = ? ORDER BY link", feed.ID)
+	assert.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var link string
+		assert.NoError(t, rows.Scan(&link))
+		links = append(links, link)
+	}
+	assert.Equal(t, []string{"https://example.com/2", "https://example.com/3"}, links)
+}
+
+func TestUpdateFeedKeepsHealthAndSnapshot(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := sql.Open("sqlite", tmpDir+"/test.sqlite3")
+	assert.NoError(t, err)
+	defer func() { _ = database.Close() }()
+
+	schema, err := os.ReadFile("../../db/schema.sql")
+	assert.NoError(t, err)
+	_, err = database.Exec(string(schema))
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	queries := db.New(database)
+	feed, err := queries.CreateFeed(ctx, db.CreateFeedParams{
+		Name:         "Terms",
+		Url:          "https://example.com/terms",
+		Mode:         "monitor",
+		SourceType:   "css",
+		ItemSelector: sql.NullString{String: ".terms", Valid: true},
+	})
+	assert.NoError(t, err)
+
+	record := func() {
+		assert.NoError(t, queries.UpdateFeedHealth(ctx, db.UpdateFeedHealthParams{
+			ID:            feed.ID,
+			LastItemCount: sql.NullInt64{Int64: 1, Valid: true},
+			BrokenReason:  sql.NullString{String: "region changed", Valid: true},
+		}))
+		assert.NoError(t, queries.UpdateFeedSnapshot(ctx, db.UpdateFeedSnapshotParams{
+			ID:           feed.ID,
+			LastSnapshot: sql.NullString{String: "Clause one", Valid: true},
+		}))
+	}
+	update := func(name, itemSelector string) db.Feed {
+		assert.NoError(t, queries.UpdateFeed(ctx, db.UpdateFeedParams{
+			ID:           feed.ID,
+			Name:         name,
+			Url:          feed.Url,
+			Mode:         feed.Mode,
+			SourceType:   feed.SourceType,
+			ItemSelector: sql.NullString{String: itemSelector, Valid: true},
+		}))
+		updated, err := queries.GetFeed(ctx, feed.ID)
+		assert.NoError(t, err)
+		return updated
+	}
+
+	// Renaming a feed keeps what was learned about it
+	record()
+	updated := update("Terms of service", ".terms")
+	assert.Equal(t, "Terms of service", updated.Name)
+	assert.Equal(t, "Clause one", updated.LastSnapshot.String)
+	assert.Equal(t, "region changed", updated.BrokenReason.String)
+	assert.Equal(t, int64(1), updated.LastItemCount.Int64)
+
+	// Monitoring another region starts over
+	updated = update("Terms of service", ".privacy")
+	assert.False(t, updated.LastSnapshot.Valid)
+	assert.False(t, updated.BrokenReason.Valid)
+	assert.False(t, updated.LastItemCount.Valid)
 }
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// startMaintenance starts a background goroutine that periodically prunes
+// items according to the retention policies and compacts the database
+func (s *Server) startMaintenance() {
+	interval := s.config.MaintenanceInterval
+	if interval <= 0 {
+		log.Println("Database maintenance disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.runMaintenance(context.Background()); err != nil {
+				log.Printf("Database maintenance failed: %v", err)
+			}
+		}
+	}()
+
+	log.Printf("Database maintenance scheduled every %s", interval)
+}
+
+// runMaintenance prunes old items, then lets SQLite reclaim the freed pages
+// and refresh its query planner statistics
+func (s *Server) runMaintenance(ctx context.Context) error {
+	pruned, err := s.feedService.PruneAllFeeds(ctx, feed.RetentionPolicy{
+		MaxItems:   s.config.RetentionMaxItems,
+		MaxAgeDays: s.config.RetentionMaxAgeDays,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune items: %w", err)
+	}
+
+	for _, stmt := range []string{"PRAGMA optimize;", "VACUUM;"} {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to run %s: %w", stmt, err)
+		}
+	}
+
+	log.Printf("Database maintenance completed: pruned %d items", pruned)
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func TestRunMaintenance(t *testing.T) {
+	// Create a temporary directory for the test
+	tmpDir, err := os.MkdirTemp("", "web2rss-test-*")
+	assert.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	dbPath := tmpDir + "/test.sqlite3"
+
+	// Initialize database
+	database, err := sql.Open("sqlite", dbPath)
+	assert.NoError(t, err)
+
+	schema, err := os.ReadFile("../../db/schema.sql")
+	assert.NoError(t, err)
+	_, err = database.Exec(string(schema))
+	assert.NoError(t, err)
+	err = database.Close()
+	assert.NoError(t, err)
+
+	cfg := &config.Config{
+		DBPath:            dbPath,
+		DataDir:           tmpDir,
+		Timezone:          "UTC",
+		TemplateDir:       "../../templates",
+		RetentionMaxItems: 3,
+	}
+
+	app, err := New(cfg)
+	assert.NoError(t, err)
+	defer func() {
+		err := app.Close()
+		assert.NoError(t, err)
+	}()
+
+	ctx := context.Background()
+	queries := db.New(app.db)
+
+	feed, err := queries.CreateFeed(ctx, db.CreateFeedParams{Name: "Test", Url: "https://example.com", Mode: "list"})
+	assert.NoError(t, err)
+
+	for i := 1; i <= 5; i++ {
+		_, err := queries.UpsertFeedItem(ctx, db.UpsertFeedItemParams{
+			FeedID: feed.ID,
+			Title:  fmt.Sprintf("Item %d", i),
+			Link:   fmt.Sprintf("https://example.com/%d", i),
+		})
+		assert.NoError(t, err)
+	}
+
+	err = app.runMaintenance(ctx)
+	assert.NoError(t, err)
+
+	// Only the most recent items are kept
+	items, err := queries.ListFeedItems(ctx, feed.ID)
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	for _, item := range items {
+		assert.NotContains(t, []string{"Item 1", "Item 2"}, item.Title)
+	}
+
+	// A per-feed age limit removes everything older than the cutoff
+	err = queries.UpdateFeedRetention(ctx, db.UpdateFeedRetentionParams{
+		ID:                  feed.ID,
+		RetentionMaxAgeDays: sql.NullInt64{Int64: 1, Valid: true},
+	})
+	assert.NoError(t, err)
+	_, err = app.db.ExecContext(ctx, "UPDATE feed_items SET created_at = datetime('now', '-2 days') WHERE title = 'Item 3'")
+	assert.NoError(t, err)
+
+	err = app.runMaintenance(ctx)
+	assert.NoError(t, err)
+
+	items, err = queries.ListFeedItems(ctx, feed.ID)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	// Pruned items still listed on the source page are not stored again
+	ids, err := queries.UpsertFeedItem(ctx, db.UpsertFeedItemParams{
+		FeedID: feed.ID,
+		Title:  "Item 1",
+		Link:   "https://example.com/1",
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	items, err = queries.ListFeedItems(ctx, feed.ID)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	// Until the feed is reset
+	err = queries.DeleteFeedItemTombstones(ctx, feed.ID)
+	assert.NoError(t, err)
+	ids, err = queries.UpsertFeedItem(ctx, db.UpsertFeedItemParams{
+		FeedID: feed.ID,
+		Title:  "Item 1",
+		Link:   "https://example.com/1",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 1)
+}
@@ -1,16 +1,19 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 
 	"github.com/alessandrocuzzocrea/web2rss/internal/config"
 	"github.com/alessandrocuzzocrea/web2rss/internal/db"
 	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+	"github.com/alessandrocuzzocrea/web2rss/internal/notify"
 	"github.com/alessandrocuzzocrea/web2rss/internal/ui"
 	_ "modernc.org/sqlite"
 )
@@ -22,6 +25,7 @@ const (
 // Server represents the main application server
 type Server struct {
 	db          *sql.DB
+	plugins     *feed.PluginHost
 	handler     *ui.Handler
 	feedService *feed.Service
 	config      *config.Config
@@ -61,10 +65,31 @@ func New(cfg *config.Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	queries := db.New(database)
+	queries := db.NewStore(database)
 
 	// Initialize Feed Service
 	feedService := feed.NewService(queries)
+	feedService.SetSanitizer(feed.NewSanitizer(cfg.SanitizeAllowedElements))
+	feedService.SetLocation(cfg.Location())
+	feedService.SetResponseStore(feed.NewResponseStore(filepath.Join(cfg.DataDir, "responses"), cfg.ResponseHistory))
+	if len(cfg.NotifyWebhookURLs) > 0 {
+		feedService.SetNotifier(notify.FromWebhookURLs(cfg.NotifyWebhookURLs))
+	}
+
+	// Load the extractor plugins uploaded so far
+	plugins, err := feed.NewPluginHost(context.Background(), filepath.Join(cfg.DataDir, "plugins"), feed.PluginLimits{
+		MemoryMB: cfg.PluginMemoryMB,
+		Timeout:  cfg.PluginTimeout,
+	})
+	if err != nil {
+		_ = database.Close()
+		return nil, fmt.Errorf("failed to start plugin host: %w", err)
+	}
+	if err := feedService.SetPluginHost(context.Background(), plugins); err != nil {
+		_ = plugins.Close(context.Background())
+		_ = database.Close()
+		return nil, fmt.Errorf("failed to load plugins: %w", err)
+	}
 
 	// Initialize Templates
 	templates := template.New("").Funcs(ui.NewTemplateFuncs(cfg))
@@ -78,6 +103,7 @@ func New(cfg *config.Config) (*Server, error) {
 	for _, dir := range dirs {
 		_, err := templates.ParseGlob(dir)
 		if err != nil {
+			_ = plugins.Close(context.Background())
 			_ = database.Close()
 			return nil, fmt.Errorf("failed to parse templates in %s: %w", dir, err)
 		}
@@ -88,6 +114,7 @@ func New(cfg *config.Config) (*Server, error) {
 
 	server := &Server{
 		db:          database,
+		plugins:     plugins,
 		handler:     handler,
 		feedService: feedService,
 		config:      cfg,
@@ -97,6 +124,9 @@ func New(cfg *config.Config) (*Server, error) {
 }
 
 func (s *Server) Close() error {
+	if err := s.plugins.Close(context.Background()); err != nil {
+		log.Printf("Failed to close plugin host: %v", err)
+	}
 	return s.db.Close()
 }
 
@@ -107,6 +137,9 @@ func (s *Server) Run() error {
 	// Start Feed Scheduler
 	s.feedService.StartScheduler()
 
+	// Start pruning and database maintenance
+	s.startMaintenance()
+
 	mux := s.handler.RegisterRoutes()
 	port := s.config.Port
 
@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// Atom 1.0 XML structures (RFC 4287)
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   AtomPerson  `xml:"author"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type AtomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published,omitempty"`
+	Links     []AtomLink  `xml:"link"`
+	Content   AtomContent `xml:"content"`
+}
+
+// AtomContent is text, or HTML escaped as text when Type is "html"
+type AtomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// GET /feed/{id}/atom - Generate an Atom feed
+func (h *Handler) handleFeedAtom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	f, err := h.queries.GetFeed(ctx, feedID)
+	if err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+
+	query, err := h.parseOutputQuery(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if h.notModified(w, r, f) {
+		return
+	}
+
+	items, err := h.listOutputItems(ctx, feedID, query)
+	if err != nil {
+		fmt.Printf("Failed to fetch feed items: %v\n", err)
+		http.Error(w, "Failed to fetch feed items", http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "html"
+	if f.DescriptionFormat == feed.FormatText {
+		contentType = "text"
+	}
+
+	entries := make([]AtomEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, newAtomEntry(item, contentType))
+	}
+
+	selfURL := fmt.Sprintf("%s/feed/%d/atom", h.baseURL(r), feedID)
+	siteURL := h.siteURL(f.Url)
+	writeAtom(w, AtomFeed{
+		Lang:     cmp.Or(f.Language.String, defaultLanguage),
+		ID:       selfURL,
+		Title:    f.Name,
+		Subtitle: cmp.Or(f.Description.String, fmt.Sprintf("Feed generated from %s", siteURL)),
+		Updated:  formatRFC3339Date(feedUpdated(f, items)),
+		Author:   AtomPerson{Name: f.Name},
+		Links: []AtomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: siteURL, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: entries,
+	})
+}
+
+// itemID identifies a stored item in Atom and JSON feeds: by its link, which
+// is unique within a feed and, unlike the item ID, survives resetting the
+// feed's items, or else by its feed and item IDs
+func itemID(item db.FeedItem) string {
+	if item.Link != "" {
+		return item.Link
+	}
+	return fmt.Sprintf("urn:web2rss:feed:%d:item:%d", item.FeedID, item.ID)
+}
+
+// newAtomEntry converts a stored item
+func newAtomEntry(item db.FeedItem, contentType string) AtomEntry {
+	var updated time.Time
+	switch {
+	case item.UpdatedAt.Valid:
+		updated = item.UpdatedAt.Time
+	case item.CreatedAt.Valid:
+		updated = item.CreatedAt.Time
+	default:
+		updated = time.Now()
+	}
+
+	entry := AtomEntry{
+		ID:      itemID(item),
+		Title:   item.Title,
+		Updated: formatRFC3339Date(updated),
+		Content: AtomContent{Type: contentType, Body: item.Description.String},
+	}
+	if item.Link != "" {
+		entry.Links = []AtomLink{{Href: item.Link, Rel: "alternate"}}
+	}
+	if item.Date.Valid {
+		entry.Published = formatRFC3339Date(item.Date.Time)
+	}
+	return entry
+}
+
+// writeAtom sends an Atom document
+func writeAtom(w http.ResponseWriter, atom AtomFeed) {
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		fmt.Printf("Failed to write XML header: %v\n", err)
+		return
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(atom); err != nil {
+		fmt.Printf("Failed to generate Atom: %v\n", err)
+	}
+}
+
+// formatRFC3339Date formats time to RFC 3339 in UTC, as Atom and JSON Feed use
+func formatRFC3339Date(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
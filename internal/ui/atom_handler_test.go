@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleFeedAtom(t *testing.T) {
+	refreshed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	changed := time.Date(2024, 3, 2, 8, 30, 0, 0, time.UTC)
+
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{
+				ID:                1,
+				Name:              "Test Feed",
+				Url:               "https://example.com",
+				DescriptionFormat: "html",
+				Description:       sql.NullString{String: "Latest news", Valid: true},
+				Language:          sql.NullString{String: "it", Valid: true},
+				LastRefreshedAt:   sql.NullTime{Time: refreshed, Valid: true},
+			}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			return []db.FeedItem{
+				{
+					ID:          1,
+					Title:       "Test Item 1",
+					Link:        "https://example.com/item1",
+					Description: sql.NullString{String: "<p>Hello</p>", Valid: true},
+					CreatedAt:   sql.NullTime{Time: refreshed, Valid: true},
+					UpdatedAt:   sql.NullTime{Time: changed, Valid: true},
+					Date:        sql.NullTime{Time: time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), Valid: true},
+				},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "http://rss.example.net/feed/1/atom", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedAtom(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="it">`)
+	assert.Contains(t, body, `<content type="html">&lt;p&gt;Hello&lt;/p&gt;</content>`)
+
+	var atom AtomFeed
+	assert.NoError(t, xml.Unmarshal([]byte(body), &atom))
+
+	assert.Equal(t, "http://rss.example.net/feed/1/atom", atom.ID)
+	assert.Equal(t, "Test Feed", atom.Title)
+	assert.Equal(t, "Latest news", atom.Subtitle)
+	assert.Equal(t, "it", atom.Lang)
+	assert.Equal(t, "2024-03-02T08:30:00Z", atom.Updated)
+	assert.Equal(t, []AtomLink{
+		{Href: "http://rss.example.net/feed/1/atom", Rel: "self", Type: "application/atom+xml"},
+		{Href: "https://example.com", Rel: "alternate", Type: "text/html"},
+	}, atom.Links)
+
+	assert.Len(t, atom.Entries, 1)
+	entry := atom.Entries[0]
+	assert.Equal(t, "https://example.com/item1", entry.ID)
+	assert.Equal(t, "2024-03-02T08:30:00Z", entry.Updated)
+	assert.Equal(t, "2024-02-28T00:00:00Z", entry.Published)
+	assert.Equal(t, AtomContent{Type: "html", Body: "<p>Hello</p>"}, entry.Content)
+}
+
+func TestHandleFeedAtomTextDescriptions(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: 1, Name: "Test Feed", DescriptionFormat: "text"}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			return []db.FeedItem{
+				{ID: 1, Title: "Item", Link: "https://example.com/item", Description: sql.NullString{String: "Plain", Valid: true}},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/feed/1/atom", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedAtom(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<content type="text">Plain</content>`)
+}
+
+func TestNewAtomEntryWithoutLink(t *testing.T) {
+	entry := newAtomEntry(db.FeedItem{ID: 12, FeedID: 3, Title: "Notice"}, "html")
+
+	// Entries without a link are identified by their feed and item
+	assert.Equal(t, "urn:web2rss:feed:3:item:12", entry.ID)
+	assert.Empty(t, entry.Links)
+}
+
+func TestHandleFeedAtomFeedNotFound(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{}, fmt.Errorf("feed not found")
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/feed/999/atom", nil)
+	req.SetPathValue("id", "999")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedAtom(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// AuthSettings are the authentication fields of the feed forms. Handlers
+// name their feed variables feed, so they refer to the type through this alias.
+type AuthSettings = feed.Auth
+
+// authFromForm reads the authentication fields of the feed forms
+func authFromForm(r *http.Request) feed.Auth {
+	return feed.Auth{
+		Type:        feed.NormalizeAuthType(r.FormValue("auth_type")),
+		Username:    r.FormValue("auth_username"),
+		Password:    r.FormValue("auth_password"),
+		Token:       r.FormValue("auth_token"),
+		LoginURL:    r.FormValue("login_url"),
+		LoginFields: r.FormValue("login_fields"),
+		LoginCheck:  r.FormValue("login_check"),
+	}
+}
+
+// secretMask stands for a stored login field value in the feed forms
+const secretMask = "********"
+
+// formAuth returns authentication settings as shown by the feed forms, with
+// the values of the login fields masked. The form leaves the password and
+// token out by itself.
+func formAuth(auth feed.Auth) feed.Auth {
+	lines := strings.Split(auth.LoginFields, "\n")
+	for i, line := range lines {
+		if name, value, ok := strings.Cut(line, "="); ok && value != "" {
+			lines[i] = name + "=" + secretMask
+		}
+	}
+	auth.LoginFields = strings.Join(lines, "\n")
+	return auth
+}
+
+// keepSecrets fills in the password and token left blank in a feed's form,
+// and the login field values left masked, with the stored ones, since the
+// form does not show them again
+func keepSecrets(auth, stored feed.Auth) feed.Auth {
+	auth.Password = cmp.Or(auth.Password, stored.Password)
+	auth.Token = cmp.Or(auth.Token, stored.Token)
+
+	storedFields, _ := feed.ParseLoginFields(stored.LoginFields)
+	lines := strings.Split(auth.LoginFields, "\n")
+	for i, line := range lines {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(value) != secretMask {
+			continue
+		}
+		if values, found := storedFields[strings.TrimSpace(name)]; found {
+			lines[i] = name + "=" + values[0]
+		}
+	}
+	auth.LoginFields = strings.Join(lines, "\n")
+	return auth
+}
+
+// sourceAuth returns the stored authentication of the feed a form was
+// filled from, when duplicating or editing one, to keep its secrets
+func (h *Handler) sourceAuth(ctx context.Context, r *http.Request) (feed.Auth, error) {
+	idStr := r.FormValue("source_feed_id")
+	if idStr == "" {
+		return feed.Auth{}, nil
+	}
+	feedID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return feed.Auth{}, fmt.Errorf("invalid source feed ID: %w", err)
+	}
+	return h.loadAuth(ctx, feedID)
+}
+
+// loadAuth returns the authentication settings stored for a feed
+func (h *Handler) loadAuth(ctx context.Context, feedID int64) (feed.Auth, error) {
+	row, err := h.queries.GetFeedAuth(ctx, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return feed.Auth{Type: feed.AuthNone}, nil
+	}
+	if err != nil {
+		return feed.Auth{}, fmt.Errorf("failed to load authentication: %w", err)
+	}
+	return feed.AuthFromRow(row), nil
+}
+
+// saveAuth replaces the authentication settings stored for a feed, with the
+// queries of the transaction writing the feed
+func saveAuth(ctx context.Context, q Querier, feedID int64, auth feed.Auth) error {
+	if auth.Type == feed.AuthNone {
+		if err := q.DeleteFeedAuth(ctx, feedID); err != nil {
+			return fmt.Errorf("failed to delete authentication: %w", err)
+		}
+		return nil
+	}
+
+	if err := q.UpsertFeedAuth(ctx, db.UpsertFeedAuthParams{
+		FeedID:      feedID,
+		Type:        auth.Type,
+		Username:    auth.Username,
+		Password:    auth.Password,
+		Token:       auth.Token,
+		LoginUrl:    auth.LoginURL,
+		LoginFields: auth.LoginFields,
+		LoginCheck:  auth.LoginCheck,
+	}); err != nil {
+		return fmt.Errorf("failed to save authentication: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// feedVersion identifies the output of a feed at some point: it changes
+// whenever the feed is edited or refreshed, or its items change
+type feedVersion struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// loadFeedVersion returns the version of a feed's output for a request,
+// without loading its items. Every URL, query included, is a variant of its
+// own.
+func (h *Handler) loadFeedVersion(ctx context.Context, r *http.Request, f db.Feed) (feedVersion, error) {
+	stats, err := h.queries.GetFeedItemsVersion(ctx, f.ID)
+	if err != nil {
+		return feedVersion{}, err
+	}
+
+	var modified time.Time
+	for _, t := range []sql.NullTime{f.UpdatedAt, f.LastRefreshedAt} {
+		if t.Valid && t.Time.After(modified) {
+			modified = t.Time
+		}
+	}
+	if itemsUpdated := time.Unix(stats.LastUpdatedUnix, 0); stats.LastUpdatedUnix > 0 && itemsUpdated.After(modified) {
+		modified = itemsUpdated
+	}
+
+	hash := sha256.Sum256(fmt.Appendf(nil, "%s%s|%d|%d|%d|%d",
+		h.baseURL(r), r.URL.RequestURI(), f.UpdatedAt.Time.UnixNano(), f.LastRefreshedAt.Time.UnixNano(),
+		stats.ItemsCount, stats.LastUpdatedUnix))
+
+	return feedVersion{
+		ETag:         fmt.Sprintf(`"%x"`, hash[:16]),
+		LastModified: modified,
+	}, nil
+}
+
+// notModified sets the caching headers of a generated feed and, if the
+// client's copy is still current, replies 304 Not Modified and reports true.
+// Feeds are cached until their next scheduled refresh.
+func (h *Handler) notModified(w http.ResponseWriter, r *http.Request, f db.Feed) bool {
+	version, err := h.loadFeedVersion(r.Context(), r, f)
+	if err != nil {
+		log.Printf("Failed to load version of feed %d: %v", f.ID, err)
+		return false
+	}
+
+	return replyNotModified(w, r, version, untilRefresh(f))
+}
+
+// combinedNotModified is notModified for a feed combining the items of
+// several feeds, along with the version of whatever else goes into it. It
+// changes whenever one of the feeds does, and is cached until the next of
+// them is refreshed.
+func (h *Handler) combinedNotModified(w http.ResponseWriter, r *http.Request, feeds []db.Feed, extra string) bool {
+	hash := sha256.New()
+	_, _ = fmt.Fprint(hash, extra)
+
+	var modified time.Time
+	maxAge := feed.RefreshInterval
+	for _, f := range feeds {
+		version, err := h.loadFeedVersion(r.Context(), r, f)
+		if err != nil {
+			log.Printf("Failed to load version of feed %d: %v", f.ID, err)
+			return false
+		}
+		_, _ = fmt.Fprintf(hash, "|%d%s", f.ID, version.ETag)
+		if version.LastModified.After(modified) {
+			modified = version.LastModified
+		}
+		maxAge = min(maxAge, untilRefresh(f))
+	}
+
+	return replyNotModified(w, r, feedVersion{
+		ETag:         fmt.Sprintf(`"%x"`, hash.Sum(nil)[:16]),
+		LastModified: modified,
+	}, maxAge)
+}
+
+// untilRefresh returns how long the output of a feed stays current
+func untilRefresh(f db.Feed) time.Duration {
+	maxAge := feed.RefreshInterval
+	if f.LastRefreshedAt.Valid {
+		maxAge = time.Until(f.LastRefreshedAt.Time.Add(feed.RefreshInterval))
+	}
+	return max(maxAge, 0)
+}
+
+// replyNotModified sets the caching headers of a version of a generated feed
+// and replies 304 Not Modified if the client has it
+func replyNotModified(w http.ResponseWriter, r *http.Request, version feedVersion, maxAge time.Duration) bool {
+	w.Header().Set("ETag", version.ETag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	if !version.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", version.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if !clientHasVersion(r, version) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// clientHasVersion evaluates the conditional headers of a request. As in
+// RFC 9110, If-Modified-Since is ignored when If-None-Match is present.
+func clientHasVersion(r *http.Request, version feedVersion) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == version.ETag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !version.LastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !version.LastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
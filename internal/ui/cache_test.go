@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCacheTestHandler serves a feed refreshed 10 minutes ago whose newest
+// item changed at itemsUpdated, counting the item lists it loads
+func newCacheTestHandler(itemsCount *int64, itemsUpdated time.Time, listed *int) *Handler {
+	refreshed := time.Now().Add(-10 * time.Minute)
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{
+				ID:              1,
+				Name:            "Test Feed",
+				Url:             "https://example.com",
+				LastRefreshedAt: sql.NullTime{Time: refreshed, Valid: true},
+				UpdatedAt:       sql.NullTime{Time: refreshed, Valid: true},
+			}, nil
+		},
+		GetFeedItemsVersionFn: func(ctx context.Context, feedID int64) (db.GetFeedItemsVersionRow, error) {
+			return db.GetFeedItemsVersionRow{ItemsCount: *itemsCount, LastUpdatedUnix: itemsUpdated.Unix()}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			*listed++
+			return []db.FeedItem{{ID: 1, Title: "Item", Link: "https://example.com/item"}}, nil
+		},
+	}
+	return NewHandler(mockQ, nil, nil, nil)
+}
+
+func getFeedRSS(handler *Handler, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/feed/1/rss", nil)
+	req.SetPathValue("id", "1")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	handler.handleFeedRSS(w, req)
+	return w
+}
+
+func TestHandleFeedRSSCacheHeaders(t *testing.T) {
+	itemsCount := int64(1)
+	itemsUpdated := time.Now().Add(-5 * time.Minute)
+	listed := 0
+	handler := newCacheTestHandler(&itemsCount, itemsUpdated, &listed)
+
+	w := getFeedRSS(handler, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Equal(t, itemsUpdated.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+	// Cached until the next hourly refresh, 50 minutes from now
+	var maxAge int
+	_, err := fmt.Sscanf(w.Header().Get("Cache-Control"), "public, max-age=%d", &maxAge)
+	assert.NoError(t, err)
+	assert.InDelta(t, 50*60, maxAge, 5)
+	assert.Equal(t, 1, listed)
+}
+
+func TestHandleFeedRSSIfNoneMatch(t *testing.T) {
+	itemsCount := int64(1)
+	listed := 0
+	handler := newCacheTestHandler(&itemsCount, time.Now().Add(-5*time.Minute), &listed)
+
+	etag := getFeedRSS(handler, nil).Header().Get("ETag")
+
+	w := getFeedRSS(handler, http.Header{"If-None-Match": {`"other", ` + etag}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.Equal(t, 1, listed, "a 304 must not load the items")
+
+	// A new item changes the version
+	itemsCount++
+	w = getFeedRSS(handler, http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestHandleFeedRSSIfModifiedSince(t *testing.T) {
+	itemsCount := int64(1)
+	itemsUpdated := time.Now().Add(-5 * time.Minute)
+	listed := 0
+	handler := newCacheTestHandler(&itemsCount, itemsUpdated, &listed)
+
+	w := getFeedRSS(handler, http.Header{"If-Modified-Since": {itemsUpdated.UTC().Format(http.TimeFormat)}})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = getFeedRSS(handler, http.Header{"If-Modified-Since": {itemsUpdated.Add(-time.Minute).UTC().Format(http.TimeFormat)}})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// If-None-Match takes precedence
+	w = getFeedRSS(handler, http.Header{
+		"If-None-Match":     {`"stale"`},
+		"If-Modified-Since": {itemsUpdated.UTC().Format(http.TimeFormat)},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
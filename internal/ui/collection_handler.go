@@ -0,0 +1,347 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// maxCollectionItems is the number of most recent items a collection's RSS
+// feed carries, whatever the number of feeds it combines
+const maxCollectionItems = 200
+
+// CollectionFeedOption is a feed that can be picked for a collection
+type CollectionFeedOption struct {
+	ID       int64
+	Name     string
+	Selected bool
+}
+
+// GET /collection/new - Show the form to create a collection
+func (h *Handler) handleNewCollection(w http.ResponseWriter, r *http.Request) {
+	h.renderCollectionForm(w, r, db.Collection{}, nil)
+}
+
+// POST /collection/ - Create a collection
+func (h *Handler) handleCreateCollection(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		return
+	}
+
+	name := r.FormValue("name")
+	if name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	feedIDs, err := parseFeedIDs(r.Form["feed_id"])
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.inTx(r.Context(), func(q Querier) error {
+		created, err := q.CreateCollection(r.Context(), name)
+		if err != nil {
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+		return saveCollectionFeeds(r.Context(), q, created.ID, feedIDs)
+	})
+	if err != nil {
+		log.Printf("Failed to create collection %q: %v", name, err)
+		http.Error(w, "Failed to create collection", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// GET /collection/{id}/edit - Show the form to edit a collection
+func (h *Handler) handleEditCollection(w http.ResponseWriter, r *http.Request) {
+	collectionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.queries.GetCollection(r.Context(), collectionID)
+	if err != nil {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return
+	}
+
+	members, err := h.queries.ListCollectionFeeds(r.Context(), collectionID)
+	if err != nil {
+		http.Error(w, "Failed to load collection feeds", http.StatusInternalServerError)
+		return
+	}
+
+	selected := make([]int64, 0, len(members))
+	for _, f := range members {
+		selected = append(selected, f.ID)
+	}
+
+	h.renderCollectionForm(w, r, collection, selected)
+}
+
+// POST /collection/{id}/edit - Update a collection
+func (h *Handler) handleUpdateCollection(w http.ResponseWriter, r *http.Request) {
+	collectionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		return
+	}
+
+	name := r.FormValue("name")
+	if name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	feedIDs, err := parseFeedIDs(r.Form["feed_id"])
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.inTx(r.Context(), func(q Querier) error {
+		if err := q.UpdateCollection(r.Context(), db.UpdateCollectionParams{
+			ID:   collectionID,
+			Name: name,
+		}); err != nil {
+			return fmt.Errorf("failed to update collection: %w", err)
+		}
+		return saveCollectionFeeds(r.Context(), q, collectionID, feedIDs)
+	})
+	if err != nil {
+		log.Printf("Failed to update collection %d: %v", collectionID, err)
+		http.Error(w, "Failed to update collection", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// POST /collection/{id}/delete - Delete a collection, keeping its feeds
+func (h *Handler) handleDeleteCollection(w http.ResponseWriter, r *http.Request) {
+	collectionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.queries.DeleteCollection(r.Context(), collectionID); err != nil {
+		http.Error(w, "Failed to delete collection", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// GET /collection/{id}/rss - Generate RSS XML combining the items of a
+// collection's feeds, newest first
+func (h *Handler) handleCollectionRSS(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	collectionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.queries.GetCollection(ctx, collectionID)
+	if err != nil {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return
+	}
+
+	feeds, err := h.queries.ListCollectionFeeds(ctx, collectionID)
+	if err != nil {
+		http.Error(w, "Failed to load feeds", http.StatusInternalServerError)
+		return
+	}
+
+	if h.combinedNotModified(w, r, feeds, fmt.Sprint(collection.Name, collection.UpdatedAt.Time.UnixNano())) {
+		return
+	}
+
+	rows, err := h.queries.ListCollectionItems(ctx, db.ListCollectionItemsParams{
+		CollectionID: collectionID,
+		MaxItems:     maxFeedItemsLimit,
+	})
+	if err != nil {
+		fmt.Printf("Failed to fetch collection items: %v\n", err)
+		http.Error(w, "Failed to fetch collection items", http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]sourcedItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, sourcedItem{FeedName: row.FeedName, Item: row.FeedItem})
+	}
+
+	h.writeCombinedRSS(w, r, collection.Name,
+		fmt.Sprintf("Items of the feeds in the %s collection", collection.Name), items)
+}
+
+// sourcedItem is an item of a combined feed along with the name of its feed
+type sourcedItem struct {
+	FeedName string
+	Item     db.FeedItem
+}
+
+// writeCombinedRSS sends items of several feeds as one RSS feed, applying
+// each feed's filter rules and tagging items with the name of their feed.
+// Items are fetched up to maxFeedItemsLimit, so that rules changed since they
+// were scraped still leave up to maxCollectionItems of them.
+func (h *Handler) writeCombinedRSS(w http.ResponseWriter, r *http.Request, title, description string, items []sourcedItem) {
+	filters := make(map[int64]feed.Filter)
+	kept := make([]db.FeedItem, 0, len(items))
+	rssItems := make([]Item, 0, len(items))
+	for _, sourced := range items {
+		if len(kept) == maxCollectionItems {
+			break
+		}
+
+		filter, ok := filters[sourced.Item.FeedID]
+		if !ok {
+			var err error
+			filter, err = h.loadFilter(r.Context(), sourced.Item.FeedID)
+			if err != nil {
+				fmt.Printf("Failed to load filter rules: %v\n", err)
+				http.Error(w, "Failed to load filter rules", http.StatusInternalServerError)
+				return
+			}
+			filters[sourced.Item.FeedID] = filter
+		}
+		if keep, _ := filter.Check(itemEntry(sourced.Item)); !keep {
+			continue
+		}
+
+		// Tell where each item comes from, in the title for readers that
+		// ignore categories
+		item := newRSSItem(sourced.Item)
+		item.Title = fmt.Sprintf("[%s] %s", sourced.FeedName, item.Title)
+		item.Categories = []string{sourced.FeedName}
+		// Links are only unique within a feed
+		item.GUID = &GUID{Value: fmt.Sprintf("feed/%d/%s", sourced.Item.FeedID, sourced.Item.Link)}
+		rssItems = append(rssItems, item)
+		kept = append(kept, sourced.Item)
+	}
+
+	writeRSS(w, RSS{
+		Version: "2.0",
+		Channel: Channel{
+			Title:       title,
+			Link:        h.baseURL(r) + "/",
+			Description: description,
+			Language:    defaultLanguage,
+			PubDate:     formatRSSDate(feedUpdated(db.Feed{}, kept)),
+			TTL:         int(feed.RefreshInterval.Minutes()),
+			AtomLink:    &AtomLink{Href: h.baseURL(r) + r.URL.Path, Rel: "self", Type: "application/rss+xml"},
+			Items:       rssItems,
+		},
+	})
+}
+
+func (h *Handler) renderCollectionForm(w http.ResponseWriter, r *http.Request, collection db.Collection, selected []int64) {
+	feeds, err := h.queries.ListFeeds(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to load feeds", http.StatusInternalServerError)
+		return
+	}
+
+	options := make([]CollectionFeedOption, 0, len(feeds))
+	for _, f := range feeds {
+		options = append(options, CollectionFeedOption{
+			ID:       f.ID,
+			Name:     f.Name,
+			Selected: slices.Contains(selected, f.ID),
+		})
+	}
+
+	data := struct {
+		Collection db.Collection
+		Feeds      []CollectionFeedOption
+	}{
+		Collection: collection,
+		Feeds:      options,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.templates.ExecuteTemplate(w, "collection_form.html", data); err != nil {
+		fmt.Printf("Template error: %v\n", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// saveCollectionFeeds replaces the feeds of a collection, with the queries
+// of the transaction writing the collection
+func saveCollectionFeeds(ctx context.Context, q Querier, collectionID int64, feedIDs []int64) error {
+	if err := q.DeleteCollectionFeeds(ctx, collectionID); err != nil {
+		return fmt.Errorf("failed to delete collection feeds: %w", err)
+	}
+
+	for _, feedID := range feedIDs {
+		if err := q.AddCollectionFeed(ctx, db.AddCollectionFeedParams{
+			CollectionID: collectionID,
+			FeedID:       feedID,
+		}); err != nil {
+			return fmt.Errorf("failed to add feed %d: %w", feedID, err)
+		}
+	}
+
+	return nil
+}
+
+// parseFeedIDs parses the feed IDs submitted by a form
+func parseFeedIDs(values []string) ([]int64, error) {
+	ids := make([]int64, 0, len(values))
+	for _, v := range values {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// baseURL returns the absolute URL the app is served at: the configured
+// base URL, or else the scheme and host the request was made to
+func (h *Handler) baseURL(r *http.Request) string {
+	if h.config != nil && h.config.BaseURL != "" {
+		return h.config.BaseURL
+	}
+	return requestBaseURL(r)
+}
+
+// requestBaseURL returns the scheme and host the request was made to
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// siteURL returns the page a feed's outputs link back to: its URL with the
+// placeholders resolved for now, the newest page a templated URL covers
+func (h *Handler) siteURL(rawURL string) string {
+	return feed.ExpandURL(rawURL, time.Now().In(h.location))[0]
+}
@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleCollectionRSS(t *testing.T) {
+	mockQ := &mockQueries{
+		GetCollectionFn: func(ctx context.Context, id int64) (db.Collection, error) {
+			return db.Collection{ID: id, Name: "Team"}, nil
+		},
+		ListCollectionItemsFn: func(ctx context.Context, arg db.ListCollectionItemsParams) ([]db.ListCollectionItemsRow, error) {
+			assert.Equal(t, int64(3), arg.CollectionID)
+			// Items are filtered before the feed is capped
+			assert.Equal(t, int64(maxFeedItemsLimit), arg.MaxItems)
+			return []db.ListCollectionItemsRow{
+				{FeedName: "Jobs", FeedItem: db.FeedItem{FeedID: 1, Title: "Go Developer", Link: "https://jobs.example.com/1",
+					Date:      sql.NullTime{Time: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Valid: true},
+					UpdatedAt: sql.NullTime{Time: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), Valid: true}}},
+				{FeedName: "News", FeedItem: db.FeedItem{FeedID: 2, Title: "Release notes", Link: "https://news.example.com/1",
+					Date: sql.NullTime{Time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}}},
+				{FeedName: "Jobs", FeedItem: db.FeedItem{FeedID: 1, Title: "PHP Developer", Link: "https://jobs.example.com/2",
+					Date:      sql.NullTime{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					UpdatedAt: sql.NullTime{Time: time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC), Valid: true}}},
+			}, nil
+		},
+		ListFeedFilterRulesFn: func(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error) {
+			if feedID == 1 {
+				return []db.FeedFilterRule{{FeedID: 1, Field: "title", Operator: "contains", Value: "Go"}}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "/collection/3/rss", nil)
+	req.SetPathValue("id", "3")
+	w := httptest.NewRecorder()
+
+	handler.handleCollectionRSS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/rss+xml; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var rss RSS
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &rss))
+	assert.Equal(t, "Team", rss.Channel.Title)
+	assert.Equal(t, "http://example.com/", rss.Channel.Link)
+	// Dated by the newest change among the items published
+	assert.Equal(t, "Sun, 05 Jan 2025 00:00:00 +0000", rss.Channel.PubDate)
+
+	// The feed's filter rules still drop the PHP job
+	if assert.Len(t, rss.Channel.Items, 2) {
+		assert.Equal(t, "[Jobs] Go Developer", rss.Channel.Items[0].Title)
+		assert.Equal(t, []string{"Jobs"}, rss.Channel.Items[0].Categories)
+		// Links may repeat across feeds, so GUIDs carry the feed
+		assert.Equal(t, &GUID{Value: "feed/1/https://jobs.example.com/1"}, rss.Channel.Items[0].GUID)
+		assert.Equal(t, "[News] Release notes", rss.Channel.Items[1].Title)
+		assert.Equal(t, []string{"News"}, rss.Channel.Items[1].Categories)
+	}
+}
+
+func TestHandleCollectionRSSNotModified(t *testing.T) {
+	refreshed := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	itemsCount := int64(2)
+	fetched := 0
+	mockQ := &mockQueries{
+		GetCollectionFn: func(ctx context.Context, id int64) (db.Collection, error) {
+			return db.Collection{ID: id, Name: "Team"}, nil
+		},
+		ListCollectionFeedsFn: func(ctx context.Context, collectionID int64) ([]db.Feed, error) {
+			return []db.Feed{
+				{ID: 1, LastRefreshedAt: sql.NullTime{Time: refreshed, Valid: true}},
+				{ID: 2, LastRefreshedAt: sql.NullTime{Time: refreshed.Add(-30 * time.Minute), Valid: true}},
+			}, nil
+		},
+		GetFeedItemsVersionFn: func(ctx context.Context, feedID int64) (db.GetFeedItemsVersionRow, error) {
+			return db.GetFeedItemsVersionRow{ItemsCount: itemsCount}, nil
+		},
+		ListCollectionItemsFn: func(ctx context.Context, arg db.ListCollectionItemsParams) ([]db.ListCollectionItemsRow, error) {
+			fetched++
+			return nil, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/collection/3/rss", nil)
+		req.SetPathValue("id", "3")
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		handler.handleCollectionRSS(w, req)
+		return w
+	}
+
+	w := get("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, refreshed.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+	// Cached until the next of the feeds is refreshed
+	var maxAge int
+	_, err := fmt.Sscanf(w.Header().Get("Cache-Control"), "public, max-age=%d", &maxAge)
+	assert.NoError(t, err)
+	assert.InDelta(t, 20*60, maxAge, 5)
+
+	w = get(etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, 1, fetched)
+
+	// Any change to one of the feeds' items is a new version
+	itemsCount = 3
+	w = get(etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestHandleCollectionRSSNotFound(t *testing.T) {
+	handler := NewHandler(&mockQueries{}, nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "/collection/9/rss", nil)
+	req.SetPathValue("id", "9")
+	w := httptest.NewRecorder()
+
+	handler.handleCollectionRSS(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleCreateCollection(t *testing.T) {
+	var added []db.AddCollectionFeedParams
+	mockQ := &mockQueries{
+		CreateCollectionFn: func(ctx context.Context, name string) (db.Collection, error) {
+			assert.Equal(t, "Team", name)
+			return db.Collection{ID: 5, Name: name}, nil
+		},
+		AddCollectionFeedFn: func(ctx context.Context, arg db.AddCollectionFeedParams) error {
+			added = append(added, arg)
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Team")
+	form.Add("feed_id", "1")
+	form.Add("feed_id", "4")
+
+	req := httptest.NewRequest("POST", "/collection/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateCollection(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, []db.AddCollectionFeedParams{
+		{CollectionID: 5, FeedID: 1},
+		{CollectionID: 5, FeedID: 4},
+	}, added)
+}
+
+func TestHandleEditCollection(t *testing.T) {
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	mockQ := &mockQueries{
+		GetCollectionFn: func(ctx context.Context, id int64) (db.Collection, error) {
+			return db.Collection{ID: id, Name: "Team"}, nil
+		},
+		ListCollectionFeedsFn: func(ctx context.Context, collectionID int64) ([]db.Feed, error) {
+			return []db.Feed{{ID: 2, Name: "News"}}, nil
+		},
+		ListFeedsFn: func(ctx context.Context) ([]db.Feed, error) {
+			return []db.Feed{{ID: 1, Name: "Jobs"}, {ID: 2, Name: "News"}}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, tmpl, nil, cfg)
+
+	req := httptest.NewRequest("GET", "/collection/3/edit", nil)
+	req.SetPathValue("id", "3")
+	w := httptest.NewRecorder()
+
+	handler.handleEditCollection(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `action="/collection/3/edit"`)
+	assert.Contains(t, body, `value="1" >`)
+	assert.Contains(t, body, `value="2" checked>`)
+}
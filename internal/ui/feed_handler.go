@@ -2,19 +2,27 @@ package ui
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
 )
 
+// previewSampleSize is the number of matched items the preview evaluates
+const previewSampleSize = 10
+
 func (h *Handler) handleNewFeed(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -43,22 +51,68 @@ func (h *Handler) handleDuplicateFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, err := h.loadFilter(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to load filter rules", http.StatusInternalServerError)
+		return
+	}
+
+	auth, err := h.loadAuth(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to load authentication", http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := h.loadTags(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to load tags", http.StatusInternalServerError)
+		return
+	}
+
 	data := struct {
-		ID            int64
-		Name          string
-		Url           string
-		ItemSelector  string
-		TitleSelector string
-		LinkSelector  string
-		DateSelector  string
+		ID                int64
+		Name              string
+		Url               string
+		ExtraURLs         string
+		ItemSelector      string
+		TitleSelector     string
+		LinkSelector      string
+		DateSelector      string
+		Mode              string
+		DescriptionFormat string
+		SourceType        string
+		SourceTypes       []string
+		RemoveSelectors   string
+		Filters           string
+		FilterExpression  string
+		MapExpression     string
+		Patterns          PatternSettings
+		Language          string
+		Description       string
+		Auth              AuthSettings
+		Tags              string
 	}{
-		ID:            feed.ID,
-		Name:          feed.Name + " (copy)",
-		Url:           feed.Url,
-		ItemSelector:  nullStringToString(feed.ItemSelector),
-		TitleSelector: nullStringToString(feed.TitleSelector),
-		LinkSelector:  nullStringToString(feed.LinkSelector),
-		DateSelector:  nullStringToString(feed.DateSelector),
+		ID:                feed.ID,
+		Name:              feed.Name + " (copy)",
+		Url:               feed.Url,
+		ExtraURLs:         nullStringToString(feed.ExtraUrls),
+		ItemSelector:      nullStringToString(feed.ItemSelector),
+		TitleSelector:     nullStringToString(feed.TitleSelector),
+		LinkSelector:      nullStringToString(feed.LinkSelector),
+		DateSelector:      nullStringToString(feed.DateSelector),
+		Mode:              feed.Mode,
+		DescriptionFormat: feed.DescriptionFormat,
+		SourceType:        feed.SourceType,
+		SourceTypes:       h.sourceTypes(),
+		RemoveSelectors:   nullStringToString(feed.RemoveSelectors),
+		Filters:           filter.String(),
+		FilterExpression:  nullStringToString(feed.FilterExpression),
+		MapExpression:     nullStringToString(feed.MapExpression),
+		Patterns:          patternsFromFeed(feed),
+		Language:          nullStringToString(feed.Language),
+		Description:       nullStringToString(feed.Description),
+		Auth:              formAuth(auth),
+		Tags:              tags,
 	}
 
 	h.renderNewFeed(w, data)
@@ -93,11 +147,24 @@ func (h *Handler) handlePreviewFeed(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "URL required", http.StatusBadRequest)
 		return
 	}
+	if err := feed.ValidateURLTemplate(feedURL); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid URL: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	itemSelector := r.FormValue("item_selector")
 	titleSelector := r.FormValue("title_selector")
 	linkSelector := r.FormValue("link_selector")
 	dateSelector := r.FormValue("date_selector")
+	descriptionSelector := r.FormValue("description_selector")
+	mode := feed.NormalizeMode(r.FormValue("mode"))
+	descriptionFormat := feed.NormalizeDescriptionFormat(r.FormValue("description_format"))
+	sourceType := feed.NormalizeSourceType(r.FormValue("source_type"))
+	removeSelectors := r.FormValue("remove_selectors")
+	filters := r.FormValue("filters")
+	filterExpression := r.FormValue("filter_expression")
+	mapExpression := r.FormValue("map_expression")
+	patterns := patternsFromForm(r)
 
 	existingSelectorIDStr := r.FormValue("existing_selector_id")
 
@@ -118,24 +185,47 @@ func (h *Handler) handlePreviewFeed(w http.ResponseWriter, r *http.Request) {
 		titleSelector = nullStringToString(template_feed.TitleSelector)
 		linkSelector = nullStringToString(template_feed.LinkSelector)
 		dateSelector = nullStringToString(template_feed.DateSelector)
+		descriptionSelector = nullStringToString(template_feed.DescriptionSelector)
+		mode = feed.NormalizeMode(template_feed.Mode)
+		descriptionFormat = feed.NormalizeDescriptionFormat(template_feed.DescriptionFormat)
+		sourceType = feed.NormalizeSourceType(template_feed.SourceType)
+		removeSelectors = nullStringToString(template_feed.RemoveSelectors)
+		filterExpression = nullStringToString(template_feed.FilterExpression)
+		mapExpression = nullStringToString(template_feed.MapExpression)
+		patterns = patternsFromFeed(template_feed)
+
+		filter, err := h.loadFilter(r.Context(), existingSelectorID)
+		if err != nil {
+			http.Error(w, "Failed to load existing filter rules", http.StatusInternalServerError)
+			return
+		}
+		filters = filter.String()
 	}
 
-	// Fetch URL
-	resp, err := http.Get(feedURL)
+	// Resolve URL placeholders as the refresher would, and fetch the newest
+	// page that exists, logged in as the feed will be
+	resolvedURLs := feed.ExpandURL(feedURL, time.Now().In(h.location))
+	stored, err := h.sourceAuth(r.Context(), r)
 	if err != nil {
-		log.Printf("failed to fetch URL %s: %v", feedURL, err)
-		http.Error(w, fmt.Sprintf("failed to fetch URL: %v", err), http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("Invalid authentication: %v", err), http.StatusBadRequest)
 		return
 	}
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("failed to fetch URL %s: status %d", feedURL, resp.StatusCode)
-		http.Error(w, fmt.Sprintf("failed to fetch URL: status %d", resp.StatusCode), http.StatusBadRequest)
+	resp, err := fetchFirstPage(r.Context(), feed.NewSession(keepSecrets(authFromForm(r), stored), nil), resolvedURLs)
+	if err != nil {
+		log.Printf("failed to fetch URL %s: %v", feedURL, err)
+		http.Error(w, fmt.Sprintf("failed to fetch URL: %v", err), http.StatusBadRequest)
 		return
 	}
 	defer func() {
 		err = resp.Body.Close()
 		_ = fmt.Errorf("failed to close response body: %w", err)
 	}()
+	feedURL = resp.Request.URL.String()
+
+	var templateURLs []string
+	if feed.IsTemplateURL(r.FormValue("url")) {
+		templateURLs = resolvedURLs
+	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -151,34 +241,90 @@ func (h *Handler) handlePreviewFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// First matched item
-	first := doc.Find(itemSelector).First()
-	firstTitle := strings.TrimSpace(first.Find(titleSelector).Text())
-	firstLink, _ := first.Find(linkSelector).Attr("href")
-	if firstLink == "" {
-		firstLink = strings.TrimSpace(first.Find(linkSelector).Text())
+	// Extract the items as the refresher would, selectors and other source
+	// types alike, and show the first as it would be stored
+	base, _ := url.Parse(feedURL)
+	previewFeed := db.Feed{
+		ItemSelector:        sql.NullString{String: itemSelector, Valid: itemSelector != ""},
+		TitleSelector:       sql.NullString{String: titleSelector, Valid: titleSelector != ""},
+		LinkSelector:        sql.NullString{String: linkSelector, Valid: linkSelector != ""},
+		DateSelector:        sql.NullString{String: dateSelector, Valid: dateSelector != ""},
+		DescriptionSelector: sql.NullString{String: descriptionSelector, Valid: descriptionSelector != ""},
+		Mode:                mode,
+		SourceType:          sourceType,
+		DescriptionFormat:   descriptionFormat,
+		RemoveSelectors:     sql.NullString{String: removeSelectors, Valid: removeSelectors != ""},
+	}
+	patterns.apply(&previewFeed)
+
+	var extracted []feed.ExtractedItem
+	var extractErr error
+	switch {
+	case sourceType == feed.SourceCSS:
+		extracted = h.feedService.ExtractItems(previewFeed, doc, base)
+	case mode != feed.ModeMonitor:
+		extracted, extractErr = h.feedService.Extract(previewFeed, feed.Page{URL: base, Body: bodyBytes, Doc: doc})
+	}
+
+	var firstTitle, firstLink, firstHTML, firstDate string
+	if len(extracted) > 0 {
+		firstTitle = extracted[0].Title
+		firstLink = extracted[0].Link
+		firstHTML = extracted[0].Description
+		if !extracted[0].Date.IsZero() {
+			firstDate = extracted[0].Date.Format(time.DateOnly)
+		}
 	}
 
-	firstDate := ""
-	if dateSelector != "" {
-		firstDate = strings.TrimSpace(first.Find(dateSelector).Text())
-		if idx := strings.Index(firstDate, " "); idx != -1 {
-			firstDate = firstDate[:idx]
+	// Evaluate the filter rules and expressions against the first matched
+	// items, to show which would be dropped and how they would be rewritten
+	filter, filterErr := feed.ParseFilter(filters)
+	hasFilter := filterErr == nil && len(filter) > 0
+	expressions, expressionErr := feed.CompileExpressions(filterExpression, mapExpression)
+	hasExpressions := expressionErr == nil && expressions != nil
+
+	var samples []feed.ExtractedItem
+	if mode != feed.ModeMonitor {
+		samples = extracted[:min(previewSampleSize, len(extracted))]
+	}
+
+	// Rules are checked against the items as rewritten by the mapping
+	// expression, as refreshes do; items dropped by the expressions never
+	// reach them
+	var filterResults []FilterResult
+	var expressionResults []ExpressionResult
+	for _, sample := range samples {
+		check := feed.CheckItem(sample, expressions, filter)
+
+		if hasFilter && check.RulesChecked() {
+			filterResults = append(filterResults, FilterResult{
+				Title:  check.Item.Title,
+				Link:   check.Item.Link,
+				Kept:   check.Rule == "",
+				Reason: check.Rule,
+			})
 		}
-	}
 
-	// Absolute link if needed
-	if firstLink != "" {
-		base, err := url.Parse(feedURL)
-		if err == nil {
-			rel, err := url.Parse(firstLink)
-			if err == nil {
-				firstLink = base.ResolveReference(rel).String()
+		if hasExpressions {
+			result := ExpressionResult{
+				Title:       sample.Title,
+				Link:        sample.Link,
+				Kept:        check.RulesChecked(),
+				MappedTitle: check.Item.Title,
+				MappedLink:  check.Item.Link,
 			}
+			if check.Err != nil {
+				result.Error = check.Err.Error()
+			}
+			expressionResults = append(expressionResults, result)
 		}
 	}
 
-	firstHTML, _ := first.Html()
+	// In monitor mode the preview shows what would be snapshotted
+	var snapshot string
+	if mode == feed.ModeMonitor && itemSelector != "" {
+		snapshot = feed.RegionSnapshot(doc, itemSelector)
+	}
 
 	// Render Step 2 HTML
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -197,14 +343,31 @@ func (h *Handler) handlePreviewFeed(w http.ResponseWriter, r *http.Request) {
 	type PageData struct {
 		ExistingSelectors []Selector
 		// any other fields, e.g. for preview
-		ItemSelector  string
-		TitleSelector string
-		LinkSelector  string
-		DateSelector  string
-		FirstHTML     string
-		FirstTitle    string
-		FirstLink     string
-		FirstDate     string
+		Mode              string
+		DescriptionFormat string
+		SourceType        string
+		SourceTypes       []string
+		RemoveSelectors   string
+		ItemSelector      string
+		TitleSelector     string
+		LinkSelector      string
+		DateSelector      string
+		FirstHTML         string
+		FirstTitle        string
+		FirstLink         string
+		FirstDate         string
+		ExtractError      string
+		Snapshot          string
+		Filters           string
+		FilterError       string
+		FilterResults     []FilterResult
+		FilterExpression  string
+		MapExpression     string
+		ExpressionError   string
+		ExpressionResults []ExpressionResult
+		Patterns          PatternSettings
+		// ResolvedURLs lists the pages a templated URL expands to
+		ResolvedURLs []string
 	}
 
 	ExistingSelectors, err := h.queries.ListFeeds(r.Context())
@@ -229,6 +392,11 @@ func (h *Handler) handlePreviewFeed(w http.ResponseWriter, r *http.Request) {
 
 	data := PageData{
 		ExistingSelectors: selectors,
+		Mode:              mode,
+		DescriptionFormat: descriptionFormat,
+		SourceType:        sourceType,
+		SourceTypes:       h.sourceTypes(),
+		RemoveSelectors:   removeSelectors,
 		ItemSelector:      itemSelector,
 		TitleSelector:     titleSelector,
 		LinkSelector:      linkSelector,
@@ -237,6 +405,23 @@ func (h *Handler) handlePreviewFeed(w http.ResponseWriter, r *http.Request) {
 		FirstTitle:        firstTitle,
 		FirstLink:         firstLink,
 		FirstDate:         firstDate,
+		Snapshot:          snapshot,
+		Filters:           filters,
+		FilterResults:     filterResults,
+		FilterExpression:  filterExpression,
+		MapExpression:     mapExpression,
+		ExpressionResults: expressionResults,
+		Patterns:          patterns,
+		ResolvedURLs:      templateURLs,
+	}
+	if filterErr != nil {
+		data.FilterError = filterErr.Error()
+	}
+	if expressionErr != nil {
+		data.ExpressionError = expressionErr.Error()
+	}
+	if extractErr != nil {
+		data.ExtractError = extractErr.Error()
 	}
 
 	// lets use feed-selector-partial.html
@@ -266,23 +451,107 @@ func (h *Handler) handleCreateFeed(w http.ResponseWriter, r *http.Request) {
 	title_selector := r.FormValue("title_selector")
 	link_selector := r.FormValue("link_selector")
 	date_selector := r.FormValue("date_selector")
+	mode := feed.NormalizeMode(r.FormValue("mode"))
+	descriptionFormat := feed.NormalizeDescriptionFormat(r.FormValue("description_format"))
+	sourceType := feed.NormalizeSourceType(r.FormValue("source_type"))
+	removeSelectors := strings.Join(feed.ParseRemoveSelectors(r.FormValue("remove_selectors")), "\n")
 
 	if name == "" || url == "" {
 		http.Error(w, "Name and URL are required", http.StatusBadRequest)
 		return
 	}
+	if err := feed.ValidateURLTemplate(url); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid URL: %v", err), http.StatusBadRequest)
+		return
+	}
 
-	// Insert the new feed into the database
-	_, err := h.queries.CreateFeed(r.Context(), db.CreateFeedParams{
-		Name:          name,
-		Url:           url,
-		ItemSelector:  sql.NullString{String: item_selector, Valid: item_selector != ""},
-		TitleSelector: sql.NullString{String: title_selector, Valid: title_selector != ""},
-		LinkSelector:  sql.NullString{String: link_selector, Valid: link_selector != ""},
-		DateSelector:  sql.NullString{String: date_selector, Valid: date_selector != ""},
-	})
+	if !slices.Contains(h.sourceTypes(), sourceType) {
+		http.Error(w, fmt.Sprintf("Unknown source type %q", sourceType), http.StatusBadRequest)
+		return
+	}
 
+	filter, err := feed.ParseFilter(r.FormValue("filters"))
 	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid filter rules: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	filterExpression := r.FormValue("filter_expression")
+	mapExpression := r.FormValue("map_expression")
+	if _, err := feed.CompileExpressions(filterExpression, mapExpression); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid expressions: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	patterns := patternsFromForm(r)
+	if err := patterns.Validate(sourceType); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid patterns: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	language := strings.TrimSpace(r.FormValue("language"))
+	if language != "" && !languageTag.MatchString(language) {
+		http.Error(w, fmt.Sprintf("Invalid language %q", language), http.StatusBadRequest)
+		return
+	}
+	description := strings.TrimSpace(r.FormValue("description"))
+
+	extraURLList, err := feed.ParseExtraURLs(r.FormValue("extra_urls"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid additional URLs: %v", err), http.StatusBadRequest)
+		return
+	}
+	extraURLs := strings.Join(extraURLList, "\n")
+
+	stored, err := h.sourceAuth(r.Context(), r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid authentication: %v", err), http.StatusBadRequest)
+		return
+	}
+	auth := keepSecrets(authFromForm(r), stored)
+	if err := auth.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid authentication: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Insert the new feed into the database, along with its settings
+	err = h.inTx(r.Context(), func(q Querier) error {
+		created, err := q.CreateFeed(r.Context(), db.CreateFeedParams{
+			Name:                name,
+			Url:                 url,
+			ExtraUrls:           sql.NullString{String: extraURLs, Valid: extraURLs != ""},
+			SourceType:          sourceType,
+			ItemSelector:        sql.NullString{String: item_selector, Valid: item_selector != ""},
+			TitleSelector:       sql.NullString{String: title_selector, Valid: title_selector != ""},
+			LinkSelector:        sql.NullString{String: link_selector, Valid: link_selector != ""},
+			DateSelector:        sql.NullString{String: date_selector, Valid: date_selector != ""},
+			Mode:                mode,
+			DescriptionFormat:   descriptionFormat,
+			RemoveSelectors:     sql.NullString{String: removeSelectors, Valid: removeSelectors != ""},
+			FilterExpression:    sql.NullString{String: filterExpression, Valid: filterExpression != ""},
+			MapExpression:       sql.NullString{String: mapExpression, Valid: mapExpression != ""},
+			GlobalPattern:       sql.NullString{String: patterns.GlobalPattern, Valid: patterns.GlobalPattern != ""},
+			ItemPattern:         sql.NullString{String: patterns.ItemPattern, Valid: patterns.ItemPattern != ""},
+			TitleTemplate:       sql.NullString{String: patterns.TitleTemplate, Valid: patterns.TitleTemplate != ""},
+			LinkTemplate:        sql.NullString{String: patterns.LinkTemplate, Valid: patterns.LinkTemplate != ""},
+			DescriptionTemplate: sql.NullString{String: patterns.DescriptionTemplate, Valid: patterns.DescriptionTemplate != ""},
+			DateTemplate:        sql.NullString{String: patterns.DateTemplate, Valid: patterns.DateTemplate != ""},
+			Language:            sql.NullString{String: language, Valid: language != ""},
+			Description:         sql.NullString{String: description, Valid: description != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create feed: %w", err)
+		}
+		if err := saveFilter(r.Context(), q, created.ID, filter); err != nil {
+			return err
+		}
+		if err := saveAuth(r.Context(), q, created.ID, auth); err != nil {
+			return err
+		}
+		return saveTags(r.Context(), q, created.ID, parseTags(r.FormValue("tags")))
+	})
+	if err != nil {
+		log.Printf("Failed to create feed %q: %v", name, err)
 		http.Error(w, "Failed to create feed", http.StatusInternalServerError)
 		return
 	}
@@ -312,24 +581,74 @@ func (h *Handler) handleEditFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, err := h.loadFilter(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to load filter rules", http.StatusInternalServerError)
+		return
+	}
+
+	auth, err := h.loadAuth(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to load authentication", http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := h.loadTags(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to load tags", http.StatusInternalServerError)
+		return
+	}
+
 	var data = struct {
 		ID                  int64
 		Name                string
 		Url                 string
+		ExtraURLs           string
 		ItemSelector        string
 		TitleSelector       string
 		LinkSelector        string
 		DescriptionSelector string
 		DateSelector        string
+		Mode                string
+		DescriptionFormat   string
+		SourceType          string
+		SourceTypes         []string
+		RemoveSelectors     string
+		Filters             string
+		FilterExpression    string
+		MapExpression       string
+		RetentionMaxItems   string
+		RetentionMaxAgeDays string
+		Patterns            PatternSettings
+		Language            string
+		Description         string
+		Auth                AuthSettings
+		Tags                string
 	}{
 		ID:                  feed.ID,
 		Name:                feed.Name,
 		Url:                 feed.Url,
+		ExtraURLs:           nullStringToString(feed.ExtraUrls),
 		ItemSelector:        nullStringToString(feed.ItemSelector),
 		TitleSelector:       nullStringToString(feed.TitleSelector),
 		LinkSelector:        nullStringToString(feed.LinkSelector),
 		DescriptionSelector: nullStringToString(feed.DescriptionSelector),
 		DateSelector:        nullStringToString(feed.DateSelector),
+		Mode:                feed.Mode,
+		DescriptionFormat:   feed.DescriptionFormat,
+		SourceType:          feed.SourceType,
+		SourceTypes:         h.sourceTypes(),
+		RemoveSelectors:     nullStringToString(feed.RemoveSelectors),
+		Filters:             filter.String(),
+		FilterExpression:    nullStringToString(feed.FilterExpression),
+		MapExpression:       nullStringToString(feed.MapExpression),
+		RetentionMaxItems:   nullInt64ToString(feed.RetentionMaxItems),
+		RetentionMaxAgeDays: nullInt64ToString(feed.RetentionMaxAgeDays),
+		Patterns:            patternsFromFeed(feed),
+		Language:            nullStringToString(feed.Language),
+		Description:         nullStringToString(feed.Description),
+		Auth:                formAuth(auth),
+		Tags:                tags,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -369,24 +688,129 @@ func (h *Handler) handleUpdateFeed(w http.ResponseWriter, r *http.Request) {
 	title_selector := r.FormValue("title_selector")
 	link_selector := r.FormValue("link_selector")
 	date_selector := r.FormValue("date_selector")
+	mode := feed.NormalizeMode(r.FormValue("mode"))
+	descriptionFormat := feed.NormalizeDescriptionFormat(r.FormValue("description_format"))
+	sourceType := feed.NormalizeSourceType(r.FormValue("source_type"))
+	removeSelectors := strings.Join(feed.ParseRemoveSelectors(r.FormValue("remove_selectors")), "\n")
 
 	if name == "" || url == "" {
 		http.Error(w, "Name and URL are required", http.StatusBadRequest)
 		return
 	}
+	if err := feed.ValidateURLTemplate(url); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid URL: %v", err), http.StatusBadRequest)
+		return
+	}
 
-	// Update the feed in the database
-	err = h.queries.UpdateFeed(r.Context(), db.UpdateFeedParams{
-		ID:            feedID,
-		Name:          name,
-		Url:           url,
-		ItemSelector:  sql.NullString{String: item_selector, Valid: item_selector != ""},
-		TitleSelector: sql.NullString{String: title_selector, Valid: title_selector != ""},
-		LinkSelector:  sql.NullString{String: link_selector, Valid: link_selector != ""},
-		DateSelector:  sql.NullString{String: date_selector, Valid: date_selector != ""},
-	})
+	if !slices.Contains(h.sourceTypes(), sourceType) {
+		http.Error(w, fmt.Sprintf("Unknown source type %q", sourceType), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := feed.ParseFilter(r.FormValue("filters"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid filter rules: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	filterExpression := r.FormValue("filter_expression")
+	mapExpression := r.FormValue("map_expression")
+	if _, err := feed.CompileExpressions(filterExpression, mapExpression); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid expressions: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	patterns := patternsFromForm(r)
+	if err := patterns.Validate(sourceType); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid patterns: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	language := strings.TrimSpace(r.FormValue("language"))
+	if language != "" && !languageTag.MatchString(language) {
+		http.Error(w, fmt.Sprintf("Invalid language %q", language), http.StatusBadRequest)
+		return
+	}
+	description := strings.TrimSpace(r.FormValue("description"))
+
+	extraURLList, err := feed.ParseExtraURLs(r.FormValue("extra_urls"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid additional URLs: %v", err), http.StatusBadRequest)
+		return
+	}
+	extraURLs := strings.Join(extraURLList, "\n")
+
+	stored, err := h.loadAuth(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to load authentication", http.StatusInternalServerError)
+		return
+	}
+	auth := keepSecrets(authFromForm(r), stored)
+	if err := auth.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid authentication: %v", err), http.StatusBadRequest)
+		return
+	}
 
+	retentionMaxItems, err := parseOptionalInt(r.FormValue("retention_max_items"))
 	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid maximum items: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	retentionMaxAgeDays, err := parseOptionalInt(r.FormValue("retention_max_age_days"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid maximum age: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Update the feed in the database, along with its settings
+	err = h.inTx(r.Context(), func(q Querier) error {
+		if err := q.UpdateFeed(r.Context(), db.UpdateFeedParams{
+			ID:                  feedID,
+			Name:                name,
+			Url:                 url,
+			ExtraUrls:           sql.NullString{String: extraURLs, Valid: extraURLs != ""},
+			SourceType:          sourceType,
+			ItemSelector:        sql.NullString{String: item_selector, Valid: item_selector != ""},
+			TitleSelector:       sql.NullString{String: title_selector, Valid: title_selector != ""},
+			LinkSelector:        sql.NullString{String: link_selector, Valid: link_selector != ""},
+			DateSelector:        sql.NullString{String: date_selector, Valid: date_selector != ""},
+			Mode:                mode,
+			DescriptionFormat:   descriptionFormat,
+			RemoveSelectors:     sql.NullString{String: removeSelectors, Valid: removeSelectors != ""},
+			FilterExpression:    sql.NullString{String: filterExpression, Valid: filterExpression != ""},
+			MapExpression:       sql.NullString{String: mapExpression, Valid: mapExpression != ""},
+			GlobalPattern:       sql.NullString{String: patterns.GlobalPattern, Valid: patterns.GlobalPattern != ""},
+			ItemPattern:         sql.NullString{String: patterns.ItemPattern, Valid: patterns.ItemPattern != ""},
+			TitleTemplate:       sql.NullString{String: patterns.TitleTemplate, Valid: patterns.TitleTemplate != ""},
+			LinkTemplate:        sql.NullString{String: patterns.LinkTemplate, Valid: patterns.LinkTemplate != ""},
+			DescriptionTemplate: sql.NullString{String: patterns.DescriptionTemplate, Valid: patterns.DescriptionTemplate != ""},
+			DateTemplate:        sql.NullString{String: patterns.DateTemplate, Valid: patterns.DateTemplate != ""},
+			Language:            sql.NullString{String: language, Valid: language != ""},
+			Description:         sql.NullString{String: description, Valid: description != ""},
+		}); err != nil {
+			return fmt.Errorf("failed to update feed: %w", err)
+		}
+		if err := saveFilter(r.Context(), q, feedID, filter); err != nil {
+			return err
+		}
+		if err := saveAuth(r.Context(), q, feedID, auth); err != nil {
+			return err
+		}
+		if err := saveTags(r.Context(), q, feedID, parseTags(r.FormValue("tags"))); err != nil {
+			return err
+		}
+		if err := q.UpdateFeedRetention(r.Context(), db.UpdateFeedRetentionParams{
+			ID:                  feedID,
+			RetentionMaxItems:   retentionMaxItems,
+			RetentionMaxAgeDays: retentionMaxAgeDays,
+		}); err != nil {
+			return fmt.Errorf("failed to update feed retention: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to update feed %d: %v", feedID, err)
 		http.Error(w, "Failed to update feed", http.StatusInternalServerError)
 		return
 	}
@@ -415,6 +839,12 @@ func (h *Handler) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if store := h.responseStore(); store != nil {
+		if err := store.DeleteFeed(feedID); err != nil {
+			log.Printf("Failed to delete stored responses of feed %d: %v", feedID, err)
+		}
+	}
+
 	// Redirect back to the homepage after successful deletion
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
@@ -440,6 +870,13 @@ func (h *Handler) handleResetFeedItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Forget the items removed by retention too, so that the next refresh
+	// starts over
+	if err := h.queries.DeleteFeedItemTombstones(r.Context(), feedID); err != nil {
+		http.Error(w, "Failed to reset feed items", http.StatusInternalServerError)
+		return
+	}
+
 	// Redirect back to the homepage after successful reset
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
@@ -481,3 +918,55 @@ func nullStringToString(ns sql.NullString) string {
 	}
 	return ""
 }
+
+func nullInt64ToString(ni sql.NullInt64) string {
+	if ni.Valid {
+		return strconv.FormatInt(ni.Int64, 10)
+	}
+	return ""
+}
+
+// languageTag matches the language codes of RSS channels, e.g. "en" or "pt-BR"
+var languageTag = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{1,8})*$`)
+
+// parseOptionalInt parses a non-negative integer form value; empty means unset
+func parseOptionalInt(s string) (sql.NullInt64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return sql.NullInt64{}, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return sql.NullInt64{}, fmt.Errorf("invalid number %q", s)
+	}
+	return sql.NullInt64{Int64: n, Valid: true}, nil
+}
+
+// fetchFirstPage returns the first of the URLs answering 200 OK, so that a
+// dated URL can be previewed even before today's page is published
+func fetchFirstPage(ctx context.Context, session *feed.Session, urls []string) (*http.Response, error) {
+	var lastErr error
+	for _, u := range urls {
+		resp, err := session.Get(ctx, u)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			lastErr = fmt.Errorf("status %d", resp.StatusCode)
+			continue
+		}
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// sourceTypes returns the source types feeds can use: those with a
+// registered extractor
+func (h *Handler) sourceTypes() []string {
+	if h.feedService == nil {
+		return []string{feed.SourceCSS}
+	}
+	return h.feedService.SourceTypes()
+}
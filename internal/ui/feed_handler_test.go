@@ -3,15 +3,18 @@ package ui
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/alessandrocuzzocrea/web2rss/internal/config"
 	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,7 +50,7 @@ func TestHandlePreviewFeed(t *testing.T) {
 	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
 	assert.NoError(t, err)
 
-	handler := NewHandler(mockQ, tmpl, nil, cfg)
+	handler := NewHandler(mockQ, tmpl, feed.NewService(nil), cfg)
 
 	// Create a test request
 	form := url.Values{}
@@ -77,7 +80,7 @@ func TestHandlePreviewFeedInvalidURL(t *testing.T) {
 	}
 	cfg := &config.Config{Timezone: "UTC"}
 
-	handler := NewHandler(mockQ, nil, nil, cfg)
+	handler := NewHandler(mockQ, nil, feed.NewService(nil), cfg)
 
 	form := url.Values{}
 	form.Add("url", "http://nonexistent-website-123.com")
@@ -132,3 +135,837 @@ func TestHandleDuplicateFeed(t *testing.T) {
 	assert.Contains(t, body, "hx-trigger=\"change delay:500ms, load\"")
 	assert.Contains(t, body, "name=\"item_selector\" value=\".item\"")
 }
+
+func TestHandlePreviewFeedMonitorMode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><div class="terms"><p>Clause   one</p><p>Clause two</p></div></body></html>`)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("mode", "monitor")
+	form.Add("item_selector", ".terms")
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Region Selector")
+	assert.Contains(t, body, "Clause one\nClause two")
+	assert.NotContains(t, body, "Title Selector")
+}
+
+func TestHandlePreviewFeedExtractsLikeRefresh(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><div class="item"><h2 class="title">Post</h2><span class="url">/posts/1</span><p>Body</p><script>alert(1)</script></div></body></html>`)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("item_selector", ".item")
+	form.Add("title_selector", ".title")
+	form.Add("link_selector", ".url")
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+
+	// Links without an href are read from the text, and descriptions are
+	// sanitized as they would be stored
+	assert.Contains(t, body, "<strong>Link:</strong> "+ts.URL+"/posts/1")
+	assert.Contains(t, body, "Body")
+	assert.NotContains(t, body, "alert(1)")
+}
+
+func TestHandlePreviewFeedRemoveSelectors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><div class="item"><h2 class="title">Post</h2><p>Body text</p><div class="share">Share me</div><span class="ad">Buy now</span></div></body></html>`)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("item_selector", ".item")
+	form.Add("title_selector", ".title")
+	form.Add("remove_selectors", ".share\n.ad")
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Body text")
+	assert.NotContains(t, body, "Share me")
+	assert.NotContains(t, body, "Buy now")
+}
+
+func TestHandlePreviewFeedResolvesDatedURL(t *testing.T) {
+	// Only yesterday's page is published so far
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/archive/"+yesterday {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<html><body><div class="item"><h2 class="title">Yesterday's post</h2><a class="link" href="post">Link</a></div></body></html>`)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL+"/archive/{date:2006-01-02}{days:2}")
+	form.Add("item_selector", ".item")
+	form.Add("title_selector", ".title")
+	form.Add("link_selector", ".link")
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Yesterday&#39;s post")
+	assert.Contains(t, body, ts.URL+"/archive/post")
+	assert.Contains(t, body, "Resolved URLs (2)")
+}
+
+func TestHandlePreviewFeedFilterResults(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html := `
+			<html>
+				<body>
+					<div class="item"><h2 class="title">Go Developer</h2><a class="link" href="/go">Link</a></div>
+					<div class="item"><h2 class="title">Senior Go Developer</h2><a class="link" href="/senior-go">Link</a></div>
+				</body>
+			</html>
+		`
+		_, _ = fmt.Fprint(w, html)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("item_selector", ".item")
+	form.Add("title_selector", ".title")
+	form.Add("link_selector", ".link")
+	form.Add("filters", "title not_contains senior")
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Filter results")
+	assert.Contains(t, body, "Kept")
+	assert.Contains(t, body, "Dropped: fails <code>title not_contains senior</code>")
+}
+
+func TestHandlePreviewFeedFiltersMappedItems(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><div class="item"><h2 class="title">Sr. Go Developer</h2><a class="link" href="/sr-go">Link</a></div></body></html>`)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("item_selector", ".item")
+	form.Add("title_selector", ".title")
+	form.Add("link_selector", ".link")
+	form.Add("filters", "title not_contains senior")
+	form.Add("map_expression", `{"title": title.replace("Sr.", "Senior")}`)
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	// The rules see the title as rewritten by the mapping
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Senior Go Developer<br>")
+	assert.Contains(t, body, "Dropped: fails <code>title not_contains senior</code>")
+}
+
+func TestHandlePreviewFeedExpressionResults(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html := `
+			<html>
+				<body>
+					<div class="item"><h2 class="title">Go Developer</h2><a class="link" href="/go">Link</a></div>
+					<div class="item"><h2 class="title">Go Handbook</h2><a class="link" href="/go.pdf">Link</a></div>
+				</body>
+			</html>
+		`
+		_, _ = fmt.Fprint(w, html)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("item_selector", ".item")
+	form.Add("title_selector", ".title")
+	form.Add("link_selector", ".link")
+	form.Add("filter_expression", `!link.endsWith(".pdf")`)
+	form.Add("map_expression", `{"title": title.upperAscii()}`)
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Expression results")
+	assert.Contains(t, body, "Kept as GO DEVELOPER")
+	assert.Contains(t, body, "Dropped by the filter expression")
+}
+
+func TestHandlePreviewFeedExpressionError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<div class="item"><h2 class="title">Go</h2><a class="link" href="/go">Link</a></div>`)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("item_selector", ".item")
+	form.Add("title_selector", ".title")
+	form.Add("link_selector", ".link")
+	form.Add("filter_expression", `title.size()`)
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "filter expression: expression returns int, expected bool")
+	assert.NotContains(t, body, "Expression results")
+}
+
+func TestHandleCreateFeedInvalidExpression(t *testing.T) {
+	created := false
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = true
+			return db.Feed{}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Jobs")
+	form.Add("url", "https://example.com/jobs")
+	form.Add("map_expression", `{"title": `)
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid expressions: mapping expression")
+	assert.False(t, created)
+}
+
+func TestHandleCreateFeedInvalidFilter(t *testing.T) {
+	created := false
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = true
+			return db.Feed{}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Jobs")
+	form.Add("url", "https://example.com")
+	form.Add("filters", "title matches (")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid filter rules")
+	assert.False(t, created)
+}
+
+func TestHandleCreateFeedSavesExtraURLs(t *testing.T) {
+	var created db.CreateFeedParams
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = arg
+			return db.Feed{ID: 1}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Shop")
+	form.Add("url", "https://example.com/shoes")
+	form.Add("extra_urls", " https://example.com/bags \n\nhttps://example.com/hats\n")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "https://example.com/bags\nhttps://example.com/hats", created.ExtraUrls.String)
+}
+
+func TestHandleCreateFeedInvalidExtraURLs(t *testing.T) {
+	created := false
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = true
+			return db.Feed{}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Shop")
+	form.Add("url", "https://example.com/shoes")
+	form.Add("extra_urls", "/bags")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid additional URLs")
+	assert.False(t, created)
+}
+
+func TestHandleCreateFeedUnknownSourceType(t *testing.T) {
+	created := false
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = true
+			return db.Feed{}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Shop")
+	form.Add("url", "https://example.com/shoes")
+	form.Add("source_type", "wasm")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Unknown source type")
+	assert.False(t, created)
+}
+
+func TestHandleCreateFeedRejectsTooManyDays(t *testing.T) {
+	created := false
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = true
+			return db.Feed{}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Archive")
+	form.Add("url", "https://example.com/{date:2006/01/02}{days:90}")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid URL: {days:N} must be between 1 and 31")
+	assert.False(t, created)
+}
+
+func TestHandleCreateFeedSavesAuth(t *testing.T) {
+	var saved db.UpsertFeedAuthParams
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			return db.Feed{ID: 7}, nil
+		},
+		UpsertFeedAuthFn: func(ctx context.Context, arg db.UpsertFeedAuthParams) error {
+			saved = arg
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Intranet")
+	form.Add("url", "https://intranet.example.com/news")
+	form.Add("auth_type", "form")
+	form.Add("login_url", "https://intranet.example.com/login")
+	form.Add("login_fields", "user=me\npass=secret")
+	form.Add("login_check", "Log out")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, int64(7), saved.FeedID)
+	assert.Equal(t, "form", saved.Type)
+	assert.Equal(t, "https://intranet.example.com/login", saved.LoginUrl)
+	assert.Equal(t, "user=me\npass=secret", saved.LoginFields)
+	assert.Equal(t, "Log out", saved.LoginCheck)
+}
+
+func TestHandleUpdateFeedKeepsStoredSecrets(t *testing.T) {
+	var saved db.UpsertFeedAuthParams
+	mockQ := &mockQueries{
+		GetFeedAuthFn: func(ctx context.Context, feedID int64) (db.FeedAuth, error) {
+			return db.FeedAuth{FeedID: feedID, Type: "bearer", Token: "t0ken"}, nil
+		},
+		UpsertFeedAuthFn: func(ctx context.Context, arg db.UpsertFeedAuthParams) error {
+			saved = arg
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	// The form leaves the token blank
+	form := url.Values{}
+	form.Add("name", "Intranet")
+	form.Add("url", "https://intranet.example.com/news")
+	form.Add("auth_type", "bearer")
+
+	req := httptest.NewRequest("POST", "/feed/7/edit", nil)
+	req.SetPathValue("id", "7")
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleUpdateFeed(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "bearer", saved.Type)
+	assert.Equal(t, "t0ken", saved.Token)
+}
+
+func TestHandleUpdateFeedStopsAtFailedTags(t *testing.T) {
+	retentionUpdated := false
+	mockQ := &mockQueries{
+		UpsertTagFn: func(ctx context.Context, name string) (db.Tag, error) {
+			return db.Tag{}, errors.New("disk full")
+		},
+		UpdateFeedRetentionFn: func(ctx context.Context, arg db.UpdateFeedRetentionParams) error {
+			retentionUpdated = true
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "News")
+	form.Add("url", "https://example.com/news")
+	form.Add("tags", "news")
+
+	req := httptest.NewRequest("POST", "/feed/7/edit", nil)
+	req.SetPathValue("id", "7")
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleUpdateFeed(w, req)
+
+	// The feed's writes happen together, so nothing follows a failed one
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to update feed")
+	assert.False(t, retentionUpdated)
+}
+
+func TestHandleCreateFeedKeepsSourceFeedSecrets(t *testing.T) {
+	var saved db.UpsertFeedAuthParams
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			return db.Feed{ID: 8, Name: arg.Name, Url: arg.Url}, nil
+		},
+		GetFeedAuthFn: func(ctx context.Context, feedID int64) (db.FeedAuth, error) {
+			assert.Equal(t, int64(7), feedID)
+			return db.FeedAuth{
+				FeedID:      feedID,
+				Type:        "form",
+				LoginUrl:    "https://intranet.example.com/login",
+				LoginFields: "username=me\npassword=secret",
+			}, nil
+		},
+		UpsertFeedAuthFn: func(ctx context.Context, arg db.UpsertFeedAuthParams) error {
+			saved = arg
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	// The duplicate form shows the login field values masked
+	form := url.Values{}
+	form.Add("name", "Intranet (copy)")
+	form.Add("url", "https://intranet.example.com/news")
+	form.Add("source_feed_id", "7")
+	form.Add("auth_type", "form")
+	form.Add("login_url", "https://intranet.example.com/login")
+	form.Add("login_fields", formAuth(AuthSettings{LoginFields: "username=me\npassword=secret"}).LoginFields)
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, int64(8), saved.FeedID)
+	assert.Equal(t, "username=me\npassword=secret", saved.LoginFields)
+}
+
+func TestKeepSecretsLoginFields(t *testing.T) {
+	stored := AuthSettings{LoginFields: "username=me\npassword=secret"}
+
+	masked := formAuth(stored)
+	assert.Equal(t, "username=********\npassword=********", masked.LoginFields)
+	assert.NotContains(t, masked.LoginFields, "secret")
+
+	// Changed values win, masked ones are restored
+	edited := AuthSettings{LoginFields: "username=you\npassword=********\nremember=1"}
+	assert.Equal(t, "username=you\npassword=secret\nremember=1", keepSecrets(edited, stored).LoginFields)
+}
+
+func TestHandleCreateFeedInvalidAuth(t *testing.T) {
+	created := false
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = true
+			return db.Feed{}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Intranet")
+	form.Add("url", "https://intranet.example.com/news")
+	form.Add("auth_type", "bearer")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid authentication")
+	assert.False(t, created)
+}
+
+func TestHandlePreviewFeedWithBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<html><body><div class="item"><h2 class="title">Members only</h2></div></body></html>`)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("item_selector", ".item")
+	form.Add("title_selector", ".title")
+	form.Add("auth_type", "basic")
+	form.Add("auth_username", "me")
+	form.Add("auth_password", "secret")
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Members only")
+}
+
+func TestHandlePreviewFeedWithPatterns(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		html := `
+			<html>
+				<body>
+					<ul class="news">
+						<li><a href="/first">First story</a> <i>2024-01-02</i></li>
+						<li><a href="/second">Second story</a> <i>2024-01-03</i></li>
+					</ul>
+				</body>
+			</html>
+		`
+		_, _ = fmt.Fprint(w, html)
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(&mockQueries{}, tmpl, feed.NewService(nil), cfg)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("source_type", "pattern")
+	form.Add("global_pattern", `<ul class="news">{%}</ul>`)
+	form.Add("item_pattern", `<li><a href="{%}">{%}</a> <i>{%}</i>`)
+	form.Add("title_template", "{%2}")
+	form.Add("link_template", "{%1}")
+	form.Add("date_template", "{%3}")
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "<strong>Title:</strong> First story")
+	assert.Contains(t, body, "<strong>Link:</strong> "+ts.URL+"/first")
+	assert.Contains(t, body, "<strong>Date:</strong> 2024-01-02")
+	assert.Contains(t, body, `id="item_pattern"`)
+	assert.NotContains(t, body, `id="title_selector"`)
+}
+
+func TestHandleCreateFeedInvalidPatterns(t *testing.T) {
+	created := false
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = true
+			return db.Feed{}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, feed.NewService(nil), &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "News")
+	form.Add("url", "https://example.com/news")
+	form.Add("source_type", "pattern")
+	form.Add("item_pattern", "<li>{*}</li>")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid patterns: item pattern has no {%} capture")
+	assert.False(t, created)
+}
+
+func TestHandleCreateFeedSavesPatterns(t *testing.T) {
+	var saved db.CreateFeedParams
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			saved = arg
+			return db.Feed{ID: 3}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, feed.NewService(nil), &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "News")
+	form.Add("url", "https://example.com/news")
+	form.Add("source_type", "pattern")
+	form.Add("item_pattern", `<a href="{%}">{%}</a>`)
+	form.Add("title_template", "{%2}")
+	form.Add("link_template", "{%1}")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "pattern", saved.SourceType)
+	assert.Equal(t, sql.NullString{String: `<a href="{%}">{%}</a>`, Valid: true}, saved.ItemPattern)
+	assert.Equal(t, sql.NullString{String: "{%2}", Valid: true}, saved.TitleTemplate)
+	assert.False(t, saved.GlobalPattern.Valid)
+	assert.False(t, saved.DateTemplate.Valid)
+}
+
+func TestHandleCreateFeedInvalidLanguage(t *testing.T) {
+	created := false
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			created = true
+			return db.Feed{}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "News")
+	form.Add("url", "https://example.com/news")
+	form.Add("language", "english please")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid language")
+	assert.False(t, created)
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// FilterResult describes whether a sample item passes the feed's filter
+type FilterResult struct {
+	Title  string
+	Link   string
+	Kept   bool
+	Reason string
+}
+
+// ExpressionResult describes what the feed's expressions do to a sample item
+type ExpressionResult struct {
+	Title string
+	Link  string
+	Kept  bool
+	// MappedTitle and MappedLink are the fields of the item once rewritten
+	// by the mapping expression
+	MappedTitle string
+	MappedLink  string
+	Error       string
+}
+
+// loadFilter returns the filter rules stored for a feed
+func (h *Handler) loadFilter(ctx context.Context, feedID int64) (feed.Filter, error) {
+	rules, err := h.queries.ListFeedFilterRules(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load filter rules: %w", err)
+	}
+	return feed.FilterFromRules(rules)
+}
+
+// saveFilter replaces the filter rules stored for a feed, with the queries
+// of the transaction writing the feed
+func saveFilter(ctx context.Context, q Querier, feedID int64, filter feed.Filter) error {
+	if err := q.DeleteFeedFilterRules(ctx, feedID); err != nil {
+		return fmt.Errorf("failed to delete filter rules: %w", err)
+	}
+
+	for i, rule := range filter {
+		if err := q.CreateFeedFilterRule(ctx, db.CreateFeedFilterRuleParams{
+			FeedID:   feedID,
+			Field:    rule.Field,
+			Operator: rule.Operator,
+			Value:    rule.Value,
+			Position: int64(i),
+		}); err != nil {
+			return fmt.Errorf("failed to create filter rule: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// itemEntry returns a stored item as seen by filter rules
+func itemEntry(item db.FeedItem) feed.Entry {
+	return feed.Entry{
+		Title:       item.Title,
+		Link:        item.Link,
+		Description: item.Description.String,
+	}
+}
+
+// loadViewFilter returns the filter rules stored for a view
+func (h *Handler) loadViewFilter(ctx context.Context, viewID int64) (feed.Filter, error) {
+	rows, err := h.queries.ListFeedViewFilterRules(ctx, viewID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load view filter rules: %w", err)
+	}
+
+	// View rules are stored like feed rules
+	rules := make([]db.FeedFilterRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, db.FeedFilterRule{ID: row.ID, Field: row.Field, Operator: row.Operator, Value: row.Value})
+	}
+	return feed.FilterFromRules(rules)
+}
+
+// saveViewFilter replaces the filter rules stored for a view, with the
+// queries of the transaction writing the view
+func saveViewFilter(ctx context.Context, q Querier, viewID int64, filter feed.Filter) error {
+	if err := q.DeleteFeedViewFilterRules(ctx, viewID); err != nil {
+		return fmt.Errorf("failed to delete view filter rules: %w", err)
+	}
+
+	for i, rule := range filter {
+		if err := q.CreateFeedViewFilterRule(ctx, db.CreateFeedViewFilterRuleParams{
+			ViewID:   viewID,
+			Field:    rule.Field,
+			Operator: rule.Operator,
+			Value:    rule.Value,
+			Position: int64(i),
+		}); err != nil {
+			return fmt.Errorf("failed to create view filter rule: %w", err)
+		}
+	}
+
+	return nil
+}
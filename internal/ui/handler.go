@@ -3,6 +3,7 @@ package ui
 import (
 	"context"
 	"html/template"
+	"time"
 
 	"github.com/alessandrocuzzocrea/web2rss/internal/config"
 	"github.com/alessandrocuzzocrea/web2rss/internal/db"
@@ -18,7 +19,52 @@ type Querier interface {
 	UpdateFeed(ctx context.Context, arg db.UpdateFeedParams) error
 	DeleteFeed(ctx context.Context, id int64) error
 	ListFeedItems(ctx context.Context, feedID int64) ([]db.FeedItem, error)
+	ListFeedItemsPage(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error)
+	GetFeedItemsVersion(ctx context.Context, feedID int64) (db.GetFeedItemsVersionRow, error)
 	DeleteItemsByFeedID(ctx context.Context, feedID int64) error
+	DeleteFeedItemTombstones(ctx context.Context, feedID int64) error
+	GetFeedItem(ctx context.Context, id int64) (db.FeedItem, error)
+	ListFeedItemRevisions(ctx context.Context, feedItemID int64) ([]db.FeedItemRevision, error)
+	ListFeedFilterRules(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error)
+	CreateFeedFilterRule(ctx context.Context, arg db.CreateFeedFilterRuleParams) error
+	DeleteFeedFilterRules(ctx context.Context, feedID int64) error
+	UpdateFeedRetention(ctx context.Context, arg db.UpdateFeedRetentionParams) error
+	GetFeedAuth(ctx context.Context, feedID int64) (db.FeedAuth, error)
+	UpsertFeedAuth(ctx context.Context, arg db.UpsertFeedAuthParams) error
+	DeleteFeedAuth(ctx context.Context, feedID int64) error
+	ListFeedViews(ctx context.Context, feedID int64) ([]db.FeedView, error)
+	GetFeedView(ctx context.Context, arg db.GetFeedViewParams) (db.FeedView, error)
+	CreateFeedView(ctx context.Context, arg db.CreateFeedViewParams) (db.FeedView, error)
+	UpdateFeedView(ctx context.Context, arg db.UpdateFeedViewParams) error
+	DeleteFeedView(ctx context.Context, arg db.DeleteFeedViewParams) error
+	ListFeedViewFilterRules(ctx context.Context, viewID int64) ([]db.FeedViewFilterRule, error)
+	CreateFeedViewFilterRule(ctx context.Context, arg db.CreateFeedViewFilterRuleParams) error
+	DeleteFeedViewFilterRules(ctx context.Context, viewID int64) error
+	GetCollection(ctx context.Context, id int64) (db.Collection, error)
+	ListCollectionsWithFeedsCount(ctx context.Context) ([]db.ListCollectionsWithFeedsCountRow, error)
+	CreateCollection(ctx context.Context, name string) (db.Collection, error)
+	UpdateCollection(ctx context.Context, arg db.UpdateCollectionParams) error
+	DeleteCollection(ctx context.Context, id int64) error
+	ListCollectionFeeds(ctx context.Context, collectionID int64) ([]db.Feed, error)
+	AddCollectionFeed(ctx context.Context, arg db.AddCollectionFeedParams) error
+	DeleteCollectionFeeds(ctx context.Context, collectionID int64) error
+	ListCollectionItems(ctx context.Context, arg db.ListCollectionItemsParams) ([]db.ListCollectionItemsRow, error)
+	GetTagByName(ctx context.Context, name string) (db.Tag, error)
+	ListTagsWithFeedsCount(ctx context.Context) ([]db.ListTagsWithFeedsCountRow, error)
+	UpsertTag(ctx context.Context, name string) (db.Tag, error)
+	AddFeedTag(ctx context.Context, arg db.AddFeedTagParams) error
+	DeleteFeedTags(ctx context.Context, feedID int64) error
+	DeleteUnusedTags(ctx context.Context) error
+	ListFeedTagNames(ctx context.Context) ([]db.ListFeedTagNamesRow, error)
+	ListTagNamesByFeed(ctx context.Context, feedID int64) ([]string, error)
+	ListTagFeeds(ctx context.Context, tagID int64) ([]db.Feed, error)
+	ListTagItems(ctx context.Context, arg db.ListTagItemsParams) ([]db.ListTagItemsRow, error)
+}
+
+// transactor is implemented by queries that can run writes in a transaction,
+// such as a db.Store
+type transactor interface {
+	InTx(ctx context.Context, fn func(q *db.Queries) error) error
 }
 
 type Handler struct {
@@ -26,13 +72,30 @@ type Handler struct {
 	templates   *template.Template
 	feedService *feed.Service
 	config      *config.Config
+	// location is the configured timezone, loaded once at startup
+	location *time.Location
 }
 
 func NewHandler(q Querier, t *template.Template, fs *feed.Service, cfg *config.Config) *Handler {
+	location := time.UTC
+	if cfg != nil {
+		location = cfg.Location()
+	}
+
 	return &Handler{
 		queries:     q,
 		templates:   t,
 		feedService: fs,
 		config:      cfg,
+		location:    location,
+	}
+}
+
+// inTx runs writes that must happen together in a transaction, or directly
+// on queries that do not support transactions
+func (h *Handler) inTx(ctx context.Context, fn func(q Querier) error) error {
+	if t, ok := h.queries.(transactor); ok {
+		return t.InTx(ctx, func(q *db.Queries) error { return fn(q) })
 	}
+	return fn(h.queries)
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"slices"
+	"strings"
 
 	"github.com/alessandrocuzzocrea/web2rss/internal/config"
 	"github.com/alessandrocuzzocrea/web2rss/internal/db"
@@ -17,7 +19,27 @@ type HomePageData struct {
 	GoVersion  string
 	BuildTime  string
 	Uptime     string
-	Feeds      []db.ListFeedsWithItemsCountRow
+	Feeds      []FeedRow
+	// Feeds whose selectors stopped matching, shown above the list
+	BrokenFeeds []db.ListFeedsWithItemsCountRow
+	Collections []db.ListCollectionsWithFeedsCountRow
+	// Tags in use, the tag the list is filtered by, and the list grouped by
+	// tag when asked for
+	Tags   []db.ListTagsWithFeedsCountRow
+	Tag    string
+	Groups []FeedGroup
+}
+
+// FeedRow is a feed of the home page list along with its tags
+type FeedRow struct {
+	db.ListFeedsWithItemsCountRow
+	Tags []string
+}
+
+// FeedGroup is the feeds sharing a tag, or having none when Tag is empty
+type FeedGroup struct {
+	Tag   string
+	Feeds []FeedRow
 }
 
 // handleHomepage renders the homepage using HTML templates
@@ -35,6 +57,49 @@ func (h *Handler) handleHomepage(w http.ResponseWriter, r *http.Request) {
 	}
 	// fmt.Printf("Feeds: %+v\n", feeds) // For debugging; remove in production
 
+	collections, err := h.queries.ListCollectionsWithFeedsCount(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list collections: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := h.queries.ListTagsWithFeedsCount(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	feedTags, err := h.queries.ListFeedTagNames(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list feed tags: %v", err), http.StatusInternalServerError)
+		return
+	}
+	tagsByFeed := make(map[int64][]string)
+	for _, ft := range feedTags {
+		tagsByFeed[ft.FeedID] = append(tagsByFeed[ft.FeedID], ft.Name)
+	}
+
+	// Only keep the feeds with the requested tag, if any
+	tag := r.URL.Query().Get("tag")
+	var rows []FeedRow
+	var broken []db.ListFeedsWithItemsCountRow
+	for _, f := range feeds {
+		if f.BrokenReason.Valid {
+			broken = append(broken, f)
+		}
+
+		row := FeedRow{ListFeedsWithItemsCountRow: f, Tags: tagsByFeed[f.ID]}
+		if tag != "" && !slices.ContainsFunc(row.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	var groups []FeedGroup
+	if r.URL.Query().Get("group") == "tag" {
+		groups = groupFeedsByTag(rows, tags)
+	}
+
 	data := HomePageData{
 		Title:      "Home",
 		Version:    "0.1.0",
@@ -44,7 +109,12 @@ func (h *Handler) handleHomepage(w http.ResponseWriter, r *http.Request) {
 		Uptime:     "N/A",        // time.Since(h.startTime) -> h.startTime is not available in Handler yet.
 		// We can add StartTime to Handler or calculate it differently.
 		// For now, let's just put "N/A" or pass it from Config if we tracked it there.
-		Feeds: feeds,
+		Feeds:       rows,
+		BrokenFeeds: broken,
+		Collections: collections,
+		Tags:        tags,
+		Tag:         tag,
+		Groups:      groups,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -55,3 +125,32 @@ func (h *Handler) handleHomepage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// groupFeedsByTag lists the feeds under each of their tags, in tag order,
+// followed by the untagged feeds
+func groupFeedsByTag(rows []FeedRow, tags []db.ListTagsWithFeedsCountRow) []FeedGroup {
+	var groups []FeedGroup
+	for _, tag := range tags {
+		group := FeedGroup{Tag: tag.Name}
+		for _, row := range rows {
+			if slices.Contains(row.Tags, tag.Name) {
+				group.Feeds = append(group.Feeds, row)
+			}
+		}
+		if len(group.Feeds) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	untagged := FeedGroup{}
+	for _, row := range rows {
+		if len(row.Tags) == 0 {
+			untagged.Feeds = append(untagged.Feeds, row)
+		}
+	}
+	if len(untagged.Feeds) > 0 {
+		groups = append(groups, untagged)
+	}
+
+	return groups
+}
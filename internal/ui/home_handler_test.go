@@ -6,6 +6,8 @@ import (
 	"html/template"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -80,3 +82,126 @@ func TestHandleHomepage(t *testing.T) {
 	assert.Contains(t, body, "/feed/1/rss")
 	assert.Contains(t, body, "/feed/1/refresh")
 }
+
+func TestHandleHomepageShowsBrokenFeeds(t *testing.T) {
+	mockQ := &mockQueries{
+		ListFeedsWithItemsCountFn: func(ctx context.Context) ([]db.ListFeedsWithItemsCountRow, error) {
+			return []db.ListFeedsWithItemsCountRow{
+				{ID: 1, Name: "Healthy Feed", Url: "http://test.com"},
+				{
+					ID:           2,
+					Name:         "Redesigned Site",
+					Url:          "http://redesigned.com",
+					BrokenReason: sql.NullString{String: "item selector matched nothing", Valid: true},
+					BrokenSince:  sql.NullTime{Time: time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC), Valid: true},
+				},
+			}, nil
+		},
+	}
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(mockQ, tmpl, nil, cfg)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleHomepage(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "1 feed(s) look broken")
+	assert.Contains(t, body, "item selector matched nothing")
+	assert.Contains(t, body, "2025-01-03 08:00:00 UTC")
+	assert.Contains(t, body, "/feed/2/responses")
+}
+
+func TestHandleHomepageTags(t *testing.T) {
+	mockQ := &mockQueries{
+		ListFeedsWithItemsCountFn: func(ctx context.Context) ([]db.ListFeedsWithItemsCountRow, error) {
+			return []db.ListFeedsWithItemsCountRow{
+				{ID: 1, Name: "Go Jobs", Url: "http://jobs.test"},
+				{ID: 2, Name: "Release Notes", Url: "http://news.test"},
+				{ID: 3, Name: "Weather", Url: "http://weather.test"},
+			}, nil
+		},
+		ListTagsWithFeedsCountFn: func(ctx context.Context) ([]db.ListTagsWithFeedsCountRow, error) {
+			return []db.ListTagsWithFeedsCountRow{
+				{ID: 1, Name: "backend", FeedsCount: 2},
+				{ID: 2, Name: "hiring", FeedsCount: 1},
+				{ID: 3, Name: "ci/cd #1?", FeedsCount: 1},
+			}, nil
+		},
+		ListFeedTagNamesFn: func(ctx context.Context) ([]db.ListFeedTagNamesRow, error) {
+			return []db.ListFeedTagNamesRow{
+				{FeedID: 1, Name: "backend"},
+				{FeedID: 2, Name: "backend"},
+				{FeedID: 1, Name: "hiring"},
+				{FeedID: 2, Name: "ci/cd #1?"},
+			}, nil
+		},
+	}
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(mockQ, tmpl, nil, cfg)
+
+	t.Run("filter", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?tag=Hiring", nil)
+		w := httptest.NewRecorder()
+
+		handler.handleHomepage(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "Go Jobs")
+		assert.NotContains(t, body, "Release Notes")
+		assert.NotContains(t, body, "Weather")
+		assert.Contains(t, body, `href="/tag/Hiring/rss"`)
+		assert.Contains(t, body, `href="/tag/Hiring/opml"`)
+	})
+
+	t.Run("escaped links", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?tag="+url.QueryEscape("ci/cd #1?"), nil)
+		w := httptest.NewRecorder()
+
+		handler.handleHomepage(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "Release Notes")
+		assert.Contains(t, body, `href="/tag/ci%2Fcd%20%231%3F/rss"`)
+		assert.Contains(t, body, `href="/tag/ci%2Fcd%20%231%3F/opml"`)
+		assert.Contains(t, body, `href="/?tag=ci%2fcd%20%231%3f"`)
+	})
+
+	t.Run("group", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/?group=tag", nil)
+		w := httptest.NewRecorder()
+
+		handler.handleHomepage(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		backend := strings.Index(body, "<strong>backend</strong>")
+		hiring := strings.Index(body, "<strong>hiring</strong>")
+		untagged := strings.Index(body, "<strong>Untagged</strong>")
+		assert.True(t, backend >= 0 && backend < hiring && hiring < untagged)
+		assert.Equal(t, 2, strings.Count(body, "<strong>Go Jobs</strong>"))
+		assert.Greater(t, strings.Index(body, "<strong>Weather</strong>"), untagged)
+	})
+}
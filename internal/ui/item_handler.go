@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// ItemRevision is a single version of an item as shown in the history page
+type ItemRevision struct {
+	Title       string
+	Description string
+	CapturedAt  sql.NullTime
+	Current     bool
+	Diff        string
+}
+
+// GET /feed/{id}/items - List the stored items of a feed
+func (h *Handler) handleFeedItems(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	feed, err := h.queries.GetFeed(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+
+	items, err := h.queries.ListFeedItems(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to fetch feed items", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Feed  db.Feed
+		Items []db.FeedItem
+	}{
+		Feed:  feed,
+		Items: items,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.templates.ExecuteTemplate(w, "feed_items.html", data); err != nil {
+		fmt.Printf("Template error: %v\n", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GET /feed/{id}/items/{itemID}/revisions - Show the history of an item as diffs
+func (h *Handler) handleItemRevisions(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := strconv.ParseInt(r.PathValue("itemID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.queries.GetFeedItem(r.Context(), itemID)
+	if err != nil || item.FeedID != feedID {
+		http.Error(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	revisions, err := h.queries.ListFeedItemRevisions(r.Context(), itemID)
+	if err != nil {
+		http.Error(w, "Failed to fetch item revisions", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		FeedID    int64
+		Item      db.FeedItem
+		Revisions []ItemRevision
+	}{
+		FeedID:    feedID,
+		Item:      item,
+		Revisions: buildItemRevisions(item, revisions),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.templates.ExecuteTemplate(w, "item_revisions.html", data); err != nil {
+		fmt.Printf("Template error: %v\n", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// buildItemRevisions turns the stored revisions (oldest first) plus the
+// current item into a newest-first history, each entry carrying the diff
+// against the version that preceded it.
+func buildItemRevisions(item db.FeedItem, revisions []db.FeedItemRevision) []ItemRevision {
+	versions := make([]ItemRevision, 0, len(revisions)+1)
+	for i, rev := range revisions {
+		// A revision row holds the content that was replaced, so it was
+		// captured when the previous revision (or the item) was replaced.
+		capturedAt := item.CreatedAt
+		if i > 0 {
+			capturedAt = revisions[i-1].CreatedAt
+		}
+		versions = append(versions, ItemRevision{
+			Title:       rev.Title,
+			Description: rev.Description.String,
+			CapturedAt:  capturedAt,
+		})
+	}
+
+	current := ItemRevision{
+		Title:       item.Title,
+		Description: item.Description.String,
+		CapturedAt:  item.CreatedAt,
+		Current:     true,
+	}
+	if len(revisions) > 0 {
+		current.CapturedAt = revisions[len(revisions)-1].CreatedAt
+	}
+	versions = append(versions, current)
+
+	history := make([]ItemRevision, len(versions))
+	for i, v := range versions {
+		if i > 0 {
+			v.Diff = feed.Diff(revisionText(versions[i-1]), revisionText(v))
+		}
+		history[len(versions)-1-i] = v
+	}
+
+	return history
+}
+
+func revisionText(v ItemRevision) string {
+	return v.Title + "\n\n" + v.Description
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleItemRevisions(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedItemFn: func(ctx context.Context, id int64) (db.FeedItem, error) {
+			return db.FeedItem{
+				ID:          id,
+				FeedID:      1,
+				Title:       "Terms of Service",
+				Link:        "https://example.com/terms",
+				Description: sql.NullString{String: "You may cancel within 30 days.", Valid: true},
+			}, nil
+		},
+		ListFeedItemRevisionsFn: func(ctx context.Context, feedItemID int64) ([]db.FeedItemRevision, error) {
+			return []db.FeedItemRevision{
+				{
+					ID:          1,
+					FeedItemID:  feedItemID,
+					Title:       "Terms of Service",
+					Description: sql.NullString{String: "You may cancel within 14 days.", Valid: true},
+					CreatedAt:   sql.NullTime{Time: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), Valid: true},
+				},
+			}, nil
+		},
+	}
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	handler := NewHandler(mockQ, tmpl, nil, cfg)
+
+	req := httptest.NewRequest("GET", "/feed/1/items/5/revisions", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("itemID", "5")
+	w := httptest.NewRecorder()
+
+	handler.handleItemRevisions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Current version")
+	assert.Contains(t, body, "-You may cancel within 14 days.")
+	assert.Contains(t, body, "&#43;You may cancel within 30 days.")
+	assert.Contains(t, body, "2025-03-01 09:00:00 UTC")
+}
+
+func TestHandleItemRevisionsWrongFeed(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedItemFn: func(ctx context.Context, id int64) (db.FeedItem, error) {
+			return db.FeedItem{ID: id, FeedID: 2}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/feed/1/items/5/revisions", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("itemID", "5")
+	w := httptest.NewRecorder()
+
+	handler.handleItemRevisions(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBuildItemRevisions(t *testing.T) {
+	item := db.FeedItem{Title: "v3"}
+	revisions := []db.FeedItemRevision{{Title: "v1"}, {Title: "v2"}}
+
+	history := buildItemRevisions(item, revisions)
+
+	assert.Len(t, history, 3)
+	assert.True(t, history[0].Current)
+	assert.Equal(t, "v3", history[0].Title)
+	assert.Contains(t, history[0].Diff, "-v2")
+	assert.Contains(t, history[0].Diff, "+v3")
+	assert.Equal(t, "v1", history[2].Title)
+	assert.Empty(t, history[2].Diff, fmt.Sprintf("oldest version should have no diff, got %q", history[2].Diff))
+}
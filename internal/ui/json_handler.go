@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// JSONFeedVersion identifies the version of the JSON Feed spec documents follow
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// attachmentTypes are the MIME types of the files published as attachments,
+// by extension
+var attachmentTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".ogg":  "audio/ogg",
+	".opus": "audio/opus",
+	".wav":  "audio/wav",
+	".mp4":  "video/mp4",
+	".m4v":  "video/mp4",
+	".webm": "video/webm",
+	".mov":  "video/quicktime",
+	".pdf":  "application/pdf",
+}
+
+// JSON Feed 1.1 structures (https://jsonfeed.org/version/1.1)
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url,omitempty"`
+	Title         string               `json:"title,omitempty"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	DateModified  string               `json:"date_modified,omitempty"`
+	Attachments   []JSONFeedAttachment `json:"attachments,omitempty"`
+}
+
+type JSONFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+}
+
+// GET /feed/{id}/json - Generate a JSON Feed
+func (h *Handler) handleFeedJSON(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	f, err := h.queries.GetFeed(ctx, feedID)
+	if err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+
+	query, err := h.parseOutputQuery(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if h.notModified(w, r, f) {
+		return
+	}
+
+	items, err := h.listOutputItems(ctx, feedID, query)
+	if err != nil {
+		fmt.Printf("Failed to fetch feed items: %v\n", err)
+		http.Error(w, "Failed to fetch feed items", http.StatusInternalServerError)
+		return
+	}
+
+	jsonItems := make([]JSONFeedItem, 0, len(items))
+	for _, item := range items {
+		jsonItems = append(jsonItems, newJSONFeedItem(item, f.DescriptionFormat))
+	}
+
+	siteURL := h.siteURL(f.Url)
+	writeJSONFeed(w, JSONFeed{
+		Version:     JSONFeedVersion,
+		Title:       f.Name,
+		HomePageURL: siteURL,
+		FeedURL:     fmt.Sprintf("%s/feed/%d/json", h.baseURL(r), feedID),
+		Description: cmp.Or(f.Description.String, fmt.Sprintf("Feed generated from %s", siteURL)),
+		Language:    cmp.Or(f.Language.String, defaultLanguage),
+		Items:       jsonItems,
+	})
+}
+
+// newJSONFeedItem converts a stored item, identified like Atom entries. Items
+// must have some content, so those without a description repeat their title.
+func newJSONFeedItem(item db.FeedItem, descriptionFormat string) JSONFeedItem {
+	jsonItem := JSONFeedItem{
+		ID:    itemID(item),
+		URL:   item.Link,
+		Title: item.Title,
+	}
+
+	switch {
+	case item.Description.String == "":
+		jsonItem.ContentText = item.Title
+	case descriptionFormat == feed.FormatText:
+		jsonItem.ContentText = item.Description.String
+	default:
+		jsonItem.ContentHTML = item.Description.String
+		jsonItem.Attachments = descriptionAttachments(item.Description.String)
+	}
+
+	switch {
+	case item.Date.Valid:
+		jsonItem.DatePublished = formatRFC3339Date(item.Date.Time)
+	case item.CreatedAt.Valid:
+		jsonItem.DatePublished = formatRFC3339Date(item.CreatedAt.Time)
+	}
+	if item.UpdatedAt.Valid {
+		jsonItem.DateModified = formatRFC3339Date(item.UpdatedAt.Time)
+	}
+
+	return jsonItem
+}
+
+// descriptionAttachments returns the audio, video and documents an HTML
+// description embeds or links to, when their type is known from the extension
+func descriptionAttachments(description string) []JSONFeedAttachment {
+	if description == "" {
+		return nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(description))
+	if err != nil {
+		return nil
+	}
+
+	var attachments []JSONFeedAttachment
+	seen := make(map[string]bool)
+	doc.Find("audio[src], video[src], source[src], a[href]").Each(func(_ int, sel *goquery.Selection) {
+		ref := sel.AttrOr("src", sel.AttrOr("href", ""))
+		u, err := url.Parse(ref)
+		if err != nil || !u.IsAbs() || seen[ref] {
+			return
+		}
+
+		mimeType, ok := attachmentTypes[strings.ToLower(path.Ext(u.Path))]
+		if !ok {
+			return
+		}
+
+		seen[ref] = true
+		attachments = append(attachments, JSONFeedAttachment{URL: ref, MimeType: mimeType})
+	})
+	return attachments
+}
+
+// writeJSONFeed sends a JSON Feed document
+func writeJSONFeed(w http.ResponseWriter, jsonFeed JSONFeed) {
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(jsonFeed); err != nil {
+		fmt.Printf("Failed to generate JSON Feed: %v\n", err)
+	}
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleFeedJSON(t *testing.T) {
+	refreshed := time.Now().Add(-15 * time.Minute).Truncate(time.Second)
+	changed := time.Date(2024, 3, 2, 8, 30, 0, 0, time.UTC)
+
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{
+				ID:                1,
+				Name:              "Podcast",
+				Url:               "https://example.com",
+				DescriptionFormat: "html",
+				Description:       sql.NullString{String: "Weekly episodes", Valid: true},
+				Language:          sql.NullString{String: "it", Valid: true},
+				LastRefreshedAt:   sql.NullTime{Time: refreshed, Valid: true},
+			}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			return []db.FeedItem{
+				{
+					ID:    1,
+					Title: "Episode 1",
+					Link:  "https://example.com/episode-1",
+					Description: sql.NullString{
+						String: `<p>Listen</p><audio src="https://cdn.example.com/ep1.MP3"></audio><a href="https://example.com/about">About</a>`,
+						Valid:  true,
+					},
+					CreatedAt: sql.NullTime{Time: changed, Valid: true},
+					UpdatedAt: sql.NullTime{Time: changed, Valid: true},
+					Date:      sql.NullTime{Time: time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), Valid: true},
+				},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "http://rss.example.net/feed/1/json", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedJSON(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/feed+json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, refreshed.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+	var maxAge int
+	_, err := fmt.Sscanf(w.Header().Get("Cache-Control"), "public, max-age=%d", &maxAge)
+	assert.NoError(t, err)
+	assert.InDelta(t, 45*60, maxAge, 5)
+
+	var jsonFeed JSONFeed
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &jsonFeed))
+
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", jsonFeed.Version)
+	assert.Equal(t, "Podcast", jsonFeed.Title)
+	assert.Equal(t, "https://example.com", jsonFeed.HomePageURL)
+	assert.Equal(t, "http://rss.example.net/feed/1/json", jsonFeed.FeedURL)
+	assert.Equal(t, "Weekly episodes", jsonFeed.Description)
+	assert.Equal(t, "it", jsonFeed.Language)
+	assert.Equal(t, []JSONFeedItem{{
+		ID:            "https://example.com/episode-1",
+		URL:           "https://example.com/episode-1",
+		Title:         "Episode 1",
+		ContentHTML:   `<p>Listen</p><audio src="https://cdn.example.com/ep1.MP3"></audio><a href="https://example.com/about">About</a>`,
+		DatePublished: "2024-02-28T00:00:00Z",
+		DateModified:  "2024-03-02T08:30:00Z",
+		Attachments: []JSONFeedAttachment{
+			{URL: "https://cdn.example.com/ep1.MP3", MimeType: "audio/mpeg"},
+		},
+	}}, jsonFeed.Items)
+}
+
+func TestHandleFeedJSONTextDescriptions(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: 1, Name: "Test Feed", DescriptionFormat: "text"}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			return []db.FeedItem{
+				{ID: 1, Title: "Item", Link: "https://example.com/item", Description: sql.NullString{String: "Plain", Valid: true}},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/feed/1/json", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedJSON(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var jsonFeed JSONFeed
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &jsonFeed))
+	assert.Len(t, jsonFeed.Items, 1)
+	assert.Equal(t, "Plain", jsonFeed.Items[0].ContentText)
+	assert.Empty(t, jsonFeed.Items[0].ContentHTML)
+}
+
+func TestNewJSONFeedItemWithoutLinkOrDescription(t *testing.T) {
+	item := newJSONFeedItem(db.FeedItem{ID: 12, FeedID: 3, Title: "Notice"}, "html")
+
+	// Identified like Atom entries, and the title stands in for the content
+	assert.Equal(t, JSONFeedItem{
+		ID:          "urn:web2rss:feed:3:item:12",
+		Title:       "Notice",
+		ContentText: "Notice",
+	}, item)
+}
+
+func TestHandleFeedJSONFeedNotFound(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{}, fmt.Errorf("feed not found")
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/feed/999/json", nil)
+	req.SetPathValue("id", "999")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedJSON(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/alessandrocuzzocrea/web2rss/internal/db"
 )
@@ -10,16 +11,61 @@ import (
 //
 
 type mockQueries struct {
-	GetFeedFn                   func(ctx context.Context, id int64) (db.Feed, error)
-	ListFeedsFn                 func(ctx context.Context) ([]db.Feed, error)
-	ListFeedsWithItemsCountFn   func(ctx context.Context) ([]db.ListFeedsWithItemsCountRow, error)
-	CreateFeedFn                func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error)
-	UpdateFeedFn                func(ctx context.Context, arg db.UpdateFeedParams) error
-	UpdateFeedLastRefreshedAtFn func(ctx context.Context, arg db.UpdateFeedLastRefreshedAtParams) error
-	DeleteFeedFn                func(ctx context.Context, id int64) error
-	ListFeedItemsFn             func(ctx context.Context, feedID int64) ([]db.FeedItem, error)
-	UpsertFeedItemFn            func(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error)
-	DeleteItemsByFeedIDFn       func(ctx context.Context, feedID int64) error
+	GetFeedFn                       func(ctx context.Context, id int64) (db.Feed, error)
+	ListFeedsFn                     func(ctx context.Context) ([]db.Feed, error)
+	ListFeedsWithItemsCountFn       func(ctx context.Context) ([]db.ListFeedsWithItemsCountRow, error)
+	CreateFeedFn                    func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error)
+	UpdateFeedFn                    func(ctx context.Context, arg db.UpdateFeedParams) error
+	UpdateFeedLastRefreshedAtFn     func(ctx context.Context, arg db.UpdateFeedLastRefreshedAtParams) error
+	DeleteFeedFn                    func(ctx context.Context, id int64) error
+	ListFeedItemsFn                 func(ctx context.Context, feedID int64) ([]db.FeedItem, error)
+	UpsertFeedItemFn                func(ctx context.Context, arg db.UpsertFeedItemParams) ([]int64, error)
+	DeleteItemsByFeedIDFn           func(ctx context.Context, feedID int64) error
+	GetFeedItemFn                   func(ctx context.Context, id int64) (db.FeedItem, error)
+	GetFeedItemByLinkFn             func(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error)
+	UpdateFeedItemContentFn         func(ctx context.Context, arg db.UpdateFeedItemContentParams) error
+	CreateFeedItemRevisionFn        func(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error)
+	ListFeedItemRevisionsFn         func(ctx context.Context, feedItemID int64) ([]db.FeedItemRevision, error)
+	UpdateFeedSnapshotFn            func(ctx context.Context, arg db.UpdateFeedSnapshotParams) error
+	ListFeedFilterRulesFn           func(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error)
+	CreateFeedFilterRuleFn          func(ctx context.Context, arg db.CreateFeedFilterRuleParams) error
+	DeleteFeedFilterRulesFn         func(ctx context.Context, feedID int64) error
+	PruneFeedItemsByAgeFn           func(ctx context.Context, arg db.PruneFeedItemsByAgeParams) (int64, error)
+	PruneFeedItemsByCountFn         func(ctx context.Context, arg db.PruneFeedItemsByCountParams) (int64, error)
+	UpdateFeedRetentionFn           func(ctx context.Context, arg db.UpdateFeedRetentionParams) error
+	GetFeedAuthFn                   func(ctx context.Context, feedID int64) (db.FeedAuth, error)
+	UpsertFeedAuthFn                func(ctx context.Context, arg db.UpsertFeedAuthParams) error
+	DeleteFeedAuthFn                func(ctx context.Context, feedID int64) error
+	GetCollectionFn                 func(ctx context.Context, id int64) (db.Collection, error)
+	ListCollectionsWithFeedsCountFn func(ctx context.Context) ([]db.ListCollectionsWithFeedsCountRow, error)
+	CreateCollectionFn              func(ctx context.Context, name string) (db.Collection, error)
+	UpdateCollectionFn              func(ctx context.Context, arg db.UpdateCollectionParams) error
+	DeleteCollectionFn              func(ctx context.Context, id int64) error
+	ListCollectionFeedsFn           func(ctx context.Context, collectionID int64) ([]db.Feed, error)
+	AddCollectionFeedFn             func(ctx context.Context, arg db.AddCollectionFeedParams) error
+	DeleteCollectionFeedsFn         func(ctx context.Context, collectionID int64) error
+	ListCollectionItemsFn           func(ctx context.Context, arg db.ListCollectionItemsParams) ([]db.ListCollectionItemsRow, error)
+	GetTagByNameFn                  func(ctx context.Context, name string) (db.Tag, error)
+	ListTagsWithFeedsCountFn        func(ctx context.Context) ([]db.ListTagsWithFeedsCountRow, error)
+	UpsertTagFn                     func(ctx context.Context, name string) (db.Tag, error)
+	AddFeedTagFn                    func(ctx context.Context, arg db.AddFeedTagParams) error
+	DeleteFeedTagsFn                func(ctx context.Context, feedID int64) error
+	DeleteUnusedTagsFn              func(ctx context.Context) error
+	ListFeedTagNamesFn              func(ctx context.Context) ([]db.ListFeedTagNamesRow, error)
+	ListTagNamesByFeedFn            func(ctx context.Context, feedID int64) ([]string, error)
+	ListTagFeedsFn                  func(ctx context.Context, tagID int64) ([]db.Feed, error)
+	ListTagItemsFn                  func(ctx context.Context, arg db.ListTagItemsParams) ([]db.ListTagItemsRow, error)
+	GetFeedItemsVersionFn           func(ctx context.Context, feedID int64) (db.GetFeedItemsVersionRow, error)
+	ListFeedItemsPageFn             func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error)
+	ListFeedViewsFn                 func(ctx context.Context, feedID int64) ([]db.FeedView, error)
+	GetFeedViewFn                   func(ctx context.Context, arg db.GetFeedViewParams) (db.FeedView, error)
+	CreateFeedViewFn                func(ctx context.Context, arg db.CreateFeedViewParams) (db.FeedView, error)
+	UpdateFeedViewFn                func(ctx context.Context, arg db.UpdateFeedViewParams) error
+	DeleteFeedViewFn                func(ctx context.Context, arg db.DeleteFeedViewParams) error
+	ListFeedViewFilterRulesFn       func(ctx context.Context, viewID int64) ([]db.FeedViewFilterRule, error)
+	CreateFeedViewFilterRuleFn      func(ctx context.Context, arg db.CreateFeedViewFilterRuleParams) error
+	DeleteFeedViewFilterRulesFn     func(ctx context.Context, viewID int64) error
+	DeleteFeedItemTombstonesFn      func(ctx context.Context, feedID int64) error
 }
 
 func (m *mockQueries) GetFeed(ctx context.Context, id int64) (db.Feed, error) {
@@ -82,3 +128,273 @@ func (m *mockQueries) DeleteItemsByFeedID(ctx context.Context, feedID int64) err
 	}
 	return nil
 }
+func (m *mockQueries) GetFeedItem(ctx context.Context, id int64) (db.FeedItem, error) {
+	if m.GetFeedItemFn != nil {
+		return m.GetFeedItemFn(ctx, id)
+	}
+	return db.FeedItem{}, nil
+}
+func (m *mockQueries) GetFeedItemByLink(ctx context.Context, arg db.GetFeedItemByLinkParams) (db.FeedItem, error) {
+	if m.GetFeedItemByLinkFn != nil {
+		return m.GetFeedItemByLinkFn(ctx, arg)
+	}
+	return db.FeedItem{}, nil
+}
+func (m *mockQueries) UpdateFeedItemContent(ctx context.Context, arg db.UpdateFeedItemContentParams) error {
+	if m.UpdateFeedItemContentFn != nil {
+		return m.UpdateFeedItemContentFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) CreateFeedItemRevision(ctx context.Context, arg db.CreateFeedItemRevisionParams) (db.FeedItemRevision, error) {
+	if m.CreateFeedItemRevisionFn != nil {
+		return m.CreateFeedItemRevisionFn(ctx, arg)
+	}
+	return db.FeedItemRevision{}, nil
+}
+func (m *mockQueries) ListFeedItemRevisions(ctx context.Context, feedItemID int64) ([]db.FeedItemRevision, error) {
+	if m.ListFeedItemRevisionsFn != nil {
+		return m.ListFeedItemRevisionsFn(ctx, feedItemID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) UpdateFeedSnapshot(ctx context.Context, arg db.UpdateFeedSnapshotParams) error {
+	if m.UpdateFeedSnapshotFn != nil {
+		return m.UpdateFeedSnapshotFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) ListFeedFilterRules(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error) {
+	if m.ListFeedFilterRulesFn != nil {
+		return m.ListFeedFilterRulesFn(ctx, feedID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) CreateFeedFilterRule(ctx context.Context, arg db.CreateFeedFilterRuleParams) error {
+	if m.CreateFeedFilterRuleFn != nil {
+		return m.CreateFeedFilterRuleFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteFeedFilterRules(ctx context.Context, feedID int64) error {
+	if m.DeleteFeedFilterRulesFn != nil {
+		return m.DeleteFeedFilterRulesFn(ctx, feedID)
+	}
+	return nil
+}
+func (m *mockQueries) PruneFeedItemsByAge(ctx context.Context, arg db.PruneFeedItemsByAgeParams) (int64, error) {
+	if m.PruneFeedItemsByAgeFn != nil {
+		return m.PruneFeedItemsByAgeFn(ctx, arg)
+	}
+	return 0, nil
+}
+func (m *mockQueries) PruneFeedItemsByCount(ctx context.Context, arg db.PruneFeedItemsByCountParams) (int64, error) {
+	if m.PruneFeedItemsByCountFn != nil {
+		return m.PruneFeedItemsByCountFn(ctx, arg)
+	}
+	return 0, nil
+}
+func (m *mockQueries) UpdateFeedRetention(ctx context.Context, arg db.UpdateFeedRetentionParams) error {
+	if m.UpdateFeedRetentionFn != nil {
+		return m.UpdateFeedRetentionFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) GetFeedAuth(ctx context.Context, feedID int64) (db.FeedAuth, error) {
+	if m.GetFeedAuthFn != nil {
+		return m.GetFeedAuthFn(ctx, feedID)
+	}
+	return db.FeedAuth{}, sql.ErrNoRows
+}
+func (m *mockQueries) UpsertFeedAuth(ctx context.Context, arg db.UpsertFeedAuthParams) error {
+	if m.UpsertFeedAuthFn != nil {
+		return m.UpsertFeedAuthFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteFeedAuth(ctx context.Context, feedID int64) error {
+	if m.DeleteFeedAuthFn != nil {
+		return m.DeleteFeedAuthFn(ctx, feedID)
+	}
+	return nil
+}
+func (m *mockQueries) GetCollection(ctx context.Context, id int64) (db.Collection, error) {
+	if m.GetCollectionFn != nil {
+		return m.GetCollectionFn(ctx, id)
+	}
+	return db.Collection{}, sql.ErrNoRows
+}
+func (m *mockQueries) ListCollectionsWithFeedsCount(ctx context.Context) ([]db.ListCollectionsWithFeedsCountRow, error) {
+	if m.ListCollectionsWithFeedsCountFn != nil {
+		return m.ListCollectionsWithFeedsCountFn(ctx)
+	}
+	return nil, nil
+}
+func (m *mockQueries) CreateCollection(ctx context.Context, name string) (db.Collection, error) {
+	if m.CreateCollectionFn != nil {
+		return m.CreateCollectionFn(ctx, name)
+	}
+	return db.Collection{}, nil
+}
+func (m *mockQueries) UpdateCollection(ctx context.Context, arg db.UpdateCollectionParams) error {
+	if m.UpdateCollectionFn != nil {
+		return m.UpdateCollectionFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteCollection(ctx context.Context, id int64) error {
+	if m.DeleteCollectionFn != nil {
+		return m.DeleteCollectionFn(ctx, id)
+	}
+	return nil
+}
+func (m *mockQueries) ListCollectionFeeds(ctx context.Context, collectionID int64) ([]db.Feed, error) {
+	if m.ListCollectionFeedsFn != nil {
+		return m.ListCollectionFeedsFn(ctx, collectionID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) AddCollectionFeed(ctx context.Context, arg db.AddCollectionFeedParams) error {
+	if m.AddCollectionFeedFn != nil {
+		return m.AddCollectionFeedFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteCollectionFeeds(ctx context.Context, collectionID int64) error {
+	if m.DeleteCollectionFeedsFn != nil {
+		return m.DeleteCollectionFeedsFn(ctx, collectionID)
+	}
+	return nil
+}
+func (m *mockQueries) ListCollectionItems(ctx context.Context, arg db.ListCollectionItemsParams) ([]db.ListCollectionItemsRow, error) {
+	if m.ListCollectionItemsFn != nil {
+		return m.ListCollectionItemsFn(ctx, arg)
+	}
+	return nil, nil
+}
+func (m *mockQueries) GetTagByName(ctx context.Context, name string) (db.Tag, error) {
+	if m.GetTagByNameFn != nil {
+		return m.GetTagByNameFn(ctx, name)
+	}
+	return db.Tag{}, sql.ErrNoRows
+}
+func (m *mockQueries) ListTagsWithFeedsCount(ctx context.Context) ([]db.ListTagsWithFeedsCountRow, error) {
+	if m.ListTagsWithFeedsCountFn != nil {
+		return m.ListTagsWithFeedsCountFn(ctx)
+	}
+	return nil, nil
+}
+func (m *mockQueries) UpsertTag(ctx context.Context, name string) (db.Tag, error) {
+	if m.UpsertTagFn != nil {
+		return m.UpsertTagFn(ctx, name)
+	}
+	return db.Tag{Name: name}, nil
+}
+func (m *mockQueries) AddFeedTag(ctx context.Context, arg db.AddFeedTagParams) error {
+	if m.AddFeedTagFn != nil {
+		return m.AddFeedTagFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteFeedTags(ctx context.Context, feedID int64) error {
+	if m.DeleteFeedTagsFn != nil {
+		return m.DeleteFeedTagsFn(ctx, feedID)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteUnusedTags(ctx context.Context) error {
+	if m.DeleteUnusedTagsFn != nil {
+		return m.DeleteUnusedTagsFn(ctx)
+	}
+	return nil
+}
+func (m *mockQueries) ListFeedTagNames(ctx context.Context) ([]db.ListFeedTagNamesRow, error) {
+	if m.ListFeedTagNamesFn != nil {
+		return m.ListFeedTagNamesFn(ctx)
+	}
+	return nil, nil
+}
+func (m *mockQueries) ListTagNamesByFeed(ctx context.Context, feedID int64) ([]string, error) {
+	if m.ListTagNamesByFeedFn != nil {
+		return m.ListTagNamesByFeedFn(ctx, feedID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) ListTagFeeds(ctx context.Context, tagID int64) ([]db.Feed, error) {
+	if m.ListTagFeedsFn != nil {
+		return m.ListTagFeedsFn(ctx, tagID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) ListTagItems(ctx context.Context, arg db.ListTagItemsParams) ([]db.ListTagItemsRow, error) {
+	if m.ListTagItemsFn != nil {
+		return m.ListTagItemsFn(ctx, arg)
+	}
+	return nil, nil
+}
+func (m *mockQueries) GetFeedItemsVersion(ctx context.Context, feedID int64) (db.GetFeedItemsVersionRow, error) {
+	if m.GetFeedItemsVersionFn != nil {
+		return m.GetFeedItemsVersionFn(ctx, feedID)
+	}
+	return db.GetFeedItemsVersionRow{}, nil
+}
+func (m *mockQueries) ListFeedItemsPage(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+	if m.ListFeedItemsPageFn != nil {
+		return m.ListFeedItemsPageFn(ctx, arg)
+	}
+	return nil, nil
+}
+func (m *mockQueries) ListFeedViews(ctx context.Context, feedID int64) ([]db.FeedView, error) {
+	if m.ListFeedViewsFn != nil {
+		return m.ListFeedViewsFn(ctx, feedID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) GetFeedView(ctx context.Context, arg db.GetFeedViewParams) (db.FeedView, error) {
+	if m.GetFeedViewFn != nil {
+		return m.GetFeedViewFn(ctx, arg)
+	}
+	return db.FeedView{}, sql.ErrNoRows
+}
+func (m *mockQueries) CreateFeedView(ctx context.Context, arg db.CreateFeedViewParams) (db.FeedView, error) {
+	if m.CreateFeedViewFn != nil {
+		return m.CreateFeedViewFn(ctx, arg)
+	}
+	return db.FeedView{}, nil
+}
+func (m *mockQueries) UpdateFeedView(ctx context.Context, arg db.UpdateFeedViewParams) error {
+	if m.UpdateFeedViewFn != nil {
+		return m.UpdateFeedViewFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteFeedView(ctx context.Context, arg db.DeleteFeedViewParams) error {
+	if m.DeleteFeedViewFn != nil {
+		return m.DeleteFeedViewFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) ListFeedViewFilterRules(ctx context.Context, viewID int64) ([]db.FeedViewFilterRule, error) {
+	if m.ListFeedViewFilterRulesFn != nil {
+		return m.ListFeedViewFilterRulesFn(ctx, viewID)
+	}
+	return nil, nil
+}
+func (m *mockQueries) CreateFeedViewFilterRule(ctx context.Context, arg db.CreateFeedViewFilterRuleParams) error {
+	if m.CreateFeedViewFilterRuleFn != nil {
+		return m.CreateFeedViewFilterRuleFn(ctx, arg)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteFeedViewFilterRules(ctx context.Context, viewID int64) error {
+	if m.DeleteFeedViewFilterRulesFn != nil {
+		return m.DeleteFeedViewFilterRulesFn(ctx, viewID)
+	}
+	return nil
+}
+func (m *mockQueries) DeleteFeedItemTombstones(ctx context.Context, feedID int64) error {
+	if m.DeleteFeedItemTombstonesFn != nil {
+		return m.DeleteFeedItemTombstonesFn(ctx, feedID)
+	}
+	return nil
+}
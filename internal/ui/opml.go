@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// OPML XML structures, as read by feed readers importing subscriptions
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    OPMLHead `xml:"head"`
+	Body    OPMLBody `xml:"body"`
+}
+
+type OPMLHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type OPMLBody struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline is a subscription, or a folder of subscriptions when it has outlines
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline,omitempty"`
+}
+
+// feedOutline returns the subscription to a feed's RSS served under baseURL
+func feedOutline(baseURL string, feedID int64, name, siteURL string) Outline {
+	return Outline{
+		Text:    name,
+		Title:   name,
+		Type:    "rss",
+		XMLURL:  fmt.Sprintf("%s/feed/%d/rss", baseURL, feedID),
+		HTMLURL: siteURL,
+	}
+}
+
+// GET /opml - Export the RSS feeds of every feed, in a folder per tag
+func (h *Handler) handleOPML(w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.queries.ListFeeds(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to load feeds", http.StatusInternalServerError)
+		return
+	}
+
+	tags, err := h.queries.ListTagsWithFeedsCount(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to load tags", http.StatusInternalServerError)
+		return
+	}
+
+	feedTags, err := h.queries.ListFeedTagNames(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to load feed tags", http.StatusInternalServerError)
+		return
+	}
+	tagsByFeed := make(map[int64][]string)
+	for _, ft := range feedTags {
+		tagsByFeed[ft.FeedID] = append(tagsByFeed[ft.FeedID], ft.Name)
+	}
+
+	// Only the fields of the subscriptions are needed to group the feeds
+	rows := make([]FeedRow, 0, len(feeds))
+	for _, f := range feeds {
+		rows = append(rows, FeedRow{
+			ListFeedsWithItemsCountRow: db.ListFeedsWithItemsCountRow{ID: f.ID, Name: f.Name, Url: f.Url},
+			Tags:                       tagsByFeed[f.ID],
+		})
+	}
+
+	// Feeds with several tags are listed in each of their folders, and
+	// untagged feeds outside of any folder
+	baseURL := h.baseURL(r)
+	var outlines []Outline
+	for _, group := range groupFeedsByTag(rows, tags) {
+		subscriptions := make([]Outline, 0, len(group.Feeds))
+		for _, f := range group.Feeds {
+			subscriptions = append(subscriptions, feedOutline(baseURL, f.ID, f.Name, h.siteURL(f.Url)))
+		}
+
+		if group.Tag == "" {
+			outlines = append(outlines, subscriptions...)
+		} else {
+			outlines = append(outlines, Outline{Text: group.Tag, Title: group.Tag, Outlines: subscriptions})
+		}
+	}
+
+	writeOPML(w, OPML{
+		Version: "2.0",
+		Head: OPMLHead{
+			Title:       "web2rss feeds",
+			DateCreated: formatRSSDate(time.Now()),
+		},
+		Body: OPMLBody{Outlines: outlines},
+	})
+}
+
+// writeOPML sends an OPML document
+func writeOPML(w http.ResponseWriter, opml OPML) {
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		fmt.Printf("Failed to write XML header: %v\n", err)
+		return
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(opml); err != nil {
+		fmt.Printf("Failed to generate OPML: %v\n", err)
+	}
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOPMLTestQueries() *mockQueries {
+	return &mockQueries{
+		ListFeedsFn: func(ctx context.Context) ([]db.Feed, error) {
+			return []db.Feed{
+				{ID: 1, Name: "Jobs", Url: "https://jobs.example.com"},
+				{ID: 2, Name: "News", Url: "https://news.example.com"},
+				{ID: 3, Name: "Weather", Url: "https://weather.example.com"},
+			}, nil
+		},
+		ListTagsWithFeedsCountFn: func(ctx context.Context) ([]db.ListTagsWithFeedsCountRow, error) {
+			return []db.ListTagsWithFeedsCountRow{
+				{ID: 1, Name: "backend", FeedsCount: 2},
+				{ID: 2, Name: "hiring", FeedsCount: 1},
+			}, nil
+		},
+		ListFeedTagNamesFn: func(ctx context.Context) ([]db.ListFeedTagNamesRow, error) {
+			return []db.ListFeedTagNamesRow{
+				{FeedID: 1, Name: "backend"},
+				{FeedID: 2, Name: "backend"},
+				{FeedID: 1, Name: "hiring"},
+			}, nil
+		},
+	}
+}
+
+func TestHandleOPML(t *testing.T) {
+	handler := NewHandler(newOPMLTestQueries(), nil, nil, &config.Config{Timezone: "UTC", BaseURL: "https://example.org/web2rss"})
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/opml", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleOPML(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/x-opml; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var opml OPML
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &opml))
+	assert.Equal(t, "2.0", opml.Version)
+
+	outlines := opml.Body.Outlines
+	if assert.Len(t, outlines, 3) {
+		assert.Equal(t, "backend", outlines[0].Text)
+		if assert.Len(t, outlines[0].Outlines, 2) {
+			assert.Equal(t, "https://example.org/web2rss/feed/1/rss", outlines[0].Outlines[0].XMLURL)
+			assert.Equal(t, "https://example.org/web2rss/feed/2/rss", outlines[0].Outlines[1].XMLURL)
+		}
+
+		assert.Equal(t, "hiring", outlines[1].Text)
+		if assert.Len(t, outlines[1].Outlines, 1) {
+			assert.Equal(t, "Jobs", outlines[1].Outlines[0].Title)
+		}
+
+		// Untagged feeds are not in a folder
+		assert.Equal(t, "Weather", outlines[2].Text)
+		assert.Equal(t, "https://example.org/web2rss/feed/3/rss", outlines[2].XMLURL)
+		assert.Equal(t, "https://weather.example.com", outlines[2].HTMLURL)
+	}
+}
+
+func TestHandleOPMLWithoutBaseURL(t *testing.T) {
+	handler := NewHandler(newOPMLTestQueries(), nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "http://rss.example.net/opml", nil)
+	w := httptest.NewRecorder()
+
+	handler.handleOPML(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `xmlUrl="http://rss.example.net/feed/3/rss"`)
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// PatternSettings are the fields of the feed forms used by the pattern
+// source type
+type PatternSettings struct {
+	GlobalPattern       string
+	ItemPattern         string
+	TitleTemplate       string
+	LinkTemplate        string
+	DescriptionTemplate string
+	DateTemplate        string
+}
+
+// patternsFromForm reads the pattern fields of the feed forms
+func patternsFromForm(r *http.Request) PatternSettings {
+	return PatternSettings{
+		GlobalPattern:       r.FormValue("global_pattern"),
+		ItemPattern:         r.FormValue("item_pattern"),
+		TitleTemplate:       r.FormValue("title_template"),
+		LinkTemplate:        r.FormValue("link_template"),
+		DescriptionTemplate: r.FormValue("description_template"),
+		DateTemplate:        r.FormValue("date_template"),
+	}
+}
+
+// patternsFromFeed returns the pattern fields stored for a feed
+func patternsFromFeed(f db.Feed) PatternSettings {
+	return PatternSettings{
+		GlobalPattern:       nullStringToString(f.GlobalPattern),
+		ItemPattern:         nullStringToString(f.ItemPattern),
+		TitleTemplate:       nullStringToString(f.TitleTemplate),
+		LinkTemplate:        nullStringToString(f.LinkTemplate),
+		DescriptionTemplate: nullStringToString(f.DescriptionTemplate),
+		DateTemplate:        nullStringToString(f.DateTemplate),
+	}
+}
+
+// apply sets the pattern fields of a feed, leaving empty fields NULL
+func (p PatternSettings) apply(f *db.Feed) {
+	f.GlobalPattern = sql.NullString{String: p.GlobalPattern, Valid: p.GlobalPattern != ""}
+	f.ItemPattern = sql.NullString{String: p.ItemPattern, Valid: p.ItemPattern != ""}
+	f.TitleTemplate = sql.NullString{String: p.TitleTemplate, Valid: p.TitleTemplate != ""}
+	f.LinkTemplate = sql.NullString{String: p.LinkTemplate, Valid: p.LinkTemplate != ""}
+	f.DescriptionTemplate = sql.NullString{String: p.DescriptionTemplate, Valid: p.DescriptionTemplate != ""}
+	f.DateTemplate = sql.NullString{String: p.DateTemplate, Valid: p.DateTemplate != ""}
+}
+
+// Validate checks the patterns of a feed of the given source type. Other
+// source types ignore them.
+func (p PatternSettings) Validate(sourceType string) error {
+	if sourceType != feed.SourcePattern {
+		return nil
+	}
+	var f db.Feed
+	p.apply(&f)
+	_, err := feed.CompilePatterns(f)
+	return err
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// maxPluginSize bounds the size of an uploaded plugin module
+const maxPluginSize = 32 << 20
+
+// GET /plugins - List the installed extractor plugins
+func (h *Handler) handlePlugins(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Enabled bool
+		Plugins []string
+		Prefix  string
+	}{
+		Prefix: feed.SourceWASMPrefix,
+	}
+	if h.feedService != nil {
+		data.Enabled = h.feedService.PluginsEnabled()
+		data.Plugins = h.feedService.Plugins()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.templates.ExecuteTemplate(w, "plugins.html", data); err != nil {
+		fmt.Printf("Template error: %v\n", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// POST /plugins - Upload an extractor plugin, replacing any plugin of the same name
+func (h *Handler) handleUploadPlugin(w http.ResponseWriter, r *http.Request) {
+	if h.feedService == nil || !h.feedService.PluginsEnabled() {
+		http.Error(w, "Plugins are disabled", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPluginSize)
+	if err := r.ParseMultipartForm(maxPluginSize); err != nil {
+		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("module")
+	if err != nil {
+		http.Error(w, "Module file is required", http.StatusBadRequest)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	wasm, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read module file", http.StatusBadRequest)
+		return
+	}
+
+	sourceType, err := h.feedService.InstallPlugin(r.Context(), r.FormValue("name"), wasm)
+	if errors.Is(err, feed.ErrInvalidPluginName) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to install plugin %q: %v", r.FormValue("name"), err)
+		http.Error(w, fmt.Sprintf("Failed to install plugin: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Installed plugin %s", sourceType)
+	http.Redirect(w, r, "/plugins", http.StatusSeeOther)
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPluginTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	host, err := feed.NewPluginHost(context.Background(), t.TempDir(), feed.PluginLimits{MemoryMB: 16, Timeout: 5 * time.Second})
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = host.Close(context.Background()) })
+
+	svc := feed.NewService(nil)
+	assert.NoError(t, svc.SetPluginHost(context.Background(), host))
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err = tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	return NewHandler(&mockQueries{}, tmpl, svc, cfg)
+}
+
+func newPluginUpload(t *testing.T, name, path string) *http.Request {
+	t.Helper()
+
+	wasm, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	assert.NoError(t, mw.WriteField("name", name))
+	part, err := mw.CreateFormFile("module", "plugin.wasm")
+	assert.NoError(t, err)
+	_, _ = part.Write(wasm)
+	assert.NoError(t, mw.Close())
+
+	req := httptest.NewRequest("POST", "/plugins", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestHandleUploadPlugin(t *testing.T) {
+	handler := newPluginTestHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.handleUploadPlugin(w, newPluginUpload(t, "echo", "../feed/testdata/plugins/echo.wasm"))
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/plugins", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	handler.handlePlugins(w, httptest.NewRequest("GET", "/plugins", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<code>wasm:echo</code>")
+}
+
+func TestHandleUploadPluginInvalid(t *testing.T) {
+	handler := newPluginTestHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.handleUploadPlugin(w, newPluginUpload(t, "Echo Plugin", "../feed/testdata/plugins/echo.wasm"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "plugin names may only contain")
+
+	w = httptest.NewRecorder()
+	handler.handleUploadPlugin(w, newPluginUpload(t, "broken", "../../templates/plugins.html"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid WebAssembly module")
+}
+
+func TestHandleUploadPluginDisabled(t *testing.T) {
+	handler := NewHandler(&mockQueries{}, nil, nil, &config.Config{Timezone: "UTC"})
+
+	w := httptest.NewRecorder()
+	handler.handleUploadPlugin(w, newPluginUpload(t, "echo", "../feed/testdata/plugins/echo.wasm"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlePreviewFeedWithPlugin(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "Plain text page")
+	}))
+	defer ts.Close()
+
+	handler := newPluginTestHandler(t)
+	w := httptest.NewRecorder()
+	handler.handleUploadPlugin(w, newPluginUpload(t, "echo", "../feed/testdata/plugins/echo.wasm"))
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+
+	form := url.Values{}
+	form.Add("url", ts.URL)
+	form.Add("source_type", "wasm:echo")
+
+	req := httptest.NewRequest("POST", "/preview", nil)
+	req.PostForm = form
+	w = httptest.NewRecorder()
+
+	handler.handlePreviewFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "<strong>Title:</strong> Plain text page")
+	assert.Contains(t, body, "<strong>Link:</strong> https://example.com/echo")
+	assert.Contains(t, body, `<option value="wasm:echo" selected>`)
+}
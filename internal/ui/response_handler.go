@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// ResponseSummary describes a stored raw response
+type ResponseSummary struct {
+	Name      string
+	URL       string
+	Status    int
+	FetchedAt sql.NullTime
+	Size      int
+}
+
+// ResponseItem is an item extracted from a stored response by the current selectors
+type ResponseItem struct {
+	Title  string
+	Link   string
+	Date   sql.NullTime
+	Kept   bool
+	Reason string
+}
+
+// responseStore returns where raw responses are kept, or nil if they are not
+func (h *Handler) responseStore() *feed.ResponseStore {
+	if h.feedService == nil {
+		return nil
+	}
+	return h.feedService.Responses()
+}
+
+// GET /feed/{id}/responses - List the raw responses kept for a feed
+func (h *Handler) handleFeedResponses(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	feed, err := h.queries.GetFeed(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+
+	store := h.responseStore()
+	if store == nil {
+		http.Error(w, "Raw responses are not kept", http.StatusNotFound)
+		return
+	}
+
+	responses, err := store.List(feedID)
+	if err != nil {
+		log.Printf("Failed to list responses of feed %d: %v", feedID, err)
+		http.Error(w, "Failed to load responses", http.StatusInternalServerError)
+		return
+	}
+
+	summaries := make([]ResponseSummary, 0, len(responses))
+	for _, resp := range responses {
+		summaries = append(summaries, summarizeResponse(resp))
+	}
+
+	data := struct {
+		Feed      db.Feed
+		Responses []ResponseSummary
+	}{
+		Feed:      feed,
+		Responses: summaries,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.templates.ExecuteTemplate(w, "feed_responses.html", data); err != nil {
+		fmt.Printf("Template error: %v\n", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GET /feed/{id}/responses/{name} - Show a raw response and what the current
+// selectors extract from it
+func (h *Handler) handleFeedResponse(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	current, err := h.queries.GetFeed(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+
+	store := h.responseStore()
+	if store == nil {
+		http.Error(w, "Raw responses are not kept", http.StatusNotFound)
+		return
+	}
+
+	resp, err := store.Load(feedID, r.PathValue("name"))
+	if errors.Is(err, feed.ErrResponseNotFound) {
+		http.Error(w, "Response not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to load response of feed %d: %v", feedID, err)
+		http.Error(w, "Failed to load response", http.StatusInternalServerError)
+		return
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(resp.Body))
+	if err != nil {
+		http.Error(w, "Failed to parse response", http.StatusInternalServerError)
+		return
+	}
+
+	baseURL, err := url.Parse(resp.URL)
+	if err != nil {
+		baseURL, _ = url.Parse(current.Url)
+	}
+
+	// Re-run the current selectors against what the site served back then
+	var snapshot string
+	var items []ResponseItem
+	if current.Mode == feed.ModeMonitor {
+		snapshot = feed.RegionSnapshot(doc, current.ItemSelector.String)
+	} else {
+		filter, err := h.loadFilter(r.Context(), feedID)
+		if err != nil {
+			http.Error(w, "Failed to load filter rules", http.StatusInternalServerError)
+			return
+		}
+
+		extracted, err := h.feedService.Extract(current, feed.Page{URL: baseURL, Body: resp.Body, Doc: doc})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to extract items: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		for _, item := range extracted {
+			kept, reason := filter.Check(item.Entry())
+			items = append(items, ResponseItem{
+				Title:  item.Title,
+				Link:   item.Link,
+				Date:   sql.NullTime{Time: item.Date, Valid: !item.Date.IsZero()},
+				Kept:   kept,
+				Reason: reason,
+			})
+		}
+	}
+
+	data := struct {
+		Feed     db.Feed
+		Response ResponseSummary
+		Headers  []string
+		Body     string
+		Snapshot string
+		Items    []ResponseItem
+	}{
+		Feed:     current,
+		Response: summarizeResponse(resp.Info()),
+		Headers:  formatHeaders(resp.Header),
+		Body:     string(resp.Body),
+		Snapshot: snapshot,
+		Items:    items,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.templates.ExecuteTemplate(w, "feed_response.html", data); err != nil {
+		fmt.Printf("Template error: %v\n", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func summarizeResponse(info feed.ResponseInfo) ResponseSummary {
+	return ResponseSummary{
+		Name:      info.Name,
+		URL:       info.URL,
+		Status:    info.Status,
+		FetchedAt: sql.NullTime{Time: info.FetchedAt, Valid: !info.FetchedAt.IsZero()},
+		Size:      info.Size,
+	}
+}
+
+// formatHeaders returns the headers as sorted "Name: value" lines
+func formatHeaders(header http.Header) []string {
+	var lines []string
+	for name, values := range header {
+		lines = append(lines, name+": "+strings.Join(values, ", "))
+	}
+	slices.Sort(lines)
+	return lines
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+	"github.com/stretchr/testify/assert"
+)
+
+func newResponseTestHandler(t *testing.T, store *feed.ResponseStore) *Handler {
+	t.Helper()
+
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{
+				ID:            id,
+				Name:          "Jobs",
+				Url:           "https://example.com/jobs",
+				Mode:          feed.ModeList,
+				ItemSelector:  sql.NullString{String: ".job", Valid: true},
+				TitleSelector: sql.NullString{String: "h2", Valid: true},
+				LinkSelector:  sql.NullString{String: "a", Valid: true},
+			}, nil
+		},
+		ListFeedFilterRulesFn: func(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error) {
+			return []db.FeedFilterRule{{FeedID: feedID, Field: "title", Operator: "contains", Value: "Go"}}, nil
+		},
+	}
+
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	svc := feed.NewService(nil)
+	svc.SetResponseStore(store)
+
+	return NewHandler(mockQ, tmpl, svc, cfg)
+}
+
+func TestHandleFeedResponses(t *testing.T) {
+	store := feed.NewResponseStore(t.TempDir(), 3)
+	err := store.Save(1, feed.RawResponse{
+		URL:       "https://example.com/jobs",
+		Status:    http.StatusServiceUnavailable,
+		Body:      []byte("<html>Maintenance</html>"),
+		FetchedAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	handler := newResponseTestHandler(t, store)
+
+	req := httptest.NewRequest("GET", "/feed/1/responses", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedResponses(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "503")
+	assert.Contains(t, body, "2025-03-01 09:00:00 UTC")
+	assert.Contains(t, body, "/feed/1/responses/20250301T090000.000000000Z")
+}
+
+func TestHandleFeedResponseRerunsSelectors(t *testing.T) {
+	store := feed.NewResponseStore(t.TempDir(), 3)
+	err := store.Save(1, feed.RawResponse{
+		URL:    "https://example.com/jobs",
+		Status: http.StatusOK,
+		Header: http.Header{"Content-Type": {"text/html"}},
+		Body: []byte(`<html><body>
+			<div class="job"><h2>Go Developer</h2><a href="/go">Apply</a></div>
+			<div class="job"><h2>Rust Developer</h2><a href="/rust">Apply</a></div>
+		</body></html>`),
+		FetchedAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+
+	handler := newResponseTestHandler(t, store)
+
+	req := httptest.NewRequest("GET", "/feed/1/responses/20250301T090000.000000000Z", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("name", "20250301T090000.000000000Z")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedResponse(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Content-Type: text/html")
+	assert.Contains(t, body, "Go Developer")
+	assert.Contains(t, body, "https://example.com/go")
+	assert.Contains(t, body, "Dropped: fails <code>title contains Go</code>")
+}
+
+func TestHandleFeedResponseNotFound(t *testing.T) {
+	handler := newResponseTestHandler(t, feed.NewResponseStore(t.TempDir(), 3))
+
+	req := httptest.NewRequest("GET", "/feed/1/responses/missing", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("name", "../../etc/passwd")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedResponse(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -39,9 +39,47 @@ func (h *Handler) RegisterRoutes() *http.ServeMux {
 	// Refresh feed
 	mux.HandleFunc("POST /feed/{id}/refresh", h.handleRefreshFeed)
 
+	// Feed items and their revision history
+	mux.HandleFunc("GET /feed/{id}/items", h.handleFeedItems)
+	mux.HandleFunc("GET /feed/{id}/items/{itemID}/revisions", h.handleItemRevisions)
+
+	// Raw responses kept for debugging
+	mux.HandleFunc("GET /feed/{id}/responses", h.handleFeedResponses)
+	mux.HandleFunc("GET /feed/{id}/responses/{name}", h.handleFeedResponse)
+
 	// Feed endpoints
 	// mux.HandleFunc("/feeds/", h.handleListFeeds)  // List all feeds
 	mux.HandleFunc("GET /feed/{id}/rss", h.handleFeedRSS) // Get RSS for specific feed
+	mux.HandleFunc("GET /feed/{id}/atom", h.handleFeedAtom)
+	mux.HandleFunc("GET /feed/{id}/json", h.handleFeedJSON)
+
+	// Views: filtered outputs of a feed, each with its own RSS URL
+	mux.HandleFunc("GET /feed/{id}/views", h.handleFeedViews)
+	mux.HandleFunc("GET /feed/{id}/views/new", h.handleNewFeedView)
+	mux.HandleFunc("POST /feed/{id}/views/", h.handleCreateFeedView)
+	mux.HandleFunc("GET /feed/{id}/views/{viewID}/edit", h.handleEditFeedView)
+	mux.HandleFunc("POST /feed/{id}/views/{viewID}/edit", h.handleUpdateFeedView)
+	mux.HandleFunc("POST /feed/{id}/views/{viewID}/delete", h.handleDeleteFeedView)
+	mux.HandleFunc("GET /feed/{id}/views/{viewID}/rss", h.handleFeedViewRSS)
+
+	// Collections combining several feeds
+	mux.HandleFunc("GET /collection/new", h.handleNewCollection)
+	mux.HandleFunc("POST /collection/", h.handleCreateCollection)
+	mux.HandleFunc("GET /collection/{id}/edit", h.handleEditCollection)
+	mux.HandleFunc("POST /collection/{id}/edit", h.handleUpdateCollection)
+	mux.HandleFunc("POST /collection/{id}/delete", h.handleDeleteCollection)
+	mux.HandleFunc("GET /collection/{id}/rss", h.handleCollectionRSS)
+
+	// Feeds sharing a tag
+	mux.HandleFunc("GET /tag/{name}/rss", h.handleTagRSS)
+	mux.HandleFunc("GET /tag/{name}/opml", h.handleTagOPML)
+
+	// Subscriptions to every feed
+	mux.HandleFunc("GET /opml", h.handleOPML)
+
+	// WebAssembly extractor plugins
+	mux.HandleFunc("GET /plugins", h.handlePlugins)
+	mux.HandleFunc("POST /plugins", h.handleUploadPlugin)
 
 	// Add a health endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
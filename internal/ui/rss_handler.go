@@ -1,34 +1,66 @@
 package ui
 
 import (
+	"cmp"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// Namespaces of the RSS extensions used by the generated feeds
+const (
+	atomNamespace    = "http://www.w3.org/2005/Atom"
+	contentNamespace = "http://purl.org/rss/1.0/modules/content/"
 )
 
+// defaultLanguage is the language of feeds that do not set their own
+const defaultLanguage = "en-us"
+
 // RSS XML structures
 type RSS struct {
-	XMLName xml.Name `xml:"rss"`
-	Version string   `xml:"version,attr"`
-	Channel Channel  `xml:"channel"`
+	XMLName      xml.Name `xml:"rss"`
+	Version      string   `xml:"version,attr"`
+	XMLNSAtom    string   `xml:"xmlns:atom,attr,omitempty"`
+	XMLNSContent string   `xml:"xmlns:content,attr,omitempty"`
+	Channel      Channel  `xml:"channel"`
 }
 
 type Channel struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	Language    string `xml:"language,omitempty"`
-	PubDate     string `xml:"pubDate,omitempty"`
-	Items       []Item `xml:"item"`
+	// AtomLink comes first so that decoders, which ignore the prefix, see
+	// the channel's own link last
+	AtomLink      *AtomLink `xml:"atom:link"`
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	PubDate       string    `xml:"pubDate,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	TTL           int       `xml:"ttl,omitempty"`
+	Items         []Item    `xml:"item"`
 }
 
 type Item struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate,omitempty"`
+	Title          string   `xml:"title"`
+	Link           string   `xml:"link"`
+	Description    string   `xml:"description"`
+	ContentEncoded string   `xml:"content:encoded,omitempty"`
+	Categories     []string `xml:"category,omitempty"`
+	GUID           *GUID    `xml:"guid"`
+	PubDate        string   `xml:"pubDate,omitempty"`
+}
+
+// GUID identifies an item; a permalink is also the URL of the item
+type GUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
 }
 
 // GET /feed/{id}/ - Generate RSS XML for a feed
@@ -45,60 +77,209 @@ func (h *Handler) handleFeedRSS(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get feed details
-	feed, err := h.queries.GetFeed(ctx, feedID)
+	f, err := h.queries.GetFeed(ctx, feedID)
 	if err != nil {
 		http.Error(w, "Feed not found", http.StatusNotFound)
 		return
 	}
 
-	// Get feed items
-	items, err := h.queries.ListFeedItems(ctx, feedID)
+	query, err := h.parseOutputQuery(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if h.notModified(w, r, f) {
+		return
+	}
+
+	items, err := h.listOutputItems(ctx, feedID, query)
 	if err != nil {
 		fmt.Printf("Failed to fetch feed items: %v\n", err)
 		http.Error(w, "Failed to fetch feed items", http.StatusInternalServerError)
 		return
 	}
 
-	// Convert to RSS items
-	rssItems := make([]Item, len(items))
-	for i, item := range items {
-		var pubDate time.Time
-		switch {
-		case item.Date.Valid:
-			pubDate = item.Date.Time
-		case item.CreatedAt.Valid:
-			pubDate = item.CreatedAt.Time
-		default:
-			pubDate = time.Now()
-		}
+	writeRSS(w, newFeedRSS(f, items, h.siteURL(f.Url), fmt.Sprintf("%s/feed/%d/rss", h.baseURL(r), feedID)))
+}
 
-		rssItems[i] = Item{
-			Title:       item.Title,
-			Link:        item.Link,
-			Description: item.Description.String,
-			PubDate:     formatRSSDate(pubDate),
+// newFeedRSS builds the RSS document of a feed's items, linking to the page at
+// siteURL and published at selfURL
+func newFeedRSS(f db.Feed, items []db.FeedItem, siteURL, selfURL string) RSS {
+	// Convert to RSS items. HTML descriptions go in content:encoded, leaving
+	// a plain-text summary in the description.
+	rssItems := make([]Item, 0, len(items))
+	for _, item := range items {
+		rssItem := newRSSItem(item)
+		if f.DescriptionFormat != feed.FormatText && rssItem.Description != "" {
+			rssItem.ContentEncoded = rssItem.Description
+			rssItem.Description = feed.Summary(rssItem.Description)
 		}
+		rssItems = append(rssItems, rssItem)
 	}
 
-	// Create RSS feed
 	rss := RSS{
 		Version: "2.0",
 		Channel: Channel{
-			Title:       feed.Name,
-			Link:        feed.Url,
-			Description: fmt.Sprintf("RSS feed generated from %s", feed.Url),
-			Language:    "en-us",
-			PubDate:     formatRSSDate(time.Now()),
+			Title:       f.Name,
+			Link:        siteURL,
+			Description: cmp.Or(f.Description.String, fmt.Sprintf("RSS feed generated from %s", siteURL)),
+			Language:    cmp.Or(f.Language.String, defaultLanguage),
+			PubDate:     formatRSSDate(feedUpdated(f, items)),
+			TTL:         int(feed.RefreshInterval.Minutes()),
+			AtomLink:    &AtomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
 			Items:       rssItems,
 		},
 	}
+	if f.LastRefreshedAt.Valid {
+		rss.Channel.LastBuildDate = formatRSSDate(f.LastRefreshedAt.Time)
+	}
+	return rss
+}
+
+// Limits of the number of items a generated feed lists
+const (
+	// defaultFeedItemsLimit applies when the configuration sets none
+	defaultFeedItemsLimit = 100
+	// maxFeedItemsLimit bounds the limit clients may ask for
+	maxFeedItemsLimit = 1000
+)
+
+// outputQuery selects the items a generated feed lists, from the query
+// parameters of its URL:
+//
+//	limit  the number of items, newest first
+//	since  only items dated at or after a time, RFC 3339 or YYYY-MM-DD
+//	q      only items whose title or description contains the text
+type outputQuery struct {
+	Limit int64
+	Since time.Time
+	Query string
+}
+
+// parseOutputQuery reads the query parameters of a generated feed
+func (h *Handler) parseOutputQuery(r *http.Request) (outputQuery, error) {
+	q := outputQuery{
+		Limit: defaultFeedItemsLimit,
+		Query: strings.TrimSpace(r.URL.Query().Get("q")),
+	}
+	if h.config != nil && h.config.FeedItemsLimit > 0 {
+		q.Limit = int64(h.config.FeedItemsLimit)
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n < 1 || n > maxFeedItemsLimit {
+			return q, fmt.Errorf("limit must be a number between 1 and %d", maxFeedItemsLimit)
+		}
+		q.Limit = n
+	}
+
+	if since := r.URL.Query().Get("since"); since != "" {
+		var err error
+		if q.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			if q.Since, err = time.ParseInLocation(time.DateOnly, since, h.location); err != nil {
+				return q, errors.New("since must be an RFC 3339 time or a YYYY-MM-DD date")
+			}
+		}
+	}
+
+	return q, nil
+}
+
+// listOutputItems returns the items of a feed to publish, newest first,
+// without those that no longer pass the feed's filter rules. When the feed
+// has rules, they are checked against its latest items up to
+// maxFeedItemsLimit, so that rules changed since the items were scraped
+// still leave up to q.Limit items.
+func (h *Handler) listOutputItems(ctx context.Context, feedID int64, q outputQuery) ([]db.FeedItem, error) {
+	filter, err := h.loadFilter(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load filter rules: %w", err)
+	}
+
+	params := db.ListFeedItemsPageParams{
+		FeedID:   feedID,
+		Query:    q.Query,
+		MaxItems: q.Limit,
+	}
+	if len(filter) > 0 {
+		params.MaxItems = maxFeedItemsLimit
+	}
+	if !q.Since.IsZero() {
+		params.Since = q.Since.UTC().Format(time.DateTime)
+	}
+
+	items, err := h.queries.ListFeedItemsPage(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if int64(len(kept)) == q.Limit {
+			break
+		}
+		if keep, _ := filter.Check(itemEntry(item)); keep {
+			kept = append(kept, item)
+		}
+	}
+	return kept, nil
+}
+
+// feedUpdated returns when the output of a feed last changed: when its
+// newest item changed, or else when it was last refreshed
+func feedUpdated(f db.Feed, items []db.FeedItem) time.Time {
+	var updated time.Time
+	if f.LastRefreshedAt.Valid {
+		updated = f.LastRefreshedAt.Time
+	}
+	for _, item := range items {
+		if item.UpdatedAt.Valid && item.UpdatedAt.Time.After(updated) {
+			updated = item.UpdatedAt.Time
+		}
+	}
+	if updated.IsZero() {
+		return time.Now()
+	}
+	return updated
+}
+
+// newRSSItem converts a stored item, dating it by its publication date or,
+// failing that, when it was first seen
+func newRSSItem(item db.FeedItem) Item {
+	var pubDate time.Time
+	switch {
+	case item.Date.Valid:
+		pubDate = item.Date.Time
+	case item.CreatedAt.Valid:
+		pubDate = item.CreatedAt.Time
+	default:
+		pubDate = time.Now()
+	}
+
+	// Links are unique within a feed and, unlike item IDs, survive
+	// resetting its items
+	return Item{
+		Title:       item.Title,
+		Link:        item.Link,
+		Description: item.Description.String,
+		GUID:        &GUID{IsPermaLink: true, Value: item.Link},
+		PubDate:     formatRSSDate(pubDate),
+	}
+}
+
+// writeRSS sends an RSS document
+func writeRSS(w http.ResponseWriter, rss RSS) {
+	rss.XMLNSAtom = atomNamespace
+	rss.XMLNSContent = contentNamespace
 
 	// Set headers and encode XML
 	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 
 	// Write XML declaration
-	_, err = w.Write([]byte(xml.Header))
+	_, err := w.Write([]byte(xml.Header))
 	if err != nil {
 		fmt.Printf("Failed to write XML header: %v\n", err)
 		http.Error(w, "Failed to write XML header", http.StatusInternalServerError)
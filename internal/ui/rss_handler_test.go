@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
 	"github.com/alessandrocuzzocrea/web2rss/internal/db"
 	"github.com/stretchr/testify/assert"
 )
@@ -101,8 +102,8 @@ func TestHandleFeedRSS(t *testing.T) {
 			}
 			return db.Feed{}, fmt.Errorf("feed not found")
 		},
-		ListFeedItemsFn: func(ctx context.Context, feedID int64) ([]db.FeedItem, error) {
-			if feedID == 1 {
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			if arg.FeedID == 1 {
 				return []db.FeedItem{
 					{
 						ID:    1,
@@ -160,6 +161,85 @@ func TestHandleFeedRSS(t *testing.T) {
 	assert.Equal(t, "This is a test item", rss.Channel.Items[0].Description)
 }
 
+func TestHandleFeedRSSChannelMetadata(t *testing.T) {
+	refreshed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{
+				ID:                1,
+				Name:              "Nachrichten",
+				Url:               "https://example.de",
+				DescriptionFormat: "html",
+				Language:          sql.NullString{String: "de", Valid: true},
+				Description:       sql.NullString{String: "Neueste Meldungen", Valid: true},
+				LastRefreshedAt:   sql.NullTime{Time: refreshed, Valid: true},
+			}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			return []db.FeedItem{
+				{
+					ID:          1,
+					Title:       "Meldung",
+					Link:        "https://example.de/meldung",
+					Description: sql.NullString{String: "<p>Text</p>", Valid: true},
+					CreatedAt:   sql.NullTime{Time: refreshed, Valid: true},
+				},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "http://rss.example.net/feed/1/rss", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedRSS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/">`)
+	assert.Contains(t, body, `<atom:link href="http://rss.example.net/feed/1/rss" rel="self" type="application/rss+xml"></atom:link>`)
+	assert.Contains(t, body, "<description>Neueste Meldungen</description>")
+	assert.Contains(t, body, "<language>de</language>")
+	assert.Contains(t, body, "<lastBuildDate>Fri, 01 Mar 2024 12:00:00 +0000</lastBuildDate>")
+	assert.Contains(t, body, "<ttl>60</ttl>")
+	assert.Contains(t, body, `<guid isPermaLink="true">https://example.de/meldung</guid>`)
+	assert.Contains(t, body, "<content:encoded>&lt;p&gt;Text&lt;/p&gt;</content:encoded>")
+	assert.Contains(t, body, "<description>Text</description>")
+
+	var rss RSS
+	assert.NoError(t, xml.Unmarshal([]byte(body), &rss))
+	assert.Equal(t, "https://example.de", rss.Channel.Link)
+	assert.Equal(t, "https://example.de/meldung", rss.Channel.Items[0].GUID.Value)
+}
+
+func TestHandleFeedRSSResolvesTemplatedURL(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: 1, Name: "Daily", Url: "https://example.com/{date:2006-01-02}?page={seq:1-3}"}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "/feed/1/rss", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedRSS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var rss RSS
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &rss))
+
+	// The channel links to the newest page the URL covers
+	siteURL := fmt.Sprintf("https://example.com/%s?page=1", time.Now().UTC().Format("2006-01-02"))
+	assert.Equal(t, siteURL, rss.Channel.Link)
+	assert.Equal(t, "RSS feed generated from "+siteURL, rss.Channel.Description)
+}
+
 func TestHandleFeedRSSInvalidID(t *testing.T) {
 	// Create an app instance
 	handler := NewHandler(nil, nil, nil, nil)
@@ -210,7 +290,7 @@ func TestHandleFeedRSSItemsError(t *testing.T) {
 				Url:  "https://example.com",
 			}, nil
 		},
-		ListFeedItemsFn: func(ctx context.Context, feedID int64) ([]db.FeedItem, error) {
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
 			return []db.FeedItem{}, fmt.Errorf("failed to fetch items")
 		},
 	}
@@ -229,3 +309,138 @@ func TestHandleFeedRSSItemsError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "Failed to fetch feed items")
 }
+
+func TestHandleFeedRSSAppliesFilterRules(t *testing.T) {
+	var params db.ListFeedItemsPageParams
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: 1, Name: "Jobs", Url: "https://example.com"}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			params = arg
+			return []db.FeedItem{
+				{ID: 1, Title: "Rust Developer", Link: "https://example.com/rust"},
+				{ID: 2, Title: "Go Developer", Link: "https://example.com/go"},
+				{ID: 3, Title: "Go Engineer", Link: "https://example.com/go-2"},
+				{ID: 4, Title: "Go Lead", Link: "https://example.com/go-3"},
+			}, nil
+		},
+		ListFeedFilterRulesFn: func(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error) {
+			return []db.FeedFilterRule{{FeedID: feedID, Field: "title", Operator: "not_contains", Value: "rust"}}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/feed/1/rss?limit=2", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedRSS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The rules are checked before the limit, so it is still filled
+	assert.Equal(t, int64(maxFeedItemsLimit), params.MaxItems)
+
+	var rss RSS
+	err := xml.Unmarshal(w.Body.Bytes(), &rss)
+	assert.NoError(t, err)
+	if assert.Len(t, rss.Channel.Items, 2) {
+		assert.Equal(t, "Go Developer", rss.Channel.Items[0].Title)
+		assert.Equal(t, "Go Engineer", rss.Channel.Items[1].Title)
+	}
+}
+
+func TestParseOutputQuery(t *testing.T) {
+	handler := NewHandler(nil, nil, nil, &config.Config{Timezone: "Asia/Tokyo", FeedItemsLimit: 50})
+
+	tests := []struct {
+		query   string
+		want    outputQuery
+		wantErr bool
+	}{
+		{query: "", want: outputQuery{Limit: 50}},
+		{query: "limit=10&q=+Go+", want: outputQuery{Limit: 10, Query: "Go"}},
+		{query: "since=2024-03-01T10:00:00Z", want: outputQuery{Limit: 50, Since: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}},
+		{query: "limit=0", wantErr: true},
+		{query: "limit=1001", wantErr: true},
+		{query: "limit=ten", wantErr: true},
+		{query: "since=yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/feed/1/rss?"+tt.query, nil)
+			got, err := handler.parseOutputQuery(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Limit, got.Limit)
+			assert.Equal(t, tt.want.Query, got.Query)
+			assert.True(t, tt.want.Since.Equal(got.Since), "since %s, want %s", got.Since, tt.want.Since)
+		})
+	}
+
+	// Dates start at midnight in the configured time zone
+	req := httptest.NewRequest("GET", "/feed/1/rss?since=2024-03-01", nil)
+	got, err := handler.parseOutputQuery(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-02-29T15:00:00Z", got.Since.UTC().Format(time.RFC3339))
+}
+
+func TestHandleFeedRSSQueryParameters(t *testing.T) {
+	var params db.ListFeedItemsPageParams
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: 1, Name: "Test Feed", Url: "https://example.com"}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			params = arg
+			return nil, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/feed/1/rss?limit=5&since=2024-03-01T10:00:00%2B02:00&q=rust", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedRSS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, db.ListFeedItemsPageParams{
+		FeedID:   1,
+		Since:    "2024-03-01 08:00:00",
+		Query:    "rust",
+		MaxItems: 5,
+	}, params)
+
+	// Without parameters the default cap applies
+	req = httptest.NewRequest("GET", "/feed/1/rss", nil)
+	req.SetPathValue("id", "1")
+	handler.handleFeedRSS(httptest.NewRecorder(), req)
+	assert.Equal(t, db.ListFeedItemsPageParams{FeedID: 1, MaxItems: defaultFeedItemsLimit}, params)
+}
+
+func TestHandleFeedRSSInvalidQuery(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: 1, Name: "Test Feed"}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/feed/1/rss?limit=-1", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedRSS(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid query: limit must be a number between 1 and 1000")
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+)
+
+// parseTags reads comma-separated tag names, dropping blanks and names
+// repeated with a different case
+func parseTags(text string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(text, ",") {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, name)
+	}
+	return tags
+}
+
+// loadTags returns a feed's tags as comma-separated text for its form
+func (h *Handler) loadTags(ctx context.Context, feedID int64) (string, error) {
+	names, err := h.queries.ListTagNamesByFeed(ctx, feedID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load tags: %w", err)
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// saveTags replaces the tags of a feed, forgetting tags no feed uses anymore,
+// with the queries of the transaction writing the feed
+func saveTags(ctx context.Context, q Querier, feedID int64, names []string) error {
+	if err := q.DeleteFeedTags(ctx, feedID); err != nil {
+		return fmt.Errorf("failed to delete tags: %w", err)
+	}
+
+	for _, name := range names {
+		tag, err := q.UpsertTag(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to create tag %q: %w", name, err)
+		}
+		if err := q.AddFeedTag(ctx, db.AddFeedTagParams{FeedID: feedID, TagID: tag.ID}); err != nil {
+			return fmt.Errorf("failed to tag feed with %q: %w", name, err)
+		}
+	}
+
+	if err := q.DeleteUnusedTags(ctx); err != nil {
+		return fmt.Errorf("failed to delete unused tags: %w", err)
+	}
+
+	return nil
+}
+
+// GET /tag/{name}/rss - Generate RSS XML combining the items of the feeds
+// with a tag, newest first
+func (h *Handler) handleTagRSS(w http.ResponseWriter, r *http.Request) {
+	tag, err := h.queries.GetTagByName(r.Context(), r.PathValue("name"))
+	if err != nil {
+		http.Error(w, "Tag not found", http.StatusNotFound)
+		return
+	}
+
+	feeds, err := h.queries.ListTagFeeds(r.Context(), tag.ID)
+	if err != nil {
+		http.Error(w, "Failed to load feeds", http.StatusInternalServerError)
+		return
+	}
+
+	if h.combinedNotModified(w, r, feeds, tag.Name) {
+		return
+	}
+
+	rows, err := h.queries.ListTagItems(r.Context(), db.ListTagItemsParams{
+		TagID:    tag.ID,
+		MaxItems: maxFeedItemsLimit,
+	})
+	if err != nil {
+		fmt.Printf("Failed to fetch tag items: %v\n", err)
+		http.Error(w, "Failed to fetch tag items", http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]sourcedItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, sourcedItem{FeedName: row.FeedName, Item: row.FeedItem})
+	}
+
+	h.writeCombinedRSS(w, r, tag.Name, fmt.Sprintf("Items of the feeds tagged %s", tag.Name), items)
+}
+
+// GET /tag/{name}/opml - Export the feeds with a tag as OPML
+func (h *Handler) handleTagOPML(w http.ResponseWriter, r *http.Request) {
+	tag, err := h.queries.GetTagByName(r.Context(), r.PathValue("name"))
+	if err != nil {
+		http.Error(w, "Tag not found", http.StatusNotFound)
+		return
+	}
+
+	feeds, err := h.queries.ListTagFeeds(r.Context(), tag.ID)
+	if err != nil {
+		http.Error(w, "Failed to load feeds", http.StatusInternalServerError)
+		return
+	}
+
+	baseURL := h.baseURL(r)
+	outlines := make([]Outline, 0, len(feeds))
+	for _, f := range feeds {
+		outlines = append(outlines, feedOutline(baseURL, f.ID, f.Name, h.siteURL(f.Url)))
+	}
+
+	writeOPML(w, OPML{
+		Version: "2.0",
+		Head: OPMLHead{
+			Title:       fmt.Sprintf("web2rss feeds tagged %s", tag.Name),
+			DateCreated: formatRSSDate(time.Now()),
+		},
+		Body: OPMLBody{
+			Outlines: []Outline{{Text: tag.Name, Title: tag.Name, Outlines: outlines}},
+		},
+	})
+}
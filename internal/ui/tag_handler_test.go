@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTags(t *testing.T) {
+	assert.Equal(t, []string{"news", "Go", "jobs"}, parseTags(" news, Go,,go , jobs,"))
+	assert.Empty(t, parseTags(""))
+}
+
+func TestHandleTagRSS(t *testing.T) {
+	mockQ := &mockQueries{
+		GetTagByNameFn: func(ctx context.Context, name string) (db.Tag, error) {
+			assert.Equal(t, "backend team", name)
+			return db.Tag{ID: 4, Name: "backend team"}, nil
+		},
+		ListTagItemsFn: func(ctx context.Context, arg db.ListTagItemsParams) ([]db.ListTagItemsRow, error) {
+			assert.Equal(t, int64(4), arg.TagID)
+			return []db.ListTagItemsRow{
+				{FeedName: "Jobs", FeedItem: db.FeedItem{FeedID: 1, Title: "Go Developer", Link: "https://jobs.example.com/1"}},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "/tag/backend%20team/rss", nil)
+	req.SetPathValue("name", "backend team")
+	w := httptest.NewRecorder()
+
+	handler.handleTagRSS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var rss RSS
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &rss))
+	assert.Equal(t, "backend team", rss.Channel.Title)
+	if assert.Len(t, rss.Channel.Items, 1) {
+		assert.Equal(t, "[Jobs] Go Developer", rss.Channel.Items[0].Title)
+	}
+}
+
+func TestHandleTagOPML(t *testing.T) {
+	mockQ := &mockQueries{
+		GetTagByNameFn: func(ctx context.Context, name string) (db.Tag, error) {
+			return db.Tag{ID: 4, Name: "backend"}, nil
+		},
+		ListTagFeedsFn: func(ctx context.Context, tagID int64) ([]db.Feed, error) {
+			return []db.Feed{
+				{ID: 1, Name: "Jobs", Url: "https://jobs.example.com"},
+				{ID: 7, Name: "News", Url: "https://news.example.com"},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "https://web2rss.example.com/tag/backend/opml", nil)
+	req.SetPathValue("name", "backend")
+	w := httptest.NewRecorder()
+
+	handler.handleTagOPML(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/x-opml; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var opml OPML
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &opml))
+	assert.Equal(t, "2.0", opml.Version)
+	if assert.Len(t, opml.Body.Outlines, 1) {
+		folder := opml.Body.Outlines[0]
+		assert.Equal(t, "backend", folder.Text)
+		if assert.Len(t, folder.Outlines, 2) {
+			assert.Equal(t, "https://web2rss.example.com/feed/7/rss", folder.Outlines[1].XMLURL)
+			assert.Equal(t, "https://news.example.com", folder.Outlines[1].HTMLURL)
+			assert.Equal(t, "rss", folder.Outlines[1].Type)
+		}
+	}
+}
+
+func TestHandleTagRSSNotFound(t *testing.T) {
+	handler := NewHandler(&mockQueries{}, nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "/tag/none/rss", nil)
+	req.SetPathValue("name", "none")
+	w := httptest.NewRecorder()
+
+	handler.handleTagRSS(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleCreateFeedSavesTags(t *testing.T) {
+	var tagged []db.AddFeedTagParams
+	var upserted []string
+	mockQ := &mockQueries{
+		CreateFeedFn: func(ctx context.Context, arg db.CreateFeedParams) (db.Feed, error) {
+			return db.Feed{ID: 3}, nil
+		},
+		UpsertTagFn: func(ctx context.Context, name string) (db.Tag, error) {
+			upserted = append(upserted, name)
+			return db.Tag{ID: int64(len(upserted)), Name: name}, nil
+		},
+		AddFeedTagFn: func(ctx context.Context, arg db.AddFeedTagParams) error {
+			tagged = append(tagged, arg)
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Jobs")
+	form.Add("url", "https://example.com")
+	form.Add("tags", "backend, hiring")
+
+	req := httptest.NewRequest("POST", "/feed/", nil)
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeed(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, []string{"backend", "hiring"}, upserted)
+	assert.Equal(t, []db.AddFeedTagParams{{FeedID: 3, TagID: 1}, {FeedID: 3, TagID: 2}}, tagged)
+}
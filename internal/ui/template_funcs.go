@@ -3,8 +3,7 @@ package ui
 import (
 	"database/sql"
 	"html/template"
-	"log"
-	"time"
+	"net/url"
 
 	"github.com/alessandrocuzzocrea/web2rss/internal/config"
 )
@@ -12,11 +11,7 @@ import (
 // NewTemplateFuncs creates a FuncMap with configuration-aware functions
 func NewTemplateFuncs(cfg *config.Config) template.FuncMap {
 	// Load the location once at startup
-	loc, err := time.LoadLocation(cfg.Timezone)
-	if err != nil {
-		log.Printf("Invalid timezone '%s', falling back to UTC: %v", cfg.Timezone, err)
-		loc = time.UTC
-	}
+	loc := cfg.Location()
 
 	return template.FuncMap{
 		"formatDate": func(t sql.NullTime) string {
@@ -28,5 +23,8 @@ func NewTemplateFuncs(cfg *config.Config) template.FuncMap {
 			// Format: YYYY-MM-DD HH:MM:SS MST
 			return localTime.Format("2006-01-02 15:04:05 MST")
 		},
+		// Escape values used as a URL path segment, such as tag names,
+		// which may contain slashes and question marks
+		"pathEscape": url.PathEscape,
 	}
 }
@@ -0,0 +1,351 @@
+package ui
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/alessandrocuzzocrea/web2rss/internal/feed"
+)
+
+// viewForm is a view as submitted by its form
+type viewForm struct {
+	Name     string
+	Title    sql.NullString
+	MaxItems sql.NullInt64
+	Filter   feed.Filter
+}
+
+// parseViewForm reads and validates the fields of a view's form
+func parseViewForm(r *http.Request) (viewForm, error) {
+	form := viewForm{Name: strings.TrimSpace(r.FormValue("name"))}
+	if form.Name == "" {
+		return form, errors.New("name is required")
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	form.Title = sql.NullString{String: title, Valid: title != ""}
+
+	maxItems, err := parseOptionalInt(r.FormValue("max_items"))
+	if err != nil || (maxItems.Valid && (maxItems.Int64 < 1 || maxItems.Int64 > maxFeedItemsLimit)) {
+		return form, fmt.Errorf("item limit must be a number between 1 and %d", maxFeedItemsLimit)
+	}
+	form.MaxItems = maxItems
+
+	if form.Filter, err = feed.ParseFilter(r.FormValue("filters")); err != nil {
+		return form, fmt.Errorf("invalid filter rules: %w", err)
+	}
+
+	return form, nil
+}
+
+// GET /feed/{id}/views - List the views of a feed
+func (h *Handler) handleFeedViews(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	f, err := h.queries.GetFeed(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+
+	views, err := h.queries.ListFeedViews(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Failed to load views", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Feed  db.Feed
+		Views []db.FeedView
+	}{
+		Feed:  f,
+		Views: views,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.templates.ExecuteTemplate(w, "feed_views.html", data); err != nil {
+		fmt.Printf("Template error: %v\n", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GET /feed/{id}/views/new - Show the form to create a view
+func (h *Handler) handleNewFeedView(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	f, err := h.queries.GetFeed(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+
+	h.renderViewForm(w, f, db.FeedView{FeedID: feedID}, nil)
+}
+
+// POST /feed/{id}/views/ - Create a view
+func (h *Handler) handleCreateFeedView(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.queries.GetFeed(r.Context(), feedID); err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		return
+	}
+
+	form, err := parseViewForm(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid view: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	err = h.inTx(r.Context(), func(q Querier) error {
+		created, err := q.CreateFeedView(r.Context(), db.CreateFeedViewParams{
+			FeedID:   feedID,
+			Name:     form.Name,
+			Title:    form.Title,
+			MaxItems: form.MaxItems,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create view: %w", err)
+		}
+		return saveViewFilter(r.Context(), q, created.ID, form.Filter)
+	})
+	if err != nil {
+		log.Printf("Failed to create view of feed %d: %v", feedID, err)
+		http.Error(w, "Failed to create view", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/feed/%d/views", feedID), http.StatusSeeOther)
+}
+
+// GET /feed/{id}/views/{viewID}/edit - Show the form to edit a view
+func (h *Handler) handleEditFeedView(w http.ResponseWriter, r *http.Request) {
+	f, view, ok := h.loadView(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := h.loadViewFilter(r.Context(), view.ID)
+	if err != nil {
+		log.Printf("Failed to load filter rules of view %d: %v", view.ID, err)
+		http.Error(w, "Failed to load view filter rules", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderViewForm(w, f, view, filter)
+}
+
+// POST /feed/{id}/views/{viewID}/edit - Update a view
+func (h *Handler) handleUpdateFeedView(w http.ResponseWriter, r *http.Request) {
+	_, view, ok := h.loadView(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Failed to parse form data", http.StatusBadRequest)
+		return
+	}
+
+	form, err := parseViewForm(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid view: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	err = h.inTx(r.Context(), func(q Querier) error {
+		if err := q.UpdateFeedView(r.Context(), db.UpdateFeedViewParams{
+			ID:       view.ID,
+			FeedID:   view.FeedID,
+			Name:     form.Name,
+			Title:    form.Title,
+			MaxItems: form.MaxItems,
+		}); err != nil {
+			return fmt.Errorf("failed to update view: %w", err)
+		}
+		return saveViewFilter(r.Context(), q, view.ID, form.Filter)
+	})
+	if err != nil {
+		log.Printf("Failed to update view %d: %v", view.ID, err)
+		http.Error(w, "Failed to update view", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/feed/%d/views", view.FeedID), http.StatusSeeOther)
+}
+
+// POST /feed/{id}/views/{viewID}/delete - Delete a view
+func (h *Handler) handleDeleteFeedView(w http.ResponseWriter, r *http.Request) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	viewID, err := strconv.ParseInt(r.PathValue("viewID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid view ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.queries.DeleteFeedView(r.Context(), db.DeleteFeedViewParams{
+		ID:     viewID,
+		FeedID: feedID,
+	}); err != nil {
+		http.Error(w, "Failed to delete view", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/feed/%d/views", feedID), http.StatusSeeOther)
+}
+
+// GET /feed/{id}/views/{viewID}/rss - Generate RSS XML for a view of a feed
+func (h *Handler) handleFeedViewRSS(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	f, view, ok := h.loadView(w, r)
+	if !ok {
+		return
+	}
+
+	query, err := h.parseOutputQuery(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+		return
+	}
+	if view.MaxItems.Valid && !r.URL.Query().Has("limit") {
+		query.Limit = view.MaxItems.Int64
+	}
+
+	// Editing the view changes its output as editing the feed does
+	if view.UpdatedAt.Valid && view.UpdatedAt.Time.After(f.UpdatedAt.Time) {
+		f.UpdatedAt = view.UpdatedAt
+	}
+	if h.notModified(w, r, f) {
+		return
+	}
+
+	items, err := h.listViewItems(ctx, view, query)
+	if err != nil {
+		fmt.Printf("Failed to fetch view items: %v\n", err)
+		http.Error(w, "Failed to fetch feed items", http.StatusInternalServerError)
+		return
+	}
+
+	rss := newFeedRSS(f, items, h.siteURL(f.Url), fmt.Sprintf("%s/feed/%d/views/%d/rss", h.baseURL(r), f.ID, view.ID))
+	rss.Channel.Title = cmp.Or(view.Title.String, fmt.Sprintf("%s - %s", f.Name, view.Name))
+	writeRSS(w, rss)
+}
+
+// listViewItems returns the items a view publishes: the feed's own output
+// items that also pass the view's filter rules. The rules are checked against
+// the feed's latest items up to maxFeedItemsLimit, so that a narrow view still
+// fills up to its limit.
+func (h *Handler) listViewItems(ctx context.Context, view db.FeedView, q outputQuery) ([]db.FeedItem, error) {
+	limit := q.Limit
+	q.Limit = maxFeedItemsLimit
+
+	items, err := h.listOutputItems(ctx, view.FeedID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := h.loadViewFilter(ctx, view.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if int64(len(kept)) == limit {
+			break
+		}
+		if keep, _ := filter.Check(itemEntry(item)); keep {
+			kept = append(kept, item)
+		}
+	}
+	return kept, nil
+}
+
+// loadView returns the feed and view a request is for, replying with an error
+// and reporting false if either is invalid or missing
+func (h *Handler) loadView(w http.ResponseWriter, r *http.Request) (db.Feed, db.FeedView, bool) {
+	feedID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return db.Feed{}, db.FeedView{}, false
+	}
+
+	viewID, err := strconv.ParseInt(r.PathValue("viewID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid view ID", http.StatusBadRequest)
+		return db.Feed{}, db.FeedView{}, false
+	}
+
+	f, err := h.queries.GetFeed(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "Feed not found", http.StatusNotFound)
+		return db.Feed{}, db.FeedView{}, false
+	}
+
+	view, err := h.queries.GetFeedView(r.Context(), db.GetFeedViewParams{
+		ID:     viewID,
+		FeedID: feedID,
+	})
+	if err != nil {
+		http.Error(w, "View not found", http.StatusNotFound)
+		return db.Feed{}, db.FeedView{}, false
+	}
+
+	return f, view, true
+}
+
+// renderViewForm shows the form to create or edit a view of a feed
+func (h *Handler) renderViewForm(w http.ResponseWriter, f db.Feed, view db.FeedView, filter feed.Filter) {
+	data := struct {
+		Feed     db.Feed
+		View     db.FeedView
+		Filters  string
+		MaxItems string
+	}{
+		Feed:     f,
+		View:     view,
+		Filters:  filter.String(),
+		MaxItems: nullInt64ToString(view.MaxItems),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := h.templates.ExecuteTemplate(w, "feed_view_form.html", data); err != nil {
+		fmt.Printf("Template error: %v\n", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,283 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/alessandrocuzzocrea/web2rss/internal/config"
+	"github.com/alessandrocuzzocrea/web2rss/internal/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleFeedViewRSS(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: id, Name: "Jobs", Url: "https://jobs.example.com"}, nil
+		},
+		GetFeedViewFn: func(ctx context.Context, arg db.GetFeedViewParams) (db.FeedView, error) {
+			assert.Equal(t, db.GetFeedViewParams{ID: 7, FeedID: 1}, arg)
+			return db.FeedView{ID: 7, FeedID: 1, Name: "Go",
+				MaxItems: sql.NullInt64{Int64: 2, Valid: true}}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			// The view's rules are checked against more items than it lists
+			assert.Equal(t, int64(maxFeedItemsLimit), arg.MaxItems)
+			return []db.FeedItem{
+				{Title: "Go Developer", Link: "https://jobs.example.com/1"},
+				{Title: "Rust Developer", Link: "https://jobs.example.com/2"},
+				{Title: "Senior Go Engineer", Link: "https://jobs.example.com/3"},
+				{Title: "Go Intern", Link: "https://jobs.example.com/4", Description: sql.NullString{String: "unpaid", Valid: true}},
+				{Title: "Go Contractor", Link: "https://jobs.example.com/5"},
+			}, nil
+		},
+		ListFeedFilterRulesFn: func(ctx context.Context, feedID int64) ([]db.FeedFilterRule, error) {
+			return []db.FeedFilterRule{{Field: "description", Operator: "not_contains", Value: "unpaid"}}, nil
+		},
+		ListFeedViewFilterRulesFn: func(ctx context.Context, viewID int64) ([]db.FeedViewFilterRule, error) {
+			return []db.FeedViewFilterRule{{ViewID: viewID, Field: "title", Operator: "contains", Value: "Go"}}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "/feed/1/views/7/rss", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("viewID", "7")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedViewRSS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<atom:link href="http://example.com/feed/1/views/7/rss" rel="self"`)
+
+	var rss RSS
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &rss))
+	assert.Equal(t, "Jobs - Go", rss.Channel.Title)
+
+	// Both the feed's and the view's rules apply, then the view's limit
+	if assert.Len(t, rss.Channel.Items, 2) {
+		assert.Equal(t, "Go Developer", rss.Channel.Items[0].Title)
+		assert.Equal(t, "Senior Go Engineer", rss.Channel.Items[1].Title)
+	}
+}
+
+func TestHandleFeedViewRSSTitleAndLimit(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: id, Name: "Jobs"}, nil
+		},
+		GetFeedViewFn: func(ctx context.Context, arg db.GetFeedViewParams) (db.FeedView, error) {
+			return db.FeedView{ID: arg.ID, FeedID: arg.FeedID, Name: "Go",
+				Title:    sql.NullString{String: "Go jobs", Valid: true},
+				MaxItems: sql.NullInt64{Int64: 2, Valid: true}}, nil
+		},
+		ListFeedItemsPageFn: func(ctx context.Context, arg db.ListFeedItemsPageParams) ([]db.FeedItem, error) {
+			return []db.FeedItem{
+				{Title: "Go Developer", Link: "https://jobs.example.com/1"},
+				{Title: "Go Engineer", Link: "https://jobs.example.com/2"},
+				{Title: "Go Intern", Link: "https://jobs.example.com/3"},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	// An explicit limit takes precedence over the view's
+	req := httptest.NewRequest("GET", "/feed/1/views/7/rss?limit=3", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("viewID", "7")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedViewRSS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var rss RSS
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &rss))
+	assert.Equal(t, "Go jobs", rss.Channel.Title)
+	assert.Len(t, rss.Channel.Items, 3)
+}
+
+func TestHandleFeedViewRSSNotFound(t *testing.T) {
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: id, Name: "Jobs"}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	req := httptest.NewRequest("GET", "/feed/1/views/9/rss", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("viewID", "9")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedViewRSS(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleCreateFeedView(t *testing.T) {
+	var created db.CreateFeedViewParams
+	var rules []db.CreateFeedViewFilterRuleParams
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: id, Name: "Jobs"}, nil
+		},
+		CreateFeedViewFn: func(ctx context.Context, arg db.CreateFeedViewParams) (db.FeedView, error) {
+			created = arg
+			return db.FeedView{ID: 7, FeedID: arg.FeedID, Name: arg.Name}, nil
+		},
+		CreateFeedViewFilterRuleFn: func(ctx context.Context, arg db.CreateFeedViewFilterRuleParams) error {
+			rules = append(rules, arg)
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+	form := url.Values{}
+	form.Add("name", "Rust")
+	form.Add("title", "Rust jobs")
+	form.Add("max_items", "20")
+	form.Add("filters", "title contains Rust")
+
+	req := httptest.NewRequest("POST", "/feed/1/views/", nil)
+	req.SetPathValue("id", "1")
+	req.PostForm = form
+	w := httptest.NewRecorder()
+
+	handler.handleCreateFeedView(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/feed/1/views", w.Header().Get("Location"))
+	assert.Equal(t, db.CreateFeedViewParams{
+		FeedID:   1,
+		Name:     "Rust",
+		Title:    sql.NullString{String: "Rust jobs", Valid: true},
+		MaxItems: sql.NullInt64{Int64: 20, Valid: true},
+	}, created)
+	assert.Equal(t, []db.CreateFeedViewFilterRuleParams{
+		{ViewID: 7, Field: "title", Operator: "contains", Value: "Rust", Position: 0},
+	}, rules)
+}
+
+func TestHandleCreateFeedViewInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values url.Values
+		want   string
+	}{
+		{"missing name", url.Values{"name": {""}}, "name is required"},
+		{"limit out of range", url.Values{"name": {"Go"}, "max_items": {"0"}}, "item limit must be a number between 1 and 1000"},
+		{"invalid rules", url.Values{"name": {"Go"}, "filters": {"title resembles Go"}}, "invalid filter rules"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQ := &mockQueries{
+				GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+					return db.Feed{ID: id, Name: "Jobs"}, nil
+				},
+				CreateFeedViewFn: func(ctx context.Context, arg db.CreateFeedViewParams) (db.FeedView, error) {
+					t.Fatal("view should not be created")
+					return db.FeedView{}, nil
+				},
+			}
+
+			handler := NewHandler(mockQ, nil, nil, &config.Config{Timezone: "UTC"})
+
+			req := httptest.NewRequest("POST", "/feed/1/views/", nil)
+			req.SetPathValue("id", "1")
+			req.PostForm = tt.values
+			w := httptest.NewRecorder()
+
+			handler.handleCreateFeedView(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestHandleEditFeedView(t *testing.T) {
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: id, Name: "Jobs"}, nil
+		},
+		GetFeedViewFn: func(ctx context.Context, arg db.GetFeedViewParams) (db.FeedView, error) {
+			return db.FeedView{ID: arg.ID, FeedID: arg.FeedID, Name: "Go",
+				MaxItems: sql.NullInt64{Int64: 20, Valid: true}}, nil
+		},
+		ListFeedViewFilterRulesFn: func(ctx context.Context, viewID int64) ([]db.FeedViewFilterRule, error) {
+			return []db.FeedViewFilterRule{{ViewID: viewID, Field: "title", Operator: "contains", Value: "Go"}}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, tmpl, nil, cfg)
+
+	req := httptest.NewRequest("GET", "/feed/1/views/7/edit", nil)
+	req.SetPathValue("id", "1")
+	req.SetPathValue("viewID", "7")
+	w := httptest.NewRecorder()
+
+	handler.handleEditFeedView(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `action="/feed/1/views/7/edit"`)
+	assert.Contains(t, body, `value="20"`)
+	assert.Contains(t, body, "title contains Go")
+}
+
+func TestHandleFeedViews(t *testing.T) {
+	cfg := &config.Config{Timezone: "UTC"}
+
+	// Load templates
+	tmpl := template.New("").Funcs(NewTemplateFuncs(cfg))
+	_, err := tmpl.ParseGlob("../../templates/*.html")
+	assert.NoError(t, err)
+	_, err = tmpl.ParseGlob("../../templates/partials/*.html")
+	assert.NoError(t, err)
+
+	mockQ := &mockQueries{
+		GetFeedFn: func(ctx context.Context, id int64) (db.Feed, error) {
+			return db.Feed{ID: id, Name: "Jobs"}, nil
+		},
+		ListFeedViewsFn: func(ctx context.Context, feedID int64) ([]db.FeedView, error) {
+			return []db.FeedView{
+				{ID: 7, FeedID: feedID, Name: "Go"},
+				{ID: 8, FeedID: feedID, Name: "Rust", Title: sql.NullString{String: "Rust jobs", Valid: true}},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockQ, tmpl, nil, cfg)
+
+	req := httptest.NewRequest("GET", "/feed/1/views", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.handleFeedViews(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `href="/feed/1/views/7/rss"`)
+	assert.Contains(t, body, `href="/feed/1/views/8/rss"`)
+	assert.Contains(t, body, "Rust jobs")
+}